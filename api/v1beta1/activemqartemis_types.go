@@ -37,6 +37,19 @@ type ActiveMQArtemisSpec struct {
 	// Password for standard broker user. It is required for connecting to the broker and the web console. If left empty, it will be generated.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Admin Password",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:password"}
 	AdminPassword string `json:"adminPassword,omitempty"`
+	// Specifies the credentials the operator uses for the management api of the brokers
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Management Credentials"
+	ManagementCredentials *ManagementCredentialsType `json:"managementCredentials,omitempty"`
+	// Specifies the credentials the brokers of the cluster use to connect to each other
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cluster Credentials"
+	ClusterCredentials *ClusterCredentialsType `json:"clusterCredentials,omitempty"`
+	// Specifies when the operator rotates the admin credentials it generates. The generated cluster credentials are only rotated along when the deployment is not clustered or has a single broker, a broker only accepts the cluster credentials it started with
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Credentials Rotation"
+	CredentialsRotation *CredentialsRotationType `json:"credentialsRotation,omitempty"`
+	// How the brokers load the renewed certificates of the acceptors, one of restart or reload, defaults to restart. With reload the operator asks each broker to reload its acceptors instead of restarting it
+	//+kubebuilder:validation:Enum=restart;reload
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Certificate Reload Policy",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:restart","urn:alm:descriptor:com.tectonic.ui:select:reload"}
+	CertificateReloadPolicy string `json:"certificateReloadPolicy,omitempty"`
 	// Specifies the deployment plan
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Deployment Plan"
 	DeploymentPlan DeploymentPlanType `json:"deploymentPlan,omitempty"`
@@ -46,6 +59,9 @@ type ActiveMQArtemisSpec struct {
 	// Specifies connectors and connector configuration
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Connectors"
 	Connectors []ConnectorType `json:"connectors,omitempty"`
+	// Specifies the secrets with the connection details of an acceptor that applications bind to
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Client Bindings"
+	ClientBindings []ClientBindingType `json:"clientBindings,omitempty"`
 	// Specifies the console configuration
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Console Configurations"
 	Console ConsoleType `json:"console,omitempty"`
@@ -58,6 +74,9 @@ type ActiveMQArtemisSpec struct {
 	// Specifies the address configurations
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Address Configurations"
 	AddressSettings AddressSettingsType `json:"addressSettings,omitempty"`
+	// Specifies the diverts to configure on the brokers
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Diverts"
+	Diverts []DivertType `json:"diverts,omitempty"`
 	// Optional list of key=value properties that are applied to the broker configuration bean.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Broker Properties"
 	BrokerProperties []string `json:"brokerProperties,omitempty"`
@@ -285,6 +304,36 @@ type AddressSettingType struct {
 	MaxSizeMessages *int64 `json:"maxSizeMessages,omitempty"`
 }
 
+type DivertType struct {
+	// The unique name of the divert
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
+	// The address to divert messages from
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Address",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Address string `json:"address"`
+	// The address to divert messages to
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Forwarding Address",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ForwardingAddress string `json:"forwardingAddress"`
+	// Whether the divert is exclusive, an exclusive divert does not deliver the message to the original address. Default false
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exclusive",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Exclusive *bool `json:"exclusive,omitempty"`
+	// Only messages matching the filter are diverted
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Filter",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Filter string `json:"filter,omitempty"`
+	// Used to identify the divert when there are several diverts with the same address. Default is the name of the divert
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Routing Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	RoutingName string `json:"routingName,omitempty"`
+	// How to set the routing type of the diverted message, one of STRIP, PASS, ANYCAST or MULTICAST. Default is STRIP
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Routing Type",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	RoutingType string `json:"routingType,omitempty"`
+	// Class name of a transformer to apply to diverted messages
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Transformer Class Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	TransformerClassName string `json:"transformerClassName,omitempty"`
+	// Properties passed to the transformer
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Transformer Properties"
+	TransformerProperties map[string]string `json:"transformerProperties,omitempty"`
+}
+
 type DeploymentPlanType struct {
 	//The image used for the broker, all upgrades are disabled. Needs a corresponding initImage
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
@@ -337,6 +386,9 @@ type DeploymentPlanType struct {
 	// Whether or not to install the artemis metrics plugin
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Enable Metrics Plugin",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	EnableMetricsPlugin *bool `json:"enableMetricsPlugin,omitempty"`
+	// Specifies the monitoring of the brokers with the prometheus operator
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Monitoring"
+	Monitoring *MonitoringType `json:"monitoring,omitempty"`
 	// Specifies the tolerations
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tolerations"
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
@@ -346,6 +398,12 @@ type DeploymentPlanType struct {
 	// Specifies the node selector
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Selector",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:selector"}
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Specifies how the broker pods are spread across topology domains, replaces the zone aware default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Topology Spread Constraints"
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// Spreads the pods of a clustered deployment across the zones of the nodes when no topologySpreadConstraints are set, defaults to true
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Zone Aware",xDescriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	ZoneAware *bool `json:"zoneAware,omitempty"`
 	// Specifies affinity configuration
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Affinity Configurations"
 	Affinity AffinityConfig `json:"affinity,omitempty"`
@@ -358,6 +416,47 @@ type DeploymentPlanType struct {
 	// Specifies the pod disruption budget
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pod Disruption Budget"
 	PodDisruptionBudget *policyv1.PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+	// Customizes the pods that drain the messages of scaled down brokers when messageMigration is enabled
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Drain Pod Template"
+	DrainPodTemplate *DrainPodTemplateType `json:"drainPodTemplate,omitempty"`
+	// Drains the messages of the brokers to an external target when scaled down to zero, requires messageMigration
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Scale To Zero Drain Target"
+	ScaleToZeroDrainTarget *DrainTargetType `json:"scaleToZeroDrainTarget,omitempty"`
+	// The maximum number of scaled down brokers drained in parallel, each to a different remaining broker when possible, defaults to 1
+	//+kubebuilder:validation:Minimum=1
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Drain Concurrency",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:podCount"}
+	DrainConcurrency *int32 `json:"drainConcurrency,omitempty"`
+	// The high availability policy of the brokers, each live broker gets a designated backup with the replication or sharedStore policy
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="HA Policy"
+	HAPolicy *HAPolicyType `json:"haPolicy,omitempty"`
+}
+
+type HAPolicyType struct {
+	// The policy type, one of none, replication or sharedStore, defaults to none
+	//+kubebuilder:validation:Enum=none;replication;sharedStore
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Type",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:none","urn:alm:descriptor:com.tectonic.ui:select:replication","urn:alm:descriptor:com.tectonic.ui:select:sharedStore"}
+	Type string `json:"type,omitempty"`
+	// Whether a restarted live broker takes over again from its backup, defaults to true
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Allow Fail Back",xDescriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	AllowFailBack *bool `json:"allowFailBack,omitempty"`
+	// Whether a restarted live broker checks for an active backup before starting with the replication policy, defaults to true
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Check For Live Server",xDescriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	CheckForLiveServer *bool `json:"checkForLiveServer,omitempty"`
+	// The ReadWriteMany volume holding the journals of the pairs with the sharedStore policy
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Shared Storage"
+	SharedStorage StorageType `json:"sharedStorage,omitempty"`
+}
+
+type DrainTargetType struct {
+	// Name of an ActiveMQArtemis in the same namespace to drain the messages to
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="ActiveMQArtemis",xDescriptors="urn:alm:descriptor:text"
+	ActiveMQArtemis string `json:"activeMQArtemis,omitempty"`
+	// Name of a connector of this broker to drain the messages to, the drain pods connect to its host and port
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Connector Name",xDescriptors="urn:alm:descriptor:text"
+	ConnectorName string `json:"connectorName,omitempty"`
+	// Name of a secret holding the AMQ_CLUSTER_USER and AMQ_CLUSTER_PASSWORD of the target, defaults to the credentials secret of the target ActiveMQArtemis or of this broker for a connector
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Credentials Secret Name",xDescriptors="urn:alm:descriptor:text"
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
 }
 
 // Affinity is a group of affinity scheduling rules.
@@ -419,6 +518,9 @@ type AcceptorType struct {
 	// Name of the secret to use for ssl information
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="SSL Secret",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	SSLSecret string `json:"sslSecret,omitempty"`
+	// Requests the certificate of the ssl secret from cert-manager
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cert Manager"
+	CertManager *CertManagerType `json:"certManager,omitempty"`
 	// Comma separated list of cipher suites used for SSL communication.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Enabled Cipher Suites",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	EnabledCipherSuites string `json:"enabledCipherSuites,omitempty"`
@@ -443,6 +545,17 @@ type AcceptorType struct {
 	// Whether or not to expose this acceptor
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Expose",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	Expose bool `json:"expose,omitempty"`
+	// How the port is exposed, one of ingress, route, loadBalancer, nodePort or gateway, defaults to route on OpenShift and ingress on Kubernetes
+	//+kubebuilder:validation:Enum=ingress;route;loadBalancer;nodePort;gateway
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Expose Mode",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:ingress","urn:alm:descriptor:com.tectonic.ui:select:route","urn:alm:descriptor:com.tectonic.ui:select:loadBalancer","urn:alm:descriptor:com.tectonic.ui:select:nodePort","urn:alm:descriptor:com.tectonic.ui:select:gateway"}
+	ExposeMode string `json:"exposeMode,omitempty"`
+	// Specifies the options of the resources that expose the port
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exposure"
+	Exposure *ExposureType `json:"exposure,omitempty"`
+	// The services of the acceptor, perPod for a service per broker pod, loadBalanced for a single service that spreads the connections across the ready brokers or both, defaults to perPod
+	//+kubebuilder:validation:Enum=perPod;loadBalanced;both
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Service Mode",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:perPod","urn:alm:descriptor:com.tectonic.ui:select:loadBalanced","urn:alm:descriptor:com.tectonic.ui:select:both"}
+	ServiceMode string `json:"serviceMode,omitempty"`
 	// To indicate which kind of routing type to use.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Anycast Prefix",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	AnycastPrefix string `json:"anycastPrefix,omitempty"`
@@ -494,6 +607,9 @@ type ConnectorType struct {
 	// Name of the secret to use for ssl information
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="SSL Secret",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	SSLSecret string `json:"sslSecret,omitempty"`
+	// Requests the certificate of the ssl secret from cert-manager
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cert Manager"
+	CertManager *CertManagerType `json:"certManager,omitempty"`
 	// Comma separated list of cipher suites used for SSL communication.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Enabled Cipher Suites",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	EnabledCipherSuites string `json:"enabledCipherSuites,omitempty"`
@@ -518,6 +634,13 @@ type ConnectorType struct {
 	// Whether or not to expose this connector
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Expose",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	Expose bool `json:"expose,omitempty"`
+	// How the port is exposed, one of ingress, route, loadBalancer, nodePort or gateway, defaults to route on OpenShift and ingress on Kubernetes
+	//+kubebuilder:validation:Enum=ingress;route;loadBalancer;nodePort;gateway
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Expose Mode",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:ingress","urn:alm:descriptor:com.tectonic.ui:select:route","urn:alm:descriptor:com.tectonic.ui:select:loadBalancer","urn:alm:descriptor:com.tectonic.ui:select:nodePort","urn:alm:descriptor:com.tectonic.ui:select:gateway"}
+	ExposeMode string `json:"exposeMode,omitempty"`
+	// Specifies the options of the resources that expose the port
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exposure"
+	Exposure *ExposureType `json:"exposure,omitempty"`
 	// Provider used for the keystore; "SUN", "SunJCE", etc. Default is null
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="KeyStore Provider",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	KeyStoreProvider string `json:"keyStoreProvider,omitempty"`
@@ -529,19 +652,174 @@ type ConnectorType struct {
 	TrustStoreProvider string `json:"trustStoreProvider,omitempty"`
 }
 
+type ManagementCredentialsType struct {
+	// Name of the secret that holds the management user and password. If left empty, the admin credentials secret the operator creates for the brokers is used.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret Name",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	SecretName string `json:"secretName,omitempty"`
+	// Key of the user name in the secret, username by default or AMQ_USER for the admin credentials secret
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="User Key",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	UserKey string `json:"userKey,omitempty"`
+	// Key of the password in the secret, password by default or AMQ_PASSWORD for the admin credentials secret
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Password Key",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	PasswordKey string `json:"passwordKey,omitempty"`
+}
+
+type CertManagerType struct {
+	// Reference to the cert-manager issuer of the certificate
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Issuer Reference"
+	IssuerRef CertManagerIssuerRefType `json:"issuerRef"`
+	// Additional dns names of the certificate, the names of the broker services and of their ingresses or routes are included
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="DNS Names"
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// Requested lifetime of the certificate, for example 2160h. If left empty, the default of the issuer applies.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Duration",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// How long before its expiry the certificate is renewed. If left empty, it is renewed at two thirds of its lifetime.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Renew Before",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+type CertManagerIssuerRefType struct {
+	// Name of the issuer
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
+	// Kind of the issuer, Issuer or ClusterIssuer, Issuer by default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Kind",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Kind string `json:"kind,omitempty"`
+	// Group of the issuer, cert-manager.io by default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Group",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Group string `json:"group,omitempty"`
+}
+
+type ClusterCredentialsType struct {
+	// Name of the secret that holds the cluster user and password. If left empty, the operator generates them for this broker deployment.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret Name",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	SecretName string `json:"secretName,omitempty"`
+	// Key of the user name in the secret, username by default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="User Key",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	UserKey string `json:"userKey,omitempty"`
+	// Key of the password in the secret, password by default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Password Key",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	PasswordKey string `json:"passwordKey,omitempty"`
+}
+
+type ClientBindingType struct {
+	// The name of the binding, its secret is named <CR name>-<binding name>-binding
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
+	// The name of the acceptor the clients connect to
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Acceptor",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Acceptor string `json:"acceptor"`
+	// The protocol of the connection uri, core by default when the acceptor accepts it
+	//+kubebuilder:validation:Enum=core;amqp;openwire;mqtt;stomp
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Protocol",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:core","urn:alm:descriptor:com.tectonic.ui:select:amqp","urn:alm:descriptor:com.tectonic.ui:select:openwire","urn:alm:descriptor:com.tectonic.ui:select:mqtt","urn:alm:descriptor:com.tectonic.ui:select:stomp"}
+	Protocol string `json:"protocol,omitempty"`
+	// Whether the clients connect from outside the cluster, to the external address of the exposed acceptor
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="External",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	External bool `json:"external,omitempty"`
+}
+
+type MonitoringType struct {
+	// Whether to create a metrics service and, when the prometheus operator is installed, a service monitor for the brokers. It installs the metrics plugin
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Enabled",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Enabled bool `json:"enabled,omitempty"`
+	// Interval between two scrapes of the brokers, 30s by default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Interval",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Labels of the service monitor and of the prometheus rule, to match the selectors of the prometheus instance
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Labels"
+	Labels map[string]string `json:"labels,omitempty"`
+	// Specifies the prometheus rule with the alerts for the brokers
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Alerts"
+	Alerts *MonitoringAlertsType `json:"alerts,omitempty"`
+}
+
+type MonitoringAlertsType struct {
+	// Whether to create a prometheus rule with alerts for the address memory usage, paging, the dead letter queue growth and the broker pods not ready
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Enabled",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Enabled bool `json:"enabled,omitempty"`
+	// Percentage of the global max size used by the addresses above which an alert fires, 80 by default
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=100
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Address Memory Usage Percentage",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	AddressMemoryUsagePercentage *int32 `json:"addressMemoryUsagePercentage,omitempty"`
+	// Name of the dead letter queue whose growth fires an alert, DLQ by default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Dead Letter Queue",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	DeadLetterQueue string `json:"deadLetterQueue,omitempty"`
+	// How long a problem lasts before its alert fires, 5m by default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="For",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	For *metav1.Duration `json:"for,omitempty"`
+}
+
+type CredentialsRotationType struct {
+	// Interval between two rotations, for example 720h. If left empty, the credentials are only rotated on request with the broker.amq.io/rotate-credentials annotation.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Interval",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+type ExposureType struct {
+	// Annotations of the ingress, route, service or tls route that exposes the port
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Annotations"
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Class of the ingress controller with the ingress mode, or of the load balancer implementation with the loadBalancer mode
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Class Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ClassName string `json:"className,omitempty"`
+	// Gateway the tls routes of the gateway mode attach to
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Gateway Reference"
+	GatewayRef *GatewayRefType `json:"gatewayRef,omitempty"`
+	// Host of the ingress, route or tls route, $(ORDINAL) is replaced with the ordinal of the broker pod and removed for the load balanced service of an acceptor. Defaults to <service name>-<ing|rte|tlsr>.<ingressDomain>
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Host string `json:"host,omitempty"`
+	// Path of the ingress or route, / by default for an ingress. An ingress or route that passes the tls connections through has no path
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Path",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Path string `json:"path,omitempty"`
+	// Where the ingress or route ends the tls connections, passthrough to the broker, edge on the ingress or route, or reencrypt to the broker. Defaults to passthrough with sslEnabled
+	//+kubebuilder:validation:Enum=passthrough;edge;reencrypt
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="TLS Termination",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:passthrough","urn:alm:descriptor:com.tectonic.ui:select:edge","urn:alm:descriptor:com.tectonic.ui:select:reencrypt"}
+	TLSTermination string `json:"tlsTermination,omitempty"`
+	// Name of the kubernetes.io/tls secret with the certificate the ingress or route serves with the edge and reencrypt termination, the default certificate of the ingress controller or router otherwise
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="TLS Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	TLSSecret string `json:"tlsSecret,omitempty"`
+}
+
+type GatewayRefType struct {
+	// Name of the gateway
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name,omitempty"`
+	// Namespace of the gateway, the namespace of the broker by default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the tls passthrough listener of the gateway, any matching listener by default
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Section Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	SectionName string `json:"sectionName,omitempty"`
+}
+
 type ConsoleType struct {
 	// Whether or not to expose this port
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Expose",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	Expose bool `json:"expose,omitempty"`
+	// How the port is exposed, one of ingress, route, loadBalancer, nodePort or gateway, defaults to route on OpenShift and ingress on Kubernetes
+	//+kubebuilder:validation:Enum=ingress;route;loadBalancer;nodePort;gateway
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Expose Mode",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:ingress","urn:alm:descriptor:com.tectonic.ui:select:route","urn:alm:descriptor:com.tectonic.ui:select:loadBalancer","urn:alm:descriptor:com.tectonic.ui:select:nodePort","urn:alm:descriptor:com.tectonic.ui:select:gateway"}
+	ExposeMode string `json:"exposeMode,omitempty"`
+	// Specifies the options of the resources that expose the port
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exposure"
+	Exposure *ExposureType `json:"exposure,omitempty"`
 	// Whether or not to enable SSL on this port
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="SSL Enabled",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	SSLEnabled bool `json:"sslEnabled,omitempty"`
 	// Name of the secret to use for ssl information
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="SSL Secret",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	SSLSecret string `json:"sslSecret,omitempty"`
+	// Requests the certificate of the ssl secret from cert-manager
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cert Manager"
+	CertManager *CertManagerType `json:"certManager,omitempty"`
 	// If the embedded server requires client authentication
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Use Client Auth",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	UseClientAuth bool `json:"useClientAuth,omitempty"`
+	// Name of a secret with the PEM ca.crt the operator trusts when calling the management api, and with useClientAuth the tls.crt and tls.key it presents, defaults to the sslSecret
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Trust Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	TrustSecret string `json:"trustSecret,omitempty"`
 }
 
 // ActiveMQArtemis App product upgrade flags, this is deprecated in v1beta1, specifying the Version is sufficient
@@ -589,6 +867,138 @@ type ActiveMQArtemisStatus struct {
 
 	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Upgrade Status"
 	Upgrade UpgradeStatus `json:"upgrade,omitempty"`
+
+	// Current state of each broker, as reported by the broker management api
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Brokers Status"
+	Brokers []BrokerStatus `json:"brokers,omitempty"`
+
+	// Current state of the diverts declared in the spec
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Diverts Status"
+	Diverts []DivertStatus `json:"diverts,omitempty"`
+
+	// The last rotation of the credentials generated by the operator
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Credentials Rotation Status"
+	CredentialsRotation *CredentialsRotationStatus `json:"credentialsRotation,omitempty"`
+
+	// The endpoints and the client connection urls of the acceptors
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Acceptors Status"
+	Acceptors []AcceptorStatus `json:"acceptors,omitempty"`
+}
+
+type AcceptorStatus struct {
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Name",xDescriptors="urn:alm:descriptor:text"
+	Name string `json:"name"`
+	// The port of the acceptor in the cluster
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Port",xDescriptors="urn:alm:descriptor:text"
+	Port int32 `json:"port,omitempty"`
+	// The protocols the acceptor accepts
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Protocols"
+	Protocols []string `json:"protocols,omitempty"`
+	// True when the clients have to connect with tls
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="SSL Enabled",xDescriptors="urn:alm:descriptor:text"
+	SSLEnabled bool `json:"sslEnabled,omitempty"`
+	// True when the clients have to connect with tls from outside the cluster
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="External SSL Enabled",xDescriptors="urn:alm:descriptor:text"
+	ExternalSSLEnabled bool `json:"externalSSLEnabled,omitempty"`
+	// The address of the service that spreads the connections across the ready brokers
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Load Balanced"
+	LoadBalanced *AcceptorEndpointStatus `json:"loadBalanced,omitempty"`
+	// The address of the service of each broker pod
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Pods"
+	Pods []AcceptorEndpointStatus `json:"pods,omitempty"`
+}
+
+type AcceptorEndpointStatus struct {
+	// The ordinal of the broker pod, empty for the load balanced service
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Ordinal",xDescriptors="urn:alm:descriptor:text"
+	Ordinal string `json:"ordinal,omitempty"`
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Service",xDescriptors="urn:alm:descriptor:text"
+	Service string `json:"service"`
+	// The dns name of the service in the cluster
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Host",xDescriptors="urn:alm:descriptor:text"
+	Host string `json:"host,omitempty"`
+	// The host the acceptor is reachable on from outside the cluster, empty until it is assigned or for a node port
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="External Host",xDescriptors="urn:alm:descriptor:text"
+	ExternalHost string `json:"externalHost,omitempty"`
+	// The port the acceptor is reachable on from outside the cluster
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="External Port",xDescriptors="urn:alm:descriptor:text"
+	ExternalPort int32 `json:"externalPort,omitempty"`
+	// The urls clients connect to the service with in the cluster, by protocol
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Internal URLs"
+	InternalURLs map[string]string `json:"internalURLs,omitempty"`
+	// The urls clients connect to the service with from outside the cluster, by protocol
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="External URLs"
+	ExternalURLs map[string]string `json:"externalURLs,omitempty"`
+}
+
+type CredentialsRotationStatus struct {
+	// The time of the last rotation
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Last Rotation Time"
+	LastRotationTime metav1.Time `json:"lastRotationTime,omitempty"`
+	// The value of the rotate credentials annotation the last rotation was requested with
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Last Request",xDescriptors="urn:alm:descriptor:text"
+	LastRequest string `json:"lastRequest,omitempty"`
+	// True when the last rotation also rotated the generated cluster credentials, they are kept for a clustered deployment of more than one broker
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Cluster Credentials Rotated",xDescriptors="urn:alm:descriptor:text"
+	ClusterCredentialsRotated bool `json:"clusterCredentialsRotated,omitempty"`
+}
+
+type DivertStatus struct {
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Name",xDescriptors="urn:alm:descriptor:text"
+	Name string `json:"name"`
+	// True when the divert is deployed on every broker
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Applied",xDescriptors="urn:alm:descriptor:text"
+	Applied bool `json:"applied"`
+	// Ordinals of the brokers the divert is deployed on
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Ordinals"
+	Ordinals []string `json:"ordinals,omitempty"`
+}
+
+type BrokerStatus struct {
+	// The ordinal of the broker pod
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Ordinal",xDescriptors="urn:alm:descriptor:text"
+	Ordinal string `json:"ordinal"`
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Pod Name",xDescriptors="urn:alm:descriptor:text"
+	PodName string `json:"podName,omitempty"`
+	// The zone of the node the broker pod runs on
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Zone",xDescriptors="urn:alm:descriptor:text"
+	Zone string `json:"zone,omitempty"`
+	// Human readable uptime of the broker
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Uptime",xDescriptors="urn:alm:descriptor:text"
+	Uptime string `json:"uptime,omitempty"`
+	// Either live or backup
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Role",xDescriptors="urn:alm:descriptor:text"
+	Role string `json:"role,omitempty"`
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Node ID",xDescriptors="urn:alm:descriptor:text"
+	NodeID string `json:"nodeID,omitempty"`
+	// The cluster topology members known to this broker
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Cluster Topology"
+	ClusterTopology []TopologyMemberStatus `json:"clusterTopology,omitempty"`
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Address Count",xDescriptors="urn:alm:descriptor:text"
+	AddressCount int32 `json:"addressCount,omitempty"`
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Queue Count",xDescriptors="urn:alm:descriptor:text"
+	QueueCount int32 `json:"queueCount,omitempty"`
+	// Total number of messages held in all the queues of the broker
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Total Message Count",xDescriptors="urn:alm:descriptor:text"
+	TotalMessageCount int64 `json:"totalMessageCount,omitempty"`
+	// Addresses that are currently paging to disk
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Paging Addresses"
+	PagingAddresses []string `json:"pagingAddresses,omitempty"`
+	// Percentage of the disk used by the broker store
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Disk Store Usage",xDescriptors="urn:alm:descriptor:text"
+	DiskStoreUsagePercentage int32 `json:"diskStoreUsagePercentage,omitempty"`
+	// Last error returned by the broker management api, if any
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Error",xDescriptors="urn:alm:descriptor:text"
+	Error string `json:"error,omitempty"`
+}
+
+type TopologyMemberStatus struct {
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Node ID",xDescriptors="urn:alm:descriptor:text"
+	NodeID string `json:"nodeID"`
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Live",xDescriptors="urn:alm:descriptor:text"
+	Live string `json:"live,omitempty"`
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Backup",xDescriptors="urn:alm:descriptor:text"
+	Backup string `json:"backup,omitempty"`
 }
 
 type VersionStatus struct {
@@ -671,14 +1081,31 @@ const (
 	ValidConditionImagePairRequiredReason    = "InitImageMustBePairedWithBrokerImage"
 	ValidConditionInvalidVersionReason       = "SpecVersionInvalid"
 
-	ValidConditionPDBNonNilSelectorReason   = "PodDisruptionBudgetNonNilSelector"
-	ValidConditionFailedReservedLabelReason = "ReservedLabelReference"
-	ValidConditionFailedExtraMountReason    = "InvalidExtraMount"
+	ValidConditionPDBNonNilSelectorReason         = "PodDisruptionBudgetNonNilSelector"
+	ValidConditionFailedReservedLabelReason       = "ReservedLabelReference"
+	ValidConditionFailedExtraMountReason          = "InvalidExtraMount"
+	ValidConditionInvalidDivertReason             = "InvalidDivert"
+	ValidConditionInvalidDrainTargetReason        = "InvalidScaleToZeroDrainTarget"
+	ValidConditionInvalidHAPolicyReason           = "InvalidHAPolicy"
+	ValidConditionInvalidCredentialsReason        = "InvalidManagementCredentials"
+	ValidConditionInvalidClusterCredentialsReason = "InvalidClusterCredentials"
+	ValidConditionInvalidCertManagerReason        = "InvalidCertManagerCertificate"
+	ValidConditionInvalidExposeModeReason         = "InvalidExposeMode"
+	ValidConditionInvalidBindingReason            = "InvalidClientBinding"
+	ValidConditionInvalidMonitoringReason         = "InvalidMonitoring"
 
 	ReadyConditionType      = "Ready"
 	ReadyConditionReason    = "ResourceReady"
 	NotReadyConditionReason = "WaitingForAllConditions"
 
+	CertificatesReadyConditionType   = "CertificatesReady"
+	CertificatesReadyConditionReason = "CertificatesIssued"
+	CertificatesPendingReason        = "WaitingForCertificates"
+
+	CertificatesLoadedConditionType   = "CertificatesLoaded"
+	CertificatesLoadedConditionReason = "AllPodsLoaded"
+	CertificatesReloadPendingReason   = "ReloadPending"
+
 	ConfigAppliedConditionType     = "BrokerPropertiesApplied"
 	JaasConfigAppliedConditionType = "JaasPropertiesApplied"
 
@@ -688,4 +1115,36 @@ const (
 	ConfigAppliedConditionUnknownReason                   = "UnableToRetrieveStatus"
 	ConfigAppliedConditionOutOfSyncReason                 = "OutOfSync"
 	ConfigAppliedConditionNoJolokiaClientsAvailableReason = "NoJolokiaClientsAvailable"
+	ConfigAppliedConditionUnauthorizedReason              = "Unauthorized"
+	ConfigAppliedConditionBrokerUnavailableReason         = "BrokerUnavailable"
+
+	BrokerRoleLive   = "live"
+	BrokerRoleBackup = "backup"
+
+	HAPolicyNone        = "none"
+	HAPolicyReplication = "replication"
+	HAPolicySharedStore = "sharedStore"
+
+	ExposeModeIngress      = "ingress"
+	ExposeModeRoute        = "route"
+	ExposeModeLoadBalancer = "loadBalancer"
+	ExposeModeNodePort     = "nodePort"
+	ExposeModeGateway      = "gateway"
+
+	TLSTerminationPassthrough = "passthrough"
+	TLSTerminationEdge        = "edge"
+	TLSTerminationReencrypt   = "reencrypt"
+
+	// the placeholder of the ordinal of the broker pod in the host of an exposure
+	ExposureHostOrdinalPlaceholder = "$(ORDINAL)"
+
+	ServiceModePerPod       = "perPod"
+	ServiceModeLoadBalanced = "loadBalanced"
+	ServiceModeBoth         = "both"
+
+	CertificateReloadPolicyRestart = "restart"
+	CertificateReloadPolicyReload  = "reload"
+
+	// a new value requests a rotation of the credentials generated by the operator
+	RotateCredentialsAnnotation = "broker.amq.io/rotate-credentials"
 )
@@ -40,6 +40,9 @@ type ActiveMQArtemisAddressSpec struct {
 	// Whether or not delete the queue from broker when CR is undeployed(default false)
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Remove From Broker On Delete",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	RemoveFromBrokerOnDelete bool `json:"removeFromBrokerOnDelete,omitempty"`
+	// Whether or not move the messages of the previous queue to the new queue when the queue is renamed or moved to another address(default false)
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Move Messages On Change",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	MoveMessagesOnChange bool `json:"moveMessagesOnChange,omitempty"`
 	// User name for creating the queue or address
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="User",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	User *string `json:"user,omitempty"`
@@ -139,6 +142,26 @@ type QueueConfigurationType struct {
 type ActiveMQArtemisAddressStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Current state of the address across all the target brokers
+	//+optional
+	//+patchMergeKey=type
+	//+patchStrategy=merge
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
+
+	// Current state of the address on each target broker pod
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Pod Status"
+	PodStatus []AddressPodStatus `json:"podStatus,omitempty"`
+}
+
+type AddressPodStatus struct {
+	// The name of the broker pod
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Pod Name",xDescriptors="urn:alm:descriptor:text"
+	PodName string `json:"podName"`
+	// Current state of the address on the broker pod, the Synchronized condition reason is Created, Drifted or Failed
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -170,3 +193,24 @@ func init() {
 
 func (r *ActiveMQArtemisAddress) Hub() {
 }
+
+const (
+	AddressSynchronizedConditionType = "Synchronized"
+
+	AddressSynchronizedCreatedReason           = "Created"
+	AddressSynchronizedDriftedReason           = "Drifted"
+	AddressSynchronizedFailedReason            = "Failed"
+	AddressSynchronizedUnauthorizedReason      = "Unauthorized"
+	AddressSynchronizedInvalidReason           = "InvalidAddress"
+	AddressSynchronizedBrokerUnavailableReason = "BrokerUnavailable"
+	AddressSynchronizedNoBrokersReason         = "NoBrokersAvailable"
+
+	AddressMigrationCreatedConditionType         = "MigrationCreated"
+	AddressMigrationMessagesMovedConditionType   = "MigrationMessagesMoved"
+	AddressMigrationPreviousRemovedConditionType = "MigrationPreviousRemoved"
+
+	AddressMigrationSucceededReason = "Succeeded"
+	AddressMigrationSkippedReason   = "Skipped"
+	AddressMigrationPendingReason   = "Pending"
+	AddressMigrationFailedReason    = "Failed"
+)
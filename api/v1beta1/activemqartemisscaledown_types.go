@@ -35,12 +35,112 @@ type ActiveMQArtemisScaledownSpec struct {
 	// Specifies the minimum/maximum amount of compute resources required/allowed
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Customizes the drain pods, which otherwise inherit the scheduling and security settings of the broker pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Drain Pod Template"
+	DrainPodTemplate *DrainPodTemplateType `json:"drainPodTemplate,omitempty"`
+	// The target the brokers are drained to when scaled down to zero, when not set their messages are kept on their persistent volume claims
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Scale To Zero Target"
+	ScaleToZeroTarget *ScaleToZeroTargetType `json:"scaleToZeroTarget,omitempty"`
+	// The maximum number of ordinals drained in parallel, each to a different remaining broker when possible, defaults to 1
+	//+kubebuilder:validation:Minimum=1
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Drain Concurrency",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:podCount"}
+	DrainConcurrency *int32 `json:"drainConcurrency,omitempty"`
+}
+
+type ScaleToZeroTargetType struct {
+	// Name of the headless service whose endpoints are the brokers to drain to
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Headless Service Name",xDescriptors="urn:alm:descriptor:text"
+	HeadlessServiceName string `json:"headlessServiceName,omitempty"`
+	// Host of the broker to drain to, used when no headless service is set
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host",xDescriptors="urn:alm:descriptor:text"
+	Host string `json:"host,omitempty"`
+	// Port of the broker to drain to, published on the endpoints of the host, defaults to 61616
+	//+kubebuilder:validation:Minimum=1
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Port",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	Port int32 `json:"port,omitempty"`
+	// Name of the secret holding the AMQ_CLUSTER_USER and AMQ_CLUSTER_PASSWORD to connect to the target
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Credentials Secret Name",xDescriptors="urn:alm:descriptor:text"
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+type DrainPodTemplateType struct {
+	// Labels added to the drain pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Labels"
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations added to the drain pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Annotations"
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Specifies the node selector of the drain pods, replacing the one of the broker pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Selector",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:selector"}
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Specifies the tolerations of the drain pods, replacing the ones of the broker pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tolerations"
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Specifies the affinity of the drain pods, replacing the one of the broker pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Affinity Configurations"
+	Affinity *AffinityConfig `json:"affinity,omitempty"`
+	// Specifies the pod security context of the drain pods, replacing the one of the broker pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pod Security Context"
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// Specifies the security context of the drain container, replacing the one of the broker container
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Container Security Context"
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+	// Secrets used to pull the drain pod images, replacing the ones of the broker pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image Pull Secrets"
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// The priority class of the drain pods, replacing the one of the broker pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Priority Class Name",xDescriptors="urn:alm:descriptor:text"
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Extra environment variables of the drain container, they can override the default ones
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Environment Variables"
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Extra containers to run in the drain pods, like a proxy required to reach the other brokers.
+	// They are not validated by the CRD schema to keep it under the size limit of the API server
+	//+kubebuilder:validation:Schemaless
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Sidecars"
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
 }
 
 // ActiveMQArtemisScaledownStatus defines the observed state of ActiveMQArtemisScaledown
 type ActiveMQArtemisScaledownStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Message draining state of each scaled down broker ordinal
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Ordinals"
+	Ordinals []DrainOrdinalStatus `json:"ordinals,omitempty"`
+	// The maximum number of ordinals drained in parallel, the ordinals are drained from the highest down
+	// and the others stay Pending until a drain in progress completes
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Concurrency",xDescriptors="urn:alm:descriptor:text"
+	Concurrency int32 `json:"concurrency,omitempty"`
+}
+
+type DrainOrdinalStatus struct {
+	// The scaled down broker ordinal
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Ordinal",xDescriptors="urn:alm:descriptor:text"
+	Ordinal int32 `json:"ordinal"`
+	// The drain state of the ordinal, one of Pending, Draining, Completed or Failed
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="State",xDescriptors="urn:alm:descriptor:text"
+	State string `json:"state"`
+	// The name of the pod draining the messages of the ordinal
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Drain Pod Name",xDescriptors="urn:alm:descriptor:text"
+	DrainPodName string `json:"drainPodName,omitempty"`
+	// The broker pod, service or host the messages are drained to
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Target",xDescriptors="urn:alm:descriptor:text"
+	Target string `json:"target,omitempty"`
+	// When the drain pod was started
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Start Time",xDescriptors="urn:alm:descriptor:text"
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// When the drain pod finished
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Finish Time",xDescriptors="urn:alm:descriptor:text"
+	FinishTime *metav1.Time `json:"finishTime,omitempty"`
+	// The number of messages migrated to the remaining brokers, as reported by the drain pod
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Messages Migrated",xDescriptors="urn:alm:descriptor:text"
+	MessagesMigrated *int64 `json:"messagesMigrated,omitempty"`
+	// Details about the state, like why the drain failed
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Message",xDescriptors="urn:alm:descriptor:text"
+	Message string `json:"message,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -69,3 +169,13 @@ type ActiveMQArtemisScaledownList struct {
 func init() {
 	SchemeBuilder.Register(&ActiveMQArtemisScaledown{}, &ActiveMQArtemisScaledownList{})
 }
+
+// the drain pods connect to the broker they drain to on this port unless the target sets another one
+const DefaultDrainTargetPort int32 = 61616
+
+const (
+	DrainStatePending   = "Pending"
+	DrainStateDraining  = "Draining"
+	DrainStateCompleted = "Completed"
+	DrainStateFailed    = "Failed"
+)
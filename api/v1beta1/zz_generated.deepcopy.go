@@ -28,9 +28,80 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AcceptorEndpointStatus) DeepCopyInto(out *AcceptorEndpointStatus) {
+	*out = *in
+	if in.InternalURLs != nil {
+		in, out := &in.InternalURLs, &out.InternalURLs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExternalURLs != nil {
+		in, out := &in.ExternalURLs, &out.ExternalURLs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AcceptorEndpointStatus.
+func (in *AcceptorEndpointStatus) DeepCopy() *AcceptorEndpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AcceptorEndpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AcceptorStatus) DeepCopyInto(out *AcceptorStatus) {
+	*out = *in
+	if in.Protocols != nil {
+		in, out := &in.Protocols, &out.Protocols
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoadBalanced != nil {
+		in, out := &in.LoadBalanced, &out.LoadBalanced
+		*out = new(AcceptorEndpointStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]AcceptorEndpointStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AcceptorStatus.
+func (in *AcceptorStatus) DeepCopy() *AcceptorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AcceptorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AcceptorType) DeepCopyInto(out *AcceptorType) {
 	*out = *in
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(ExposureType)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SupportAdvisory != nil {
 		in, out := &in.SupportAdvisory, &out.SupportAdvisory
 		*out = new(bool)
@@ -91,7 +162,7 @@ func (in *ActiveMQArtemisAddress) DeepCopyInto(out *ActiveMQArtemisAddress) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisAddress.
@@ -192,6 +263,20 @@ func (in *ActiveMQArtemisAddressSpec) DeepCopy() *ActiveMQArtemisAddressSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ActiveMQArtemisAddressStatus) DeepCopyInto(out *ActiveMQArtemisAddressStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodStatus != nil {
+		in, out := &in.PodStatus, &out.PodStatus
+		*out = make([]AddressPodStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisAddressStatus.
@@ -242,7 +327,7 @@ func (in *ActiveMQArtemisScaledown) DeepCopyInto(out *ActiveMQArtemisScaledown)
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisScaledown.
@@ -299,6 +384,21 @@ func (in *ActiveMQArtemisScaledownList) DeepCopyObject() runtime.Object {
 func (in *ActiveMQArtemisScaledownSpec) DeepCopyInto(out *ActiveMQArtemisScaledownSpec) {
 	*out = *in
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.DrainPodTemplate != nil {
+		in, out := &in.DrainPodTemplate, &out.DrainPodTemplate
+		*out = new(DrainPodTemplateType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScaleToZeroTarget != nil {
+		in, out := &in.ScaleToZeroTarget, &out.ScaleToZeroTarget
+		*out = new(ScaleToZeroTargetType)
+		**out = **in
+	}
+	if in.DrainConcurrency != nil {
+		in, out := &in.DrainConcurrency, &out.DrainConcurrency
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisScaledownSpec.
@@ -314,6 +414,13 @@ func (in *ActiveMQArtemisScaledownSpec) DeepCopy() *ActiveMQArtemisScaledownSpec
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ActiveMQArtemisScaledownStatus) DeepCopyInto(out *ActiveMQArtemisScaledownStatus) {
 	*out = *in
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]DrainOrdinalStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisScaledownStatus.
@@ -426,6 +533,21 @@ func (in *ActiveMQArtemisSecurityStatus) DeepCopy() *ActiveMQArtemisSecurityStat
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ActiveMQArtemisSpec) DeepCopyInto(out *ActiveMQArtemisSpec) {
 	*out = *in
+	if in.ManagementCredentials != nil {
+		in, out := &in.ManagementCredentials, &out.ManagementCredentials
+		*out = new(ManagementCredentialsType)
+		**out = **in
+	}
+	if in.ClusterCredentials != nil {
+		in, out := &in.ClusterCredentials, &out.ClusterCredentials
+		*out = new(ClusterCredentialsType)
+		**out = **in
+	}
+	if in.CredentialsRotation != nil {
+		in, out := &in.CredentialsRotation, &out.CredentialsRotation
+		*out = new(CredentialsRotationType)
+		(*in).DeepCopyInto(*out)
+	}
 	in.DeploymentPlan.DeepCopyInto(&out.DeploymentPlan)
 	if in.Acceptors != nil {
 		in, out := &in.Acceptors, &out.Acceptors
@@ -437,11 +559,25 @@ func (in *ActiveMQArtemisSpec) DeepCopyInto(out *ActiveMQArtemisSpec) {
 	if in.Connectors != nil {
 		in, out := &in.Connectors, &out.Connectors
 		*out = make([]ConnectorType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClientBindings != nil {
+		in, out := &in.ClientBindings, &out.ClientBindings
+		*out = make([]ClientBindingType, len(*in))
 		copy(*out, *in)
 	}
-	out.Console = in.Console
+	in.Console.DeepCopyInto(&out.Console)
 	out.Upgrades = in.Upgrades
 	in.AddressSettings.DeepCopyInto(&out.AddressSettings)
+	if in.Diverts != nil {
+		in, out := &in.Diverts, &out.Diverts
+		*out = make([]DivertType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.BrokerProperties != nil {
 		in, out := &in.BrokerProperties, &out.BrokerProperties
 		*out = make([]string, len(*in))
@@ -484,6 +620,32 @@ func (in *ActiveMQArtemisStatus) DeepCopyInto(out *ActiveMQArtemisStatus) {
 	}
 	out.Version = in.Version
 	out.Upgrade = in.Upgrade
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]BrokerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Diverts != nil {
+		in, out := &in.Diverts, &out.Diverts
+		*out = make([]DivertStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CredentialsRotation != nil {
+		in, out := &in.CredentialsRotation, &out.CredentialsRotation
+		*out = new(CredentialsRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Acceptors != nil {
+		in, out := &in.Acceptors, &out.Acceptors
+		*out = make([]AcceptorStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisStatus.
@@ -511,6 +673,28 @@ func (in *ActiveMQArtemisUpgrades) DeepCopy() *ActiveMQArtemisUpgrades {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddressPodStatus) DeepCopyInto(out *AddressPodStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddressPodStatus.
+func (in *AddressPodStatus) DeepCopy() *AddressPodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AddressPodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddressSettingType) DeepCopyInto(out *AddressSettingType) {
 	*out = *in
@@ -1013,6 +1197,107 @@ func (in *BrokerSecuritySettingType) DeepCopy() *BrokerSecuritySettingType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerStatus) DeepCopyInto(out *BrokerStatus) {
+	*out = *in
+	if in.ClusterTopology != nil {
+		in, out := &in.ClusterTopology, &out.ClusterTopology
+		*out = make([]TopologyMemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.PagingAddresses != nil {
+		in, out := &in.PagingAddresses, &out.PagingAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerStatus.
+func (in *BrokerStatus) DeepCopy() *BrokerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerRefType) DeepCopyInto(out *CertManagerIssuerRefType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerRefType.
+func (in *CertManagerIssuerRefType) DeepCopy() *CertManagerIssuerRefType {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerRefType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerType) DeepCopyInto(out *CertManagerType) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerType.
+func (in *CertManagerType) DeepCopy() *CertManagerType {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientBindingType) DeepCopyInto(out *ClientBindingType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientBindingType.
+func (in *ClientBindingType) DeepCopy() *ClientBindingType {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientBindingType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCredentialsType) DeepCopyInto(out *ClusterCredentialsType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCredentialsType.
+func (in *ClusterCredentialsType) DeepCopy() *ClusterCredentialsType {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCredentialsType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectorConfigType) DeepCopyInto(out *ConnectorConfigType) {
 	*out = *in
@@ -1101,6 +1386,16 @@ func (in *ConnectorConfigType) DeepCopy() *ConnectorConfigType {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectorType) DeepCopyInto(out *ConnectorType) {
 	*out = *in
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(ExposureType)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectorType.
@@ -1116,6 +1411,16 @@ func (in *ConnectorType) DeepCopy() *ConnectorType {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConsoleType) DeepCopyInto(out *ConsoleType) {
 	*out = *in
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(ExposureType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerType)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsoleType.
@@ -1128,6 +1433,42 @@ func (in *ConsoleType) DeepCopy() *ConsoleType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsRotationStatus) DeepCopyInto(out *CredentialsRotationStatus) {
+	*out = *in
+	in.LastRotationTime.DeepCopyInto(&out.LastRotationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsRotationStatus.
+func (in *CredentialsRotationStatus) DeepCopy() *CredentialsRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsRotationType) DeepCopyInto(out *CredentialsRotationType) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsRotationType.
+func (in *CredentialsRotationType) DeepCopy() *CredentialsRotationType {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsRotationType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultAccessType) DeepCopyInto(out *DefaultAccessType) {
 	*out = *in
@@ -1190,6 +1531,11 @@ func (in *DeploymentPlanType) DeepCopyInto(out *DeploymentPlanType) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringType)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]v1.Toleration, len(*in))
@@ -1211,6 +1557,18 @@ func (in *DeploymentPlanType) DeepCopyInto(out *DeploymentPlanType) {
 			(*out)[key] = val
 		}
 	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ZoneAware != nil {
+		in, out := &in.ZoneAware, &out.ZoneAware
+		*out = new(bool)
+		**out = **in
+	}
 	in.Affinity.DeepCopyInto(&out.Affinity)
 	if in.PodSecurityContext != nil {
 		in, out := &in.PodSecurityContext, &out.PodSecurityContext
@@ -1229,6 +1587,26 @@ func (in *DeploymentPlanType) DeepCopyInto(out *DeploymentPlanType) {
 		*out = new(policyv1.PodDisruptionBudgetSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DrainPodTemplate != nil {
+		in, out := &in.DrainPodTemplate, &out.DrainPodTemplate
+		*out = new(DrainPodTemplateType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScaleToZeroDrainTarget != nil {
+		in, out := &in.ScaleToZeroDrainTarget, &out.ScaleToZeroDrainTarget
+		*out = new(DrainTargetType)
+		**out = **in
+	}
+	if in.DrainConcurrency != nil {
+		in, out := &in.DrainConcurrency, &out.DrainConcurrency
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HAPolicy != nil {
+		in, out := &in.HAPolicy, &out.HAPolicy
+		*out = new(HAPolicyType)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentPlanType.
@@ -1241,6 +1619,200 @@ func (in *DeploymentPlanType) DeepCopy() *DeploymentPlanType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DivertStatus) DeepCopyInto(out *DivertStatus) {
+	*out = *in
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DivertStatus.
+func (in *DivertStatus) DeepCopy() *DivertStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DivertStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DivertType) DeepCopyInto(out *DivertType) {
+	*out = *in
+	if in.Exclusive != nil {
+		in, out := &in.Exclusive, &out.Exclusive
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TransformerProperties != nil {
+		in, out := &in.TransformerProperties, &out.TransformerProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DivertType.
+func (in *DivertType) DeepCopy() *DivertType {
+	if in == nil {
+		return nil
+	}
+	out := new(DivertType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainOrdinalStatus) DeepCopyInto(out *DrainOrdinalStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishTime != nil {
+		in, out := &in.FinishTime, &out.FinishTime
+		*out = (*in).DeepCopy()
+	}
+	if in.MessagesMigrated != nil {
+		in, out := &in.MessagesMigrated, &out.MessagesMigrated
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainOrdinalStatus.
+func (in *DrainOrdinalStatus) DeepCopy() *DrainOrdinalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainOrdinalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainPodTemplateType) DeepCopyInto(out *DrainPodTemplateType) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(AffinityConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainPodTemplateType.
+func (in *DrainPodTemplateType) DeepCopy() *DrainPodTemplateType {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainPodTemplateType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainTargetType) DeepCopyInto(out *DrainTargetType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainTargetType.
+func (in *DrainTargetType) DeepCopy() *DrainTargetType {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainTargetType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposureType) DeepCopyInto(out *ExposureType) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.GatewayRef != nil {
+		in, out := &in.GatewayRef, &out.GatewayRef
+		*out = new(GatewayRefType)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposureType.
+func (in *ExposureType) DeepCopy() *ExposureType {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposureType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalConfigStatus) DeepCopyInto(out *ExternalConfigStatus) {
 	*out = *in
@@ -1281,6 +1853,21 @@ func (in *ExtraMountsType) DeepCopy() *ExtraMountsType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRefType) DeepCopyInto(out *GatewayRefType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRefType.
+func (in *GatewayRefType) DeepCopy() *GatewayRefType {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRefType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GuestLoginModuleType) DeepCopyInto(out *GuestLoginModuleType) {
 	*out = *in
@@ -1306,6 +1893,32 @@ func (in *GuestLoginModuleType) DeepCopy() *GuestLoginModuleType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HAPolicyType) DeepCopyInto(out *HAPolicyType) {
+	*out = *in
+	if in.AllowFailBack != nil {
+		in, out := &in.AllowFailBack, &out.AllowFailBack
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CheckForLiveServer != nil {
+		in, out := &in.CheckForLiveServer, &out.CheckForLiveServer
+		*out = new(bool)
+		**out = **in
+	}
+	out.SharedStorage = in.SharedStorage
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HAPolicyType.
+func (in *HAPolicyType) DeepCopy() *HAPolicyType {
+	if in == nil {
+		return nil
+	}
+	out := new(HAPolicyType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeyValueType) DeepCopyInto(out *KeyValueType) {
 	*out = *in
@@ -1642,6 +2255,21 @@ func (in *LoginModulesType) DeepCopy() *LoginModulesType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementCredentialsType) DeepCopyInto(out *ManagementCredentialsType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementCredentialsType.
+func (in *ManagementCredentialsType) DeepCopy() *ManagementCredentialsType {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementCredentialsType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagementSecuritySettingsType) DeepCopyInto(out *ManagementSecuritySettingsType) {
 	*out = *in
@@ -1664,6 +2292,63 @@ func (in *ManagementSecuritySettingsType) DeepCopy() *ManagementSecuritySettings
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringAlertsType) DeepCopyInto(out *MonitoringAlertsType) {
+	*out = *in
+	if in.AddressMemoryUsagePercentage != nil {
+		in, out := &in.AddressMemoryUsagePercentage, &out.AddressMemoryUsagePercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.For != nil {
+		in, out := &in.For, &out.For
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringAlertsType.
+func (in *MonitoringAlertsType) DeepCopy() *MonitoringAlertsType {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringAlertsType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringType) DeepCopyInto(out *MonitoringType) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(MonitoringAlertsType)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringType.
+func (in *MonitoringType) DeepCopy() *MonitoringType {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PermissionType) DeepCopyInto(out *PermissionType) {
 	*out = *in
@@ -1908,6 +2593,21 @@ func (in *RoleAccessType) DeepCopy() *RoleAccessType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleToZeroTargetType) DeepCopyInto(out *ScaleToZeroTargetType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleToZeroTargetType.
+func (in *ScaleToZeroTargetType) DeepCopy() *ScaleToZeroTargetType {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleToZeroTargetType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityDomainsType) DeepCopyInto(out *SecurityDomainsType) {
 	*out = *in
@@ -1963,6 +2663,21 @@ func (in *StorageType) DeepCopy() *StorageType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyMemberStatus) DeepCopyInto(out *TopologyMemberStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyMemberStatus.
+func (in *TopologyMemberStatus) DeepCopy() *TopologyMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpgradeStatus) DeepCopyInto(out *UpgradeStatus) {
 	*out = *in
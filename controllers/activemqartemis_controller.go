@@ -42,6 +42,8 @@ import (
 
 	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia_client"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/selectors"
 )
 
@@ -115,13 +117,17 @@ type ActiveMQArtemisReconciler struct {
 //+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=pods,verbs=get;list
 //+kubebuilder:rbac:groups="",namespace=activemq-artemis-operator,resources=pods;services;endpoints;persistentvolumeclaims;events;configmaps;secrets;routes;serviceaccounts,verbs=*
 //+kubebuilder:rbac:groups="",namespace=activemq-artemis-operator,resources=namespaces,verbs=get
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get
 //+kubebuilder:rbac:groups=apps,namespace=activemq-artemis-operator,resources=deployments;daemonsets;replicasets;statefulsets,verbs=*
-//+kubebuilder:rbac:groups=networking.k8s.io,namespace=activemq-artemis-operator,resources=ingresses,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,namespace=activemq-artemis-operator,resources=ingresses,verbs=get;list;watch;create;delete;update
 //+kubebuilder:rbac:groups=route.openshift.io,namespace=activemq-artemis-operator,resources=routes;routes/custom-host;routes/status,verbs=get;list;watch;create;delete;update
-//+kubebuilder:rbac:groups=monitoring.coreos.com,namespace=activemq-artemis-operator,resources=servicemonitors,verbs=get;create
+//+kubebuilder:rbac:groups=monitoring.coreos.com,namespace=activemq-artemis-operator,resources=servicemonitors;prometheusrules,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=apps,namespace=activemq-artemis-operator,resources=deployments/finalizers,verbs=update
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,namespace=activemq-artemis-operator,resources=roles;rolebindings,verbs=create;get;delete
 //+kubebuilder:rbac:groups=policy,namespace=activemq-artemis-operator,resources=poddisruptionbudgets,verbs=create;get;delete
+//+kubebuilder:rbac:groups=cert-manager.io,namespace=activemq-artemis-operator,resources=certificates,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,namespace=activemq-artemis-operator,resources=tlsroutes,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,namespace=activemq-artemis-operator,resources=gateways,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -146,6 +152,7 @@ func (r *ActiveMQArtemisReconciler) Reconcile(ctx context.Context, request ctrl.
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			reqLogger.Info("ActiveMQArtemis Controller Reconcile encountered a IsNotFound, for request NamespacedName " + request.NamespacedName.String())
+			jolokia_client.EvictBrokers(types.NamespacedName{Name: namer.CrToSS(request.Name), Namespace: request.Namespace}, nil)
 			return ctrl.Result{}, nil
 		}
 		reqLogger.Error(err, "unable to retrieve the ActiveMQArtemis", "request", request)
@@ -163,6 +170,31 @@ func (r *ActiveMQArtemisReconciler) Reconcile(ctx context.Context, request ctrl.
 		reconciler.Process(customResource, *namer, r.Client, r.Scheme)
 
 		result = UpdateBrokerPropertiesStatus(customResource, r.Client, r.Scheme)
+
+		brokersStatusResult := UpdateBrokersStatus(customResource, r.Client, r.Scheme)
+		if result.IsZero() {
+			result = brokersStatusResult
+		}
+
+		rollResult := RollPairedPods(customResource, r.Client)
+		if result.IsZero() {
+			result = rollResult
+		}
+
+		reloadResult := ReloadAcceptorCertificates(customResource, r.Client, r.Scheme)
+		if result.IsZero() {
+			result = reloadResult
+		}
+
+		acceptorsStatusResult := UpdateAcceptorsStatus(customResource, r.Client)
+		if result.IsZero() {
+			result = acceptorsStatusResult
+		}
+
+		if result.IsZero() && meta.IsStatusConditionFalse(customResource.Status.Conditions, brokerv1beta1.CertificatesReadyConditionType) {
+			// certificates are not watched
+			result = ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}
+		}
 	}
 
 	UpdateStatus(customResource, r.Client, request.NamespacedName, *namer)
@@ -218,6 +250,55 @@ func validate(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Cli
 		}
 	}
 
+	if validationCondition.Status == metav1.ConditionTrue && len(customResource.Spec.Diverts) > 0 {
+		condition := validateDiverts(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status == metav1.ConditionTrue && customResource.Spec.DeploymentPlan.ScaleToZeroDrainTarget != nil {
+		condition := validateScaleToZeroDrainTarget(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status == metav1.ConditionTrue && isHAPaired(customResource) {
+		condition := validateHAPolicy(customResource, client, namer)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status == metav1.ConditionTrue {
+		condition := validateCertManager(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status == metav1.ConditionTrue {
+		condition := validateExposeModes(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status == metav1.ConditionTrue && isMonitoringEnabled(customResource) {
+		condition := validateMonitoring(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status == metav1.ConditionTrue {
+		condition := validateClientBindings(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
 	if validationCondition.Status == metav1.ConditionTrue {
 		condition, retry = validateSSLEnabledSecrets(customResource, client, scheme, namer)
 		if condition != nil {
@@ -225,6 +306,20 @@ func validate(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Cli
 		}
 	}
 
+	if validationCondition.Status == metav1.ConditionTrue && customResource.Spec.ManagementCredentials != nil {
+		condition, retry = validateManagementCredentials(customResource, client, scheme)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status == metav1.ConditionTrue && customResource.Spec.ClusterCredentials != nil {
+		condition, retry = validateClusterCredentials(customResource, client, scheme)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
 	validationCondition.ObservedGeneration = customResource.Generation
 	meta.SetStatusCondition(&customResource.Status.Conditions, validationCondition)
 
@@ -238,7 +333,8 @@ func validate(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Cli
 func validateSSLEnabledSecrets(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, scheme *runtime.Scheme, namer Namers) (*metav1.Condition, bool) {
 
 	var retry = true
-	if customResource.Spec.Console.SSLEnabled {
+	// a secret issued by cert-manager only exists once the operator requested its certificate
+	if customResource.Spec.Console.SSLEnabled && customResource.Spec.Console.CertManager == nil {
 
 		secretName := namer.SecretsConsoleNameBuilder.Name()
 
@@ -277,6 +373,74 @@ func validateSSLEnabledSecrets(customResource *brokerv1beta1.ActiveMQArtemis, cl
 		if Condition != nil {
 			return Condition, retry
 		}
+
+		if trustSecretName := customResource.Spec.Console.TrustSecret; trustSecretName != "" {
+			trustSecret := corev1.Secret{}
+			if !retrieveResource(trustSecretName, customResource.Namespace, &trustSecret, client, scheme) {
+				return &metav1.Condition{
+					Type:    brokerv1beta1.ValidConditionType,
+					Status:  metav1.ConditionFalse,
+					Reason:  brokerv1beta1.ValidConditionMissingResourcesReason,
+					Message: fmt.Sprintf(".Spec.Console.TrustSecret %v is not found", trustSecretName),
+				}, retry
+			}
+
+			trustKeys := []string{jolokia_client.TrustSecretCAKey}
+			if customResource.Spec.Console.UseClientAuth {
+				trustKeys = append(trustKeys, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+			}
+			for _, key := range trustKeys {
+				Condition := AssertSecretContainsKey(trustSecret, key, ".Spec.Console.TrustSecret requires")
+				if Condition != nil {
+					return Condition, retry
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// validateManagementCredentials checks that a referenced management credentials secret holds both keys,
+// the admin credentials secret is created by the operator so it is not checked
+func validateManagementCredentials(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, scheme *runtime.Scheme) (*metav1.Condition, bool) {
+	if customResource.Spec.ManagementCredentials.SecretName == "" {
+		return nil, false
+	}
+
+	secretName, userKey, passwordKey, _ := jolokia_client.GetManagementCredentialsRef(customResource)
+	return validateCredentialsSecret(".Spec.ManagementCredentials", brokerv1beta1.ValidConditionInvalidCredentialsReason, secretName, customResource.Namespace, []string{userKey, passwordKey}, client, scheme)
+}
+
+// validateClusterCredentials checks that a referenced cluster credentials secret holds both keys
+func validateClusterCredentials(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, scheme *runtime.Scheme) (*metav1.Condition, bool) {
+	if customResource.Spec.ClusterCredentials.SecretName == "" {
+		return nil, false
+	}
+
+	secretName, userKey, passwordKey := GetClusterCredentialsRef(customResource)
+	return validateCredentialsSecret(".Spec.ClusterCredentials", brokerv1beta1.ValidConditionInvalidClusterCredentialsReason, secretName, customResource.Namespace, []string{userKey, passwordKey}, client, scheme)
+}
+
+func validateCredentialsSecret(contextMessage string, reason string, secretName string, namespace string, keys []string, client rtclient.Client, scheme *runtime.Scheme) (*metav1.Condition, bool) {
+	secret := corev1.Secret{}
+	if !retrieveResource(secretName, namespace, &secret, client, scheme) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: fmt.Sprintf("%v.SecretName %v is not found", contextMessage, secretName),
+		}, true
+	}
+
+	for _, key := range keys {
+		if len(secret.Data[key]) == 0 {
+			return &metav1.Condition{
+				Type:    brokerv1beta1.ValidConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  reason,
+				Message: fmt.Sprintf("%v.SecretName %v requires a non empty %v", contextMessage, secretName, key),
+			}, true
+		}
 	}
 	return nil, false
 }
@@ -294,6 +458,122 @@ func validatePodDisruption(customResource *brokerv1beta1.ActiveMQArtemis) *metav
 	return nil
 }
 
+var divertNameMatcher = regexp.MustCompile(`^[^.=:\s]+$`)
+
+func validateDiverts(customResource *brokerv1beta1.ActiveMQArtemis) *metav1.Condition {
+	names := map[string]bool{}
+	for index, divert := range customResource.Spec.Diverts {
+		contextMessage := fmt.Sprintf(".Spec.Diverts[%d]", index)
+		var reason string
+		switch {
+		case !divertNameMatcher.MatchString(divert.Name):
+			reason = fmt.Sprintf("name %q must be non empty and must not contain '.', '=', ':' or white space", divert.Name)
+		case names[divert.Name]:
+			reason = fmt.Sprintf("name %q is not unique", divert.Name)
+		case strings.TrimSpace(divert.Address) == "":
+			reason = "address is required"
+		case strings.TrimSpace(divert.ForwardingAddress) == "":
+			reason = "forwardingAddress is required"
+		case strings.TrimSpace(divert.Address) != divert.Address || strings.TrimSpace(divert.ForwardingAddress) != divert.ForwardingAddress:
+			reason = "address and forwardingAddress must not have leading or trailing white space"
+		case divert.Address == divert.ForwardingAddress:
+			reason = fmt.Sprintf("address and forwardingAddress must differ, both are %q", divert.Address)
+		case divert.RoutingType != "" && !isValidDivertRoutingType(divert.RoutingType):
+			reason = fmt.Sprintf("routingType %q must be one of STRIP, PASS, ANYCAST or MULTICAST", divert.RoutingType)
+		case divert.TransformerClassName == "" && len(divert.TransformerProperties) > 0:
+			reason = "transformerProperties requires a transformerClassName"
+		}
+		if reason != "" {
+			return &metav1.Condition{
+				Type:    brokerv1beta1.ValidConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  brokerv1beta1.ValidConditionInvalidDivertReason,
+				Message: fmt.Sprintf("%s %s", contextMessage, reason),
+			}
+		}
+		names[divert.Name] = true
+	}
+	return nil
+}
+
+func isValidDivertRoutingType(routingType string) bool {
+	switch strings.ToUpper(routingType) {
+	case "STRIP", "PASS", "ANYCAST", "MULTICAST":
+		return true
+	}
+	return false
+}
+
+func validateScaleToZeroDrainTarget(customResource *brokerv1beta1.ActiveMQArtemis) *metav1.Condition {
+	target := customResource.Spec.DeploymentPlan.ScaleToZeroDrainTarget
+	contextMessage := ".Spec.DeploymentPlan.ScaleToZeroDrainTarget"
+	var reason string
+	switch {
+	case (target.ActiveMQArtemis == "") == (target.ConnectorName == ""):
+		reason = "requires exactly one of activeMQArtemis or connectorName"
+	case target.ActiveMQArtemis == customResource.Name:
+		reason = "activeMQArtemis must not be the scaled down broker itself"
+	case customResource.Spec.DeploymentPlan.MessageMigration != nil && !*customResource.Spec.DeploymentPlan.MessageMigration:
+		reason = "requires messageMigration"
+	case target.ConnectorName != "":
+		connector := getConnector(customResource, target.ConnectorName)
+		if connector == nil {
+			reason = fmt.Sprintf("connectorName %q does not match any of .Spec.Connectors", target.ConnectorName)
+		}
+	}
+	if reason != "" {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionInvalidDrainTargetReason,
+			Message: fmt.Sprintf("%s %s", contextMessage, reason),
+		}
+	}
+	return nil
+}
+
+func getConnector(customResource *brokerv1beta1.ActiveMQArtemis, name string) *brokerv1beta1.ConnectorType {
+	for i, connector := range customResource.Spec.Connectors {
+		if connector.Name == name {
+			return &customResource.Spec.Connectors[i]
+		}
+	}
+	return nil
+}
+
+func validateHAPolicy(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, namer Namers) *metav1.Condition {
+	if !isClustered(customResource) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionInvalidHAPolicyReason,
+			Message: ".Spec.DeploymentPlan.HAPolicy requires a clustered deployment, a backup finds its live broker through the cluster connection",
+		}
+	}
+
+	if customResource.Spec.DeploymentPlan.ScaleToZeroDrainTarget != nil {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionInvalidHAPolicyReason,
+			Message: ".Spec.DeploymentPlan.HAPolicy cannot be combined with .Spec.DeploymentPlan.ScaleToZeroDrainTarget, paired brokers are not drained",
+		}
+	}
+
+	deployed := &appsv1.StatefulSet{}
+	ssNamespacedName := types.NamespacedName{Name: namer.SsNameBuilder.Name(), Namespace: customResource.Namespace}
+	if err := client.Get(context.TODO(), ssNamespacedName, deployed); err == nil && deployed.Spec.PodManagementPolicy != appsv1.ParallelPodManagement {
+		// the pod management policy of a StatefulSet is immutable
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionInvalidHAPolicyReason,
+			Message: fmt.Sprintf(".Spec.DeploymentPlan.HAPolicy %s requires Parallel pod management, it can only be set on a new deployment or after the StatefulSet %s is deleted", getHAPolicy(customResource), deployed.Name),
+		}
+	}
+	return nil
+}
+
 func validateBrokerVersion(customResource *brokerv1beta1.ActiveMQArtemis) *metav1.Condition {
 	if customResource.Spec.Version != "" {
 		if isLockedDown(customResource.Spec.DeploymentPlan.Image) || isLockedDown(customResource.Spec.DeploymentPlan.InitImage) {
@@ -494,30 +774,32 @@ func hasExtraMounts(cr *brokerv1beta1.ActiveMQArtemis) bool {
 }
 
 type Namers struct {
-	SsGlobalName                  string
-	SsNameBuilder                 namer.NamerData
-	SvcHeadlessNameBuilder        namer.NamerData
-	SvcPingNameBuilder            namer.NamerData
-	PodsNameBuilder               namer.NamerData
-	SecretsCredentialsNameBuilder namer.NamerData
-	SecretsConsoleNameBuilder     namer.NamerData
-	SecretsNettyNameBuilder       namer.NamerData
-	LabelBuilder                  selectors.LabelerData
-	GLOBAL_DATA_PATH              string
+	SsGlobalName                          string
+	SsNameBuilder                         namer.NamerData
+	SvcHeadlessNameBuilder                namer.NamerData
+	SvcPingNameBuilder                    namer.NamerData
+	PodsNameBuilder                       namer.NamerData
+	SecretsCredentialsNameBuilder         namer.NamerData
+	SecretsPreviousCredentialsNameBuilder namer.NamerData
+	SecretsConsoleNameBuilder             namer.NamerData
+	SecretsNettyNameBuilder               namer.NamerData
+	LabelBuilder                          selectors.LabelerData
+	GLOBAL_DATA_PATH                      string
 }
 
 func MakeNamers(customResource *brokerv1beta1.ActiveMQArtemis) *Namers {
 	newNamers := Namers{
-		SsGlobalName:                  "",
-		SsNameBuilder:                 namer.NamerData{},
-		SvcHeadlessNameBuilder:        namer.NamerData{},
-		SvcPingNameBuilder:            namer.NamerData{},
-		PodsNameBuilder:               namer.NamerData{},
-		SecretsCredentialsNameBuilder: namer.NamerData{},
-		SecretsConsoleNameBuilder:     namer.NamerData{},
-		SecretsNettyNameBuilder:       namer.NamerData{},
-		LabelBuilder:                  selectors.LabelerData{},
-		GLOBAL_DATA_PATH:              "/opt/" + customResource.Name + "/data",
+		SsGlobalName:                          "",
+		SsNameBuilder:                         namer.NamerData{},
+		SvcHeadlessNameBuilder:                namer.NamerData{},
+		SvcPingNameBuilder:                    namer.NamerData{},
+		PodsNameBuilder:                       namer.NamerData{},
+		SecretsCredentialsNameBuilder:         namer.NamerData{},
+		SecretsPreviousCredentialsNameBuilder: namer.NamerData{},
+		SecretsConsoleNameBuilder:             namer.NamerData{},
+		SecretsNettyNameBuilder:               namer.NamerData{},
+		LabelBuilder:                          selectors.LabelerData{},
+		GLOBAL_DATA_PATH:                      "/opt/" + customResource.Name + "/data",
 	}
 	newNamers.SsNameBuilder.Base(customResource.Name).Suffix("ss").Generate()
 	newNamers.SsGlobalName = customResource.Name
@@ -525,6 +807,7 @@ func MakeNamers(customResource *brokerv1beta1.ActiveMQArtemis) *Namers {
 	newNamers.SvcPingNameBuilder.Prefix(customResource.Name).Base("ping").Suffix("svc").Generate()
 	newNamers.PodsNameBuilder.Base(customResource.Name).Suffix("container").Generate()
 	newNamers.SecretsCredentialsNameBuilder.Prefix(customResource.Name).Base("credentials").Suffix("secret").Generate()
+	newNamers.SecretsPreviousCredentialsNameBuilder.Prefix(customResource.Name).Base("credentials").Suffix("previous").Generate()
 	if customResource.Spec.Console.SSLSecret != "" {
 		newNamers.SecretsConsoleNameBuilder.SetName(customResource.Spec.Console.SSLSecret)
 	} else {
@@ -607,6 +890,18 @@ func UpdateCRStatus(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, n
 	if !reflect.DeepEqual(current.Status.PodStatus, cr.Status.PodStatus) {
 		return resources.UpdateStatus(client, cr)
 	}
+	if !reflect.DeepEqual(current.Status.Brokers, cr.Status.Brokers) {
+		return resources.UpdateStatus(client, cr)
+	}
+	if !reflect.DeepEqual(current.Status.Diverts, cr.Status.Diverts) {
+		return resources.UpdateStatus(client, cr)
+	}
+	if !reflect.DeepEqual(current.Status.CredentialsRotation, cr.Status.CredentialsRotation) {
+		return resources.UpdateStatus(client, cr)
+	}
+	if !reflect.DeepEqual(current.Status.Acceptors, cr.Status.Acceptors) {
+		return resources.UpdateStatus(client, cr)
+	}
 	if len(current.Status.Conditions) != len(cr.Status.Conditions) {
 		return resources.UpdateStatus(client, cr)
 	}
@@ -655,6 +950,26 @@ func (e unknownJolokiaError) Requeue() bool {
 	return false
 }
 
+// jolokiaRequestError is a failed management request to a broker, classified by jolokia.KindOf
+type jolokiaRequestError struct {
+	cause error
+}
+
+func NewJolokiaRequestError(err error) jolokiaRequestError {
+	return jolokiaRequestError{
+		err,
+	}
+}
+
+func (e jolokiaRequestError) Error() string {
+	return e.cause.Error()
+}
+
+// Requeue is false for failures that persist until the management credentials change
+func (e jolokiaRequestError) Requeue() bool {
+	return !jolokia.IsPermanent(e.cause)
+}
+
 func NewJolokiaClientsNotFoundError(err error) jolokiaClientNotFoundError {
 	return jolokiaClientNotFoundError{
 		err,
@@ -10,6 +10,7 @@ import (
 	"hash/adler32"
 	osruntime "runtime"
 	"sort"
+	"time"
 	"unicode"
 
 	"github.com/blang/semver/v4"
@@ -26,11 +27,14 @@ import (
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/serviceports"
 	ss "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/statefulsets"
+	mgmt "github.com/artemiscloud/activemq-artemis-operator/pkg/utils/artemis"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/channels"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/cr2jinja2"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia_client"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/random"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/selectors"
 	"github.com/artemiscloud/activemq-artemis-operator/version"
 	"github.com/go-logr/logr"
@@ -57,6 +61,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
 
@@ -86,10 +91,17 @@ const (
 	JaasConfigKey         = "login.config"
 	LoggingConfigKey      = "logging.properties"
 	DefaultDeploymentSize = int32(1)
+
+	ClusterCredentialsUserKey     = "username"
+	ClusterCredentialsPasswordKey = "password"
+
+	// the generation of the statefulset the previous credentials were rotated away from
+	rotatedGenerationAnnotation = "broker.amq.io/rotated-generation"
 )
 
 var defaultMessageMigration bool = true
 var lastStatusMap map[types.NamespacedName]olm.DeploymentStatus = make(map[types.NamespacedName]olm.DeploymentStatus)
+var lastBrokersStatusPollMap map[types.NamespacedName]time.Time = make(map[types.NamespacedName]time.Time)
 
 // the helper script looks for "/amq/scripts/post-config.sh"
 // and run it if exists.
@@ -104,6 +116,8 @@ var yacfgProfileVersion = version.YacfgProfileVersionFromFullVersion[version.Lat
 type ActiveMQArtemisReconcilerImpl struct {
 	requestedResources []rtclient.Object
 	deployed           map[reflect.Type][]rtclient.Object
+	// the tls routes of the gateway api are synced unstructured, apart from the requested resources
+	requestedTLSRoutes []*unstructured.Unstructured
 }
 
 type ValueInfo struct {
@@ -144,10 +158,18 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) Process(customResource *brokerv
 
 	reconciler.ProcessCredentials(customResource, namer, client, scheme, desiredStatefulSet)
 
+	reconciler.ProcessCertificates(customResource, namer, client, scheme, desiredStatefulSet)
+
 	reconciler.ProcessAcceptorsAndConnectors(customResource, namer, client, scheme, desiredStatefulSet)
 
 	reconciler.ProcessConsole(customResource, namer, client, scheme, desiredStatefulSet)
 
+	reconciler.ProcessMonitoring(customResource, namer, client, scheme)
+
+	reconciler.ProcessTLSRoutes(customResource, namer, client, scheme)
+
+	reconciler.ProcessClientBindings(customResource, namer, client)
+
 	// mods to env var values sourced from secrets are not detected by process resources
 	// track updates in trigger env var that has a total checksum
 	trackSecretCheckSumInEnvVar(reconciler.requestedResources, desiredStatefulSet.Spec.Template.Spec.Containers)
@@ -265,6 +287,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessCredentials(customResour
 	}
 	// TODO: Remove singular admin level user and password in favour of at least guest and admin access
 	secretName := namer.SecretsCredentialsNameBuilder.Name()
+	rotate := isCredentialsRotationDue(customResource, secretName, client)
 	for {
 		adminUser.Value = customResource.Spec.AdminUser
 		if adminUser.Value != "" {
@@ -304,16 +327,168 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessCredentials(customResour
 	}
 	envVars["AMQ_PASSWORD"] = adminPassword
 
-	envVars["AMQ_CLUSTER_USER"] = ValueInfo{
-		Value:   environments.GLOBAL_AMQ_CLUSTER_USER,
+	clusterUser, clusterPassword := clusterCredentials(customResource, client)
+	envVars["AMQ_CLUSTER_USER"] = clusterUser
+	envVars["AMQ_CLUSTER_PASSWORD"] = clusterPassword
+
+	previousSecretName := namer.SecretsPreviousCredentialsNameBuilder.Name()
+	previousSecret := reconciler.cloneOfDeployed(reflect.TypeOf(corev1.Secret{}), previousSecretName)
+	if previousSecret != nil {
+		// a rotation is rolling out, the next one waits for its end
+		rotate = false
+		if !isCredentialsRollCompleted(previousSecret, currentStatefulSet) {
+			reconciler.trackDesired(previousSecret)
+		}
+	}
+
+	// a broker only accepts its own cluster credentials, rotating them in a rolling restart would
+	// break the cluster bridges between the restarted brokers and the others until the roll ends
+	keepClusterCredentials := isClustered(customResource) && getBrokerPodCount(customResource) > 1
+
+	if rotate {
+		last := customResource.Status.CredentialsRotation
+		// the rotation is recorded ahead of the secret so that a failed status update does not rotate twice
+		customResource.Status.CredentialsRotation = &brokerv1beta1.CredentialsRotationStatus{
+			LastRotationTime:          metav1.Now(),
+			LastRequest:               customResource.Annotations[brokerv1beta1.RotateCredentialsAnnotation],
+			ClusterCredentialsRotated: !keepClusterCredentials && (clusterUser.AutoGen || clusterPassword.AutoGen),
+		}
+		if err := resources.UpdateStatus(client, customResource); err != nil {
+			log.Error(err, "unable to record the credentials rotation, it is retried on the next reconcile", "secret", secretName)
+			customResource.Status.CredentialsRotation = last
+			rotate = false
+		}
+	}
+
+	if rotate {
+		log.Info("Rotating the generated credentials", "secret", secretName, "clusterCredentials", !keepClusterCredentials)
+		for name, value := range envVars {
+			if keepClusterCredentials && strings.HasPrefix(name, "AMQ_CLUSTER_") {
+				continue
+			}
+			// values from the CR are not generated
+			if value.AutoGen {
+				envVars[name] = ValueInfo{Value: random.GenerateRandomString(8)}
+			}
+		}
+		if adminUser.AutoGen || adminPassword.AutoGen {
+			reconciler.trackPreviousCredentials(customResource, namer, previousSecretName, secretName, currentStatefulSet, client)
+		}
+	}
+
+	reconciler.sourceEnvVarFromSecret(customResource, namer, currentStatefulSet, &envVars, secretName, client, scheme)
+}
+
+// trackPreviousCredentials keeps the admin credentials of the brokers that are not restarted yet,
+// the operator manages them with these until the rolling restart of the rotation is over
+func (reconciler *ActiveMQArtemisReconcilerImpl) trackPreviousCredentials(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, previousSecretName string, secretName string, currentStatefulSet *appsv1.StatefulSet, client rtclient.Client) {
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: customResource.Namespace}, secret); err != nil {
+		clog.Error(err, "failed to retrieve the credentials secret", "secret", secretName)
+		return
+	}
+
+	previousSecret := secrets.NewSecret(types.NamespacedName{Name: previousSecretName, Namespace: customResource.Namespace}, previousSecretName, map[string]string{
+		"AMQ_USER":     string(secret.Data["AMQ_USER"]),
+		"AMQ_PASSWORD": string(secret.Data["AMQ_PASSWORD"]),
+	}, namer.LabelBuilder.Labels())
+	previousSecret.Annotations = map[string]string{
+		rotatedGenerationAnnotation: strconv.FormatInt(currentStatefulSet.Generation, 10),
+	}
+	reconciler.trackDesired(previousSecret)
+}
+
+// isCredentialsRollCompleted is true once the statefulset has rolled out a spec newer than the one
+// deployed at the rotation to all its pods, the current revision of the OnDelete strategy of paired
+// deployments is not updated so the updated pods are counted
+func isCredentialsRollCompleted(previousSecret rtclient.Object, currentStatefulSet *appsv1.StatefulSet) bool {
+	rotatedGeneration, err := strconv.ParseInt(previousSecret.GetAnnotations()[rotatedGenerationAnnotation], 10, 64)
+	if err != nil {
+		return true
+	}
+	status := currentStatefulSet.Status
+	return status.ObservedGeneration > rotatedGeneration && status.UpdatedReplicas == status.Replicas
+}
+
+// clusterCredentials are taken from the cluster credentials secret when referenced, else
+// they are generated for the CR and kept in its credentials secret from then on
+func clusterCredentials(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) (ValueInfo, ValueInfo) {
+	if customResource.Spec.ClusterCredentials != nil && customResource.Spec.ClusterCredentials.SecretName != "" {
+		secretName, userKey, passwordKey := GetClusterCredentialsRef(customResource)
+		secret := &corev1.Secret{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: customResource.Namespace}, secret); err != nil {
+			clog.Error(err, "failed to retrieve the cluster credentials secret", "secret", secretName)
+		} else if len(secret.Data[userKey]) > 0 && len(secret.Data[passwordKey]) > 0 {
+			return ValueInfo{Value: string(secret.Data[userKey])}, ValueInfo{Value: string(secret.Data[passwordKey])}
+		}
+	}
+
+	clusterUser := ValueInfo{
+		Value:   random.GenerateRandomString(8),
 		AutoGen: true,
 	}
-	envVars["AMQ_CLUSTER_PASSWORD"] = ValueInfo{
-		Value:   environments.GLOBAL_AMQ_CLUSTER_PASSWORD,
+	clusterPassword := ValueInfo{
+		Value:   random.GenerateRandomString(8),
 		AutoGen: true,
 	}
+	return clusterUser, clusterPassword
+}
 
-	reconciler.sourceEnvVarFromSecret(customResource, namer, currentStatefulSet, &envVars, secretName, client, scheme)
+// GetClusterCredentialsRef returns the name of the cluster credentials secret and its keys
+func GetClusterCredentialsRef(customResource *brokerv1beta1.ActiveMQArtemis) (string, string, string) {
+	ref := customResource.Spec.ClusterCredentials
+	userKey, passwordKey := ClusterCredentialsUserKey, ClusterCredentialsPasswordKey
+	if ref.UserKey != "" {
+		userKey = ref.UserKey
+	}
+	if ref.PasswordKey != "" {
+		passwordKey = ref.PasswordKey
+	}
+	return ref.SecretName, userKey, passwordKey
+}
+
+// isCredentialsRotationDue checks the rotation policy against the credentials secret, only
+// a secret the operator owns is rotated as its changes restart the brokers
+func isCredentialsRotationDue(customResource *brokerv1beta1.ActiveMQArtemis, secretName string, client rtclient.Client) bool {
+	if customResource.Spec.CredentialsRotation == nil && customResource.Annotations[brokerv1beta1.RotateCredentialsAnnotation] == "" {
+		return false
+	}
+
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: customResource.Namespace}, secret); err != nil {
+		// not generated yet
+		return false
+	}
+	owned := false
+	for _, or := range secret.OwnerReferences {
+		if or.Kind == "ActiveMQArtemis" && or.Name == customResource.Name {
+			owned = true
+		}
+	}
+	return owned && credentialsRotationDue(customResource, secret.CreationTimestamp.Time, time.Now())
+}
+
+// credentialsRotationDue is true once a new rotate credentials annotation is set or once the
+// rotation interval has elapsed since the last rotation, or the generation of the credentials.
+// A rotation waits for all the brokers to be ready so that the rolling restarts do not pile up
+func credentialsRotationDue(customResource *brokerv1beta1.ActiveMQArtemis, generatedAt time.Time, now time.Time) bool {
+	if !meta.IsStatusConditionTrue(customResource.Status.Conditions, brokerv1beta1.DeployedConditionType) {
+		return false
+	}
+
+	last := customResource.Status.CredentialsRotation
+	if request := customResource.Annotations[brokerv1beta1.RotateCredentialsAnnotation]; request != "" && (last == nil || last.LastRequest != request) {
+		return true
+	}
+
+	rotation := customResource.Spec.CredentialsRotation
+	if rotation == nil || rotation.Interval == nil || rotation.Interval.Duration <= 0 {
+		return false
+	}
+	if last != nil {
+		generatedAt = last.LastRotationTime.Time
+	}
+	return !generatedAt.IsZero() && now.Sub(generatedAt) >= rotation.Interval.Duration
 }
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessDeploymentPlan(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, client rtclient.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) {
@@ -322,9 +497,13 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessDeploymentPlan(customRes
 
 	clog.Info("Processing deployment plan", "plan", deploymentPlan, "broker cr", customResource.Name)
 	// Ensure the StatefulSet size is the same as the spec
-	replicas := getDeploymentSize(customResource)
+	replicas := getBrokerPodCount(customResource)
 	currentStatefulSet.Spec.Replicas = &replicas
 
+	if getHAPolicy(customResource) == brokerv1beta1.HAPolicySharedStore {
+		syncSharedStoreVolume(customResource, namer, client)
+	}
+
 	clog.Info("Now sync Message migration", "for cr", customResource.Name)
 	syncMessageMigration(customResource, namer, client, scheme)
 
@@ -392,8 +571,12 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessConsole(customResource *
 
 	secretName := namer.SecretsConsoleNameBuilder.Name()
 
+	consoleSSLFlags := generateConsoleSSLFlags(customResource, namer, client, secretName)
+	if customResource.Spec.Console.CertManager != nil {
+		consoleSSLFlags = generateCertManagerConsoleSSLFlags(customResource, reconciler, secretName)
+	}
 	envVars := map[string]ValueInfo{"AMQ_CONSOLE_ARGS": {
-		Value:    generateConsoleSSLFlags(customResource, namer, client, secretName),
+		Value:    consoleSSLFlags,
 		AutoGen:  true,
 		Internal: true,
 	}}
@@ -414,8 +597,6 @@ func syncMessageMigration(customResource *brokerv1beta1.ActiveMQArtemis, namer N
 	ssNames := make(map[string]string)
 	ssNames["CRNAMESPACE"] = customResource.Namespace
 	ssNames["CRNAME"] = customResource.Name
-	ssNames["CLUSTERUSER"] = environments.GLOBAL_AMQ_CLUSTER_USER
-	ssNames["CLUSTERPASS"] = environments.GLOBAL_AMQ_CLUSTER_PASSWORD
 	ssNames["HEADLESSSVCNAMEVALUE"] = namer.SvcHeadlessNameBuilder.Name()
 	ssNames["PINGSVCNAMEVALUE"] = namer.SvcPingNameBuilder.Name()
 	ssNames["SERVICE_ACCOUNT"] = os.Getenv("SERVICE_ACCOUNT")
@@ -434,8 +615,11 @@ func syncMessageMigration(customResource *brokerv1beta1.ActiveMQArtemis, namer N
 			Annotations: ssNames,
 		},
 		Spec: brokerv1beta1.ActiveMQArtemisScaledownSpec{
-			LocalOnly: isLocalOnly(),
-			Resources: customResource.Spec.DeploymentPlan.Resources,
+			LocalOnly:         isLocalOnly(),
+			Resources:         customResource.Spec.DeploymentPlan.Resources,
+			DrainPodTemplate:  customResource.Spec.DeploymentPlan.DrainPodTemplate,
+			ScaleToZeroTarget: getScaleToZeroTarget(customResource, namer),
+			DrainConcurrency:  customResource.Spec.DeploymentPlan.DrainConcurrency,
 		},
 		Status: brokerv1beta1.ActiveMQArtemisScaledownStatus{},
 	}
@@ -446,12 +630,19 @@ func syncMessageMigration(customResource *brokerv1beta1.ActiveMQArtemis, namer N
 
 	clustered := isClustered(customResource)
 
+	if isHAPaired(customResource) {
+		// a scaled down pair is drained by neither pod, the backup only holds a copy of the live journal
+		clog.Info("Won't set up scaledown for a deployment with backups", "haPolicy", getHAPolicy(customResource))
+		clustered = false
+	}
+
 	if *customResource.Spec.DeploymentPlan.MessageMigration && clustered {
 		if !customResource.Spec.DeploymentPlan.PersistenceEnabled {
 			clog.Info("Won't set up scaledown for non persistent deployment")
 			return
 		}
 		clog.Info("we need scaledown for this cr", "crName", customResource.Name, "scheme", scheme)
+		desiredSpec := scaledown.Spec
 		if err = resources.Retrieve(namespacedName, client, scaledown); err != nil {
 			// err means not found so create
 			clog.Info("Creating builtin drainer CR ", "scaledown", scaledown)
@@ -460,6 +651,12 @@ func syncMessageMigration(customResource *brokerv1beta1.ActiveMQArtemis, namer N
 			} else {
 				clog.Error(retrieveError, "we have error retrieving drainer", "drainer", scaledown, "scheme", scheme)
 			}
+		} else if !equality.Semantic.DeepEqual(scaledown.Spec, desiredSpec) {
+			// the drain pods are built from the scaledown spec, keep it in line with the deployment plan
+			scaledown.Spec = desiredSpec
+			if err = resources.Update(client, scaledown); err != nil {
+				clog.Error(err, "failed to update drainer", "drainer", scaledown)
+			}
 		}
 	} else {
 		if err = resources.Retrieve(namespacedName, client, scaledown); err == nil {
@@ -470,6 +667,37 @@ func syncMessageMigration(customResource *brokerv1beta1.ActiveMQArtemis, namer N
 	}
 }
 
+// getScaleToZeroTarget resolves the target the drain pods connect to when the broker is scaled down to zero
+func getScaleToZeroTarget(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers) *brokerv1beta1.ScaleToZeroTargetType {
+	drainTarget := customResource.Spec.DeploymentPlan.ScaleToZeroDrainTarget
+	if drainTarget == nil {
+		return nil
+	}
+
+	target := &brokerv1beta1.ScaleToZeroTargetType{
+		CredentialsSecretName: drainTarget.CredentialsSecretName,
+	}
+	if drainTarget.ActiveMQArtemis != "" {
+		targetNamer := MakeNamers(&brokerv1beta1.ActiveMQArtemis{ObjectMeta: metav1.ObjectMeta{Name: drainTarget.ActiveMQArtemis, Namespace: customResource.Namespace}})
+		target.HeadlessServiceName = targetNamer.SvcHeadlessNameBuilder.Name()
+		if target.CredentialsSecretName == "" {
+			target.CredentialsSecretName = targetNamer.SecretsCredentialsNameBuilder.Name()
+		}
+		return target
+	}
+
+	connector := getConnector(customResource, drainTarget.ConnectorName)
+	if connector == nil {
+		return nil
+	}
+	target.Host = connector.Host
+	target.Port = connector.Port
+	if target.CredentialsSecretName == "" {
+		target.CredentialsSecretName = namer.SecretsCredentialsNameBuilder.Name()
+	}
+	return target
+}
+
 func isLocalOnly() bool {
 	oprNamespace := os.Getenv("OPERATOR_NAMESPACE")
 	watchNamespace := os.Getenv("OPERATOR_WATCH_NAMESPACE")
@@ -649,7 +877,11 @@ func generateAcceptorsString(customResource *brokerv1beta1.ActiveMQArtemis, name
 			if acceptor.SSLSecret != "" {
 				secretName = acceptor.SSLSecret
 			}
-			acceptorEntry = acceptorEntry + ";" + generateAcceptorConnectorSSLArguments(customResource, namer, client, secretName)
+			if acceptor.CertManager != nil {
+				acceptorEntry = acceptorEntry + ";" + generateCertManagerSSLArguments(secretName)
+			} else {
+				acceptorEntry = acceptorEntry + ";" + generateAcceptorConnectorSSLArguments(customResource, namer, client, secretName)
+			}
 			sslOptionalArguments := generateAcceptorSSLOptionalArguments(acceptor)
 			if sslOptionalArguments != "" {
 				acceptorEntry = acceptorEntry + ";" + sslOptionalArguments
@@ -713,7 +945,11 @@ func generateConnectorsString(customResource *brokerv1beta1.ActiveMQArtemis, nam
 			if connector.SSLSecret != "" {
 				secretName = connector.SSLSecret
 			}
-			connectorEntry = connectorEntry + ";" + generateAcceptorConnectorSSLArguments(customResource, namer, client, secretName)
+			if connector.CertManager != nil {
+				connectorEntry = connectorEntry + ";" + generateCertManagerSSLArguments(secretName)
+			} else {
+				connectorEntry = connectorEntry + ";" + generateAcceptorConnectorSSLArguments(customResource, namer, client, secretName)
+			}
 			sslOptionalArguments := generateConnectorSSLOptionalArguments(connector)
 			if sslOptionalArguments != "" {
 				connectorEntry = connectorEntry + ";" + sslOptionalArguments
@@ -731,7 +967,30 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) configureAcceptorsExposure(cust
 		Name:      customResource.Name,
 		Namespace: customResource.Namespace,
 	}
-	deploymentSize := getDeploymentSize(customResource)
+	for _, acceptor := range customResource.Spec.Acceptors {
+		if !hasLoadBalancedService(acceptor) {
+			continue
+		}
+		serviceDefinition := svc.NewServiceDefinitionForCR("", client, namespacedName, acceptor.Name, acceptor.Port, originalLabels, namer.LabelBuilder.Labels())
+		// the connections only go to the brokers that are ready
+		serviceDefinition.Spec.PublishNotReadyAddresses = false
+
+		reconciler.checkExistingService(customResource, serviceDefinition, client)
+		reconciler.trackDesired(serviceDefinition)
+
+		if acceptor.Expose {
+			reconciler.exposePort(customResource, client, originalLabels, serviceDefinition, exposedPort{
+				targetPortName: acceptor.Name,
+				port:           acceptor.Port,
+				sslEnabled:     acceptor.SSLEnabled,
+				sslSecretName:  acceptorSSLSecretName(customResource, acceptor),
+				exposeMode:     acceptor.ExposeMode,
+				exposure:       acceptor.Exposure,
+			})
+		}
+	}
+
+	deploymentSize := getBrokerPodCount(customResource)
 	for i := int32(0); i < deploymentSize; i++ {
 		ordinalString := strconv.Itoa(int(i))
 		var serviceRoutelabels = make(map[string]string)
@@ -741,26 +1000,32 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) configureAcceptorsExposure(cust
 		serviceRoutelabels["statefulset.kubernetes.io/pod-name"] = namer.SsNameBuilder.Name() + "-" + ordinalString
 
 		for _, acceptor := range customResource.Spec.Acceptors {
+			if !hasPerPodServices(acceptor) {
+				continue
+			}
 			serviceDefinition := svc.NewServiceDefinitionForCR("", client, namespacedName, acceptor.Name+"-"+ordinalString, acceptor.Port, serviceRoutelabels, namer.LabelBuilder.Labels())
 
 			reconciler.checkExistingService(customResource, serviceDefinition, client)
 			reconciler.trackDesired(serviceDefinition)
 
 			if acceptor.Expose {
-
-				targetPortName := acceptor.Name + "-" + ordinalString
-				targetServiceName := customResource.Name + "-" + targetPortName + "-svc"
-
-				exposureDefinition := reconciler.ExposureDefinitionForCR(namespacedName, serviceRoutelabels, targetServiceName, targetPortName, acceptor.SSLEnabled, customResource.Spec.IngressDomain)
-				reconciler.trackDesired(exposureDefinition)
+				reconciler.exposePort(customResource, client, serviceRoutelabels, serviceDefinition, exposedPort{
+					targetPortName: acceptor.Name + "-" + ordinalString,
+					port:           acceptor.Port,
+					sslEnabled:     acceptor.SSLEnabled,
+					sslSecretName:  acceptorSSLSecretName(customResource, acceptor),
+					ordinal:        ordinalString,
+					exposeMode:     acceptor.ExposeMode,
+					exposure:       acceptor.Exposure,
+				})
 			}
 		}
 	}
 }
 
-func (reconciler *ActiveMQArtemisReconcilerImpl) ExposureDefinitionForCR(namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, targetPortName string, passthroughTLS bool, domain string) rtclient.Object {
+func (reconciler *ActiveMQArtemisReconcilerImpl) ExposureDefinitionForCR(namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, targetPortName string, tlsTermination string, domain string, exposeMode string, exposure *brokerv1beta1.ExposureType) rtclient.Object {
 
-	if isOpenshift, err := environments.DetectOpenshift(); isOpenshift && err == nil {
+	if exposeMode == brokerv1beta1.ExposeModeRoute {
 		clog.Info("creating route for "+targetPortName, "service", targetServiceName)
 
 		var existing *routev1.Route = nil
@@ -768,7 +1033,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ExposureDefinitionForCR(namespa
 		if obj != nil {
 			existing = obj.(*routev1.Route)
 		}
-		return routes.NewRouteDefinitionForCR(existing, namespacedName, labels, targetServiceName, targetPortName, passthroughTLS, domain)
+		return routes.NewRouteDefinitionForCR(existing, namespacedName, labels, targetServiceName, targetPortName, tlsTermination, domain, exposure)
 	} else {
 		clog.Info("creating ingress for "+targetPortName, "service", targetServiceName)
 
@@ -777,7 +1042,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ExposureDefinitionForCR(namespa
 		if obj != nil {
 			existing = obj.(*netv1.Ingress)
 		}
-		return ingresses.NewIngressForCRWithSSL(existing, namespacedName, labels, targetServiceName, targetPortName, passthroughTLS, domain)
+		return ingresses.NewIngressForCRWithSSL(existing, namespacedName, labels, targetServiceName, targetPortName, tlsTermination, domain, exposure)
 	}
 }
 
@@ -791,7 +1056,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) configureConnectorsExposure(cus
 		Name:      customResource.Name,
 		Namespace: customResource.Namespace,
 	}
-	deploymentSize := getDeploymentSize(customResource)
+	deploymentSize := getBrokerPodCount(customResource)
 	for i := int32(0); i < deploymentSize; i++ {
 		ordinalString := strconv.Itoa(int(i))
 		var serviceRoutelabels = make(map[string]string)
@@ -806,12 +1071,19 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) configureConnectorsExposure(cus
 			reconciler.trackDesired(serviceDefinition)
 
 			if connector.Expose {
-
-				targetPortName := connector.Name + "-" + ordinalString
-				targetServiceName := customResource.Name + "-" + targetPortName + "-svc"
-				exposureDefinition := reconciler.ExposureDefinitionForCR(namespacedName, serviceRoutelabels, targetServiceName, targetPortName, connector.SSLEnabled, customResource.Spec.IngressDomain)
-
-				reconciler.trackDesired(exposureDefinition)
+				sslSecretName := customResource.Name + "-" + connector.Name + "-secret"
+				if connector.SSLSecret != "" {
+					sslSecretName = connector.SSLSecret
+				}
+				reconciler.exposePort(customResource, client, serviceRoutelabels, serviceDefinition, exposedPort{
+					targetPortName: connector.Name + "-" + ordinalString,
+					port:           connector.Port,
+					sslEnabled:     connector.SSLEnabled,
+					sslSecretName:  sslSecretName,
+					ordinal:        ordinalString,
+					exposeMode:     connector.ExposeMode,
+					exposure:       connector.Exposure,
+				})
 			}
 		}
 	}
@@ -828,7 +1100,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) configureConsoleExposure(custom
 	commonPortName := "wconsj"
 	targetPort := int32(8161)
 	portNumber := int32(8162)
-	deploymentSize := getDeploymentSize(customResource)
+	deploymentSize := getBrokerPodCount(customResource)
 	for i := int32(0); i < deploymentSize; i++ {
 		ordinalString := strconv.Itoa(int(i))
 		var serviceRoutelabels = make(map[string]string)
@@ -852,31 +1124,19 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) configureConsoleExposure(custom
 			reconciler.checkExistingService(customResource, serviceDefinition, client)
 			reconciler.trackDesired(serviceDefinition)
 
-			var err error = nil
-			isOpenshift := false
-			if isOpenshift, err = environments.DetectOpenshift(); err != nil {
-				clog.Error(err, "Failed to get env, will try kubernetes")
-			}
-			if isOpenshift {
-				clog.Info("routeDefinition for " + targetPortName)
-				var existing *routev1.Route = nil
-				obj := reconciler.cloneOfDeployed(reflect.TypeOf(routev1.Route{}), targetServiceName+"-rte")
-				if obj != nil {
-					existing = obj.(*routev1.Route)
-				}
-				routeDefinition := routes.NewRouteDefinitionForCR(existing, namespacedName, serviceRoutelabels, targetServiceName, targetPortName, console.SSLEnabled, customResource.Spec.IngressDomain)
-				reconciler.trackDesired(routeDefinition)
-
-			} else {
-				clog.Info("ingress for " + targetPortName)
-				var existing *netv1.Ingress = nil
-				obj := reconciler.cloneOfDeployed(reflect.TypeOf(netv1.Ingress{}), targetServiceName+"-ing")
-				if obj != nil {
-					existing = obj.(*netv1.Ingress)
-				}
-				ingressDefinition := ingresses.NewIngressForCRWithSSL(existing, namespacedName, serviceRoutelabels, targetServiceName, targetPortName, console.SSLEnabled, customResource.Spec.IngressDomain)
-				reconciler.trackDesired(ingressDefinition)
+			sslSecretName := namer.SecretsConsoleNameBuilder.Name()
+			if console.SSLSecret != "" {
+				sslSecretName = console.SSLSecret
 			}
+			reconciler.exposePort(customResource, client, serviceRoutelabels, serviceDefinition, exposedPort{
+				targetPortName: targetPortName,
+				port:           portNumber,
+				sslEnabled:     console.SSLEnabled,
+				sslSecretName:  sslSecretName,
+				ordinal:        ordinalString,
+				exposeMode:     console.ExposeMode,
+				exposure:       console.Exposure,
+			})
 		}
 	}
 }
@@ -1109,7 +1369,10 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessResources(customResource
 	})
 
 	comparator.Comparator.SetComparator(reflect.TypeOf(netv1.Ingress{}), func(deployed, requested rtclient.Object) bool {
-		return equality.Semantic.DeepEqual(deployed.(*netv1.Ingress).Spec, requested.(*netv1.Ingress).Spec)
+		ing1 := deployed.(*netv1.Ingress)
+		ing2 := requested.(*netv1.Ingress)
+		// the annotations of the exposure configure the ingress controller
+		return equality.Semantic.DeepEqual(ing1.Annotations, ing2.Annotations) && equality.Semantic.DeepEqual(ing1.Spec, ing2.Spec)
 	})
 
 	deltas := comparator.Compare(reconciler.deployed, requested)
@@ -1240,7 +1503,7 @@ func checkExistingPersistentVolumes(instance *brokerv1beta1.ActiveMQArtemis, cli
 	var log = ctrl.Log.WithName("controller_v1beta1activemqartemis")
 
 	var i int32
-	for i = 0; i < getDeploymentSize(instance); i++ {
+	for i = 0; i < getBrokerPodCount(instance); i++ {
 		ordinalString := strconv.Itoa(int(i))
 		pvcKey := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name + "-" + namer.CrToSS(instance.Name) + "-" + ordinalString}
 		pvc := &corev1.PersistentVolumeClaim{}
@@ -1314,6 +1577,7 @@ func getDeployedResources(instance *brokerv1beta1.ActiveMQArtemis, client rtclie
 			&corev1.ServiceList{},
 			&appsv1.StatefulSetList{},
 			&routev1.RouteList{},
+			&netv1.IngressList{},
 			&corev1.SecretList{},
 			&corev1.ConfigMapList{},
 		)
@@ -1350,6 +1614,17 @@ func MakeVolumes(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers) []
 		volumeDefinitions = append(volumeDefinitions, basicCRVolume...)
 	}
 
+	if getHAPolicy(customResource) == brokerv1beta1.HAPolicySharedStore {
+		volumeDefinitions = append(volumeDefinitions, corev1.Volume{
+			Name: getSharedStoreVolumeName(customResource),
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: getSharedStoreVolumeName(customResource),
+				},
+			},
+		})
+	}
+
 	secretVolumes := make(map[string]string)
 	// Scan acceptors for any with sslEnabled
 	for _, acceptor := range customResource.Spec.Acceptors {
@@ -1384,6 +1659,11 @@ func MakeVolumes(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers) []
 		addNewVolumes(secretVolumes, &volumeDefinitions, &secretName)
 	}
 
+	for _, secretName := range certificateKeyStoreSecretNames(customResource, namer) {
+		secretName := secretName
+		addNewVolumes(secretVolumes, &volumeDefinitions, &secretName)
+	}
+
 	return volumeDefinitions
 }
 
@@ -1403,6 +1683,13 @@ func MakeVolumeMounts(customResource *brokerv1beta1.ActiveMQArtemis, namer Namer
 		volumeMounts = append(volumeMounts, persistentCRVlMnt...)
 	}
 
+	if getHAPolicy(customResource) == brokerv1beta1.HAPolicySharedStore {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      getSharedStoreVolumeName(customResource),
+			MountPath: getSharedStorePath(customResource),
+		})
+	}
+
 	// Scan acceptors for any with sslEnabled
 	secretVolumeMounts := make(map[string]string)
 	for _, acceptor := range customResource.Spec.Acceptors {
@@ -1437,6 +1724,11 @@ func MakeVolumeMounts(customResource *brokerv1beta1.ActiveMQArtemis, namer Namer
 		addNewVolumeMounts(secretVolumeMounts, &volumeMounts, &volumeMountName)
 	}
 
+	for _, secretName := range certificateKeyStoreSecretNames(customResource, namer) {
+		volumeMountName := secretName + "-volume"
+		addNewVolumeMounts(secretVolumeMounts, &volumeMounts, &volumeMountName)
+	}
+
 	return volumeMounts
 }
 
@@ -1552,6 +1844,8 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) NewPodTemplateSpecForCR(customR
 
 	configureAffinity(podSpec, &customResource.Spec.DeploymentPlan.Affinity)
 
+	podSpec.TopologySpreadConstraints = topologySpreadConstraintsForCR(customResource, namer)
+
 	if len(customResource.Spec.DeploymentPlan.Tolerations) > 0 {
 		reqLogger.V(1).Info("Adding Tolerations", "len", len(customResource.Spec.DeploymentPlan.Tolerations))
 		podSpec.Tolerations = customResource.Spec.DeploymentPlan.Tolerations
@@ -1985,7 +2279,8 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) addResourceForBrokerProperties(
 	// fetch and do idempotent transform based on CR
 
 	// deal with upgrade to immutable secret, only upgrade to mutable on not found
-	alder32Bytes := alder32Of(customResource.Spec.BrokerProperties)
+	brokerProperties := brokerPropertiesForCR(customResource)
+	alder32Bytes := alder32Of(brokerProperties)
 	shaOfMap := hex.EncodeToString(alder32Bytes)
 	resourceName := types.NamespacedName{
 		Namespace: customResource.Namespace,
@@ -2010,7 +2305,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) addResourceForBrokerProperties(
 		desired = obj.(*corev1.Secret)
 	}
 
-	data := brokerPropertiesData(customResource.Spec.BrokerProperties)
+	data := brokerPropertiesData(brokerProperties)
 	if desired == nil {
 		secret := secrets.MakeSecret(resourceName, resourceName.Name, data, namer.LabelBuilder.Labels())
 		desired = &secret
@@ -2039,6 +2334,281 @@ func alder32Of(props []string) []byte {
 	return digest.Sum(nil)
 }
 
+// brokerPropertiesForCR renders the typed configuration of the CR as broker properties,
+// the user provided BrokerProperties follow so they can override any of them
+func brokerPropertiesForCR(customResource *brokerv1beta1.ActiveMQArtemis) []string {
+	if len(customResource.Spec.Diverts) == 0 && !isHAPaired(customResource) {
+		return customResource.Spec.BrokerProperties
+	}
+
+	props := divertsBrokerProperties(customResource.Spec.Diverts)
+	props = append(props, haPolicyBrokerProperties(customResource)...)
+	return append(props, customResource.Spec.BrokerProperties...)
+}
+
+func divertsBrokerProperties(diverts []brokerv1beta1.DivertType) []string {
+	props := []string{}
+	for _, divert := range diverts {
+		prefix := "divertConfigurations." + divert.Name + "."
+		props = append(props, prefix+"address="+divert.Address)
+		props = append(props, prefix+"forwardingAddress="+divert.ForwardingAddress)
+		if divert.Exclusive != nil {
+			props = append(props, prefix+"exclusive="+strconv.FormatBool(*divert.Exclusive))
+		}
+		if divert.Filter != "" {
+			props = append(props, prefix+"filterString="+divert.Filter)
+		}
+		if divert.RoutingName != "" {
+			props = append(props, prefix+"routingName="+divert.RoutingName)
+		}
+		if divert.RoutingType != "" {
+			props = append(props, prefix+"routingType="+strings.ToUpper(divert.RoutingType))
+		}
+		if divert.TransformerClassName != "" {
+			props = append(props, prefix+"transformerConfiguration.className="+divert.TransformerClassName)
+			for _, key := range sortedKeys(divert.TransformerProperties) {
+				props = append(props, prefix+"transformerConfiguration.properties."+key+"="+divert.TransformerProperties[key])
+			}
+		}
+	}
+	return props
+}
+
+func getHAPolicy(cr *brokerv1beta1.ActiveMQArtemis) string {
+	if cr.Spec.DeploymentPlan.HAPolicy == nil || cr.Spec.DeploymentPlan.HAPolicy.Type == "" {
+		return brokerv1beta1.HAPolicyNone
+	}
+	return cr.Spec.DeploymentPlan.HAPolicy.Type
+}
+
+// isHAPaired is true when each live broker is deployed with a backup, the live broker takes the even
+// ordinal of a pair and its backup the following odd ordinal of the same StatefulSet
+func isHAPaired(cr *brokerv1beta1.ActiveMQArtemis) bool {
+	policy := getHAPolicy(cr)
+	return policy == brokerv1beta1.HAPolicyReplication || policy == brokerv1beta1.HAPolicySharedStore
+}
+
+func getHARole(ordinal int) string {
+	if ordinal%2 == 0 {
+		return brokerv1beta1.BrokerRoleLive
+	}
+	return brokerv1beta1.BrokerRoleBackup
+}
+
+func getSharedStoreVolumeName(cr *brokerv1beta1.ActiveMQArtemis) string {
+	return cr.Name + "-shared-store"
+}
+
+func getSharedStorePath(cr *brokerv1beta1.ActiveMQArtemis) string {
+	return "/opt/" + cr.Name + "/shared-store"
+}
+
+// haPolicyBrokerProperties configures the ha-policy of each pod through ordinal properties, the pods of
+// a pair share a group name so that a backup only replicates or fails over for its own live broker
+func haPolicyBrokerProperties(cr *brokerv1beta1.ActiveMQArtemis) []string {
+	if !isHAPaired(cr) {
+		return nil
+	}
+
+	haPolicy := cr.Spec.DeploymentPlan.HAPolicy
+	allowFailBack := true
+	if haPolicy.AllowFailBack != nil {
+		allowFailBack = *haPolicy.AllowFailBack
+	}
+	checkForLiveServer := true
+	if haPolicy.CheckForLiveServer != nil {
+		checkForLiveServer = *haPolicy.CheckForLiveServer
+	}
+
+	props := []string{}
+	for i := 0; i < int(getBrokerPodCount(cr)); i++ {
+		pair := strconv.Itoa(i / 2)
+		prefix := OrdinalPrefix + strconv.Itoa(i) + OrdinalPrefixSep
+		role := getHARole(i)
+
+		switch haPolicy.Type {
+		case brokerv1beta1.HAPolicyReplication:
+			groupName := cr.Name + "-" + pair
+			if role == brokerv1beta1.BrokerRoleLive {
+				props = append(props, prefix+"HAPolicyConfiguration=REPLICATED")
+				props = append(props, prefix+"HAPolicyConfiguration.checkForLiveServer="+strconv.FormatBool(checkForLiveServer))
+			} else {
+				props = append(props, prefix+"HAPolicyConfiguration=REPLICA")
+				props = append(props, prefix+"HAPolicyConfiguration.allowFailBack="+strconv.FormatBool(allowFailBack))
+			}
+			props = append(props, prefix+"HAPolicyConfiguration.groupName="+groupName)
+
+		case brokerv1beta1.HAPolicySharedStore:
+			if role == brokerv1beta1.BrokerRoleLive {
+				props = append(props, prefix+"HAPolicyConfiguration=SHARED_STORE_MASTER")
+			} else {
+				props = append(props, prefix+"HAPolicyConfiguration=SHARED_STORE_SLAVE")
+				props = append(props, prefix+"HAPolicyConfiguration.allowFailBack="+strconv.FormatBool(allowFailBack))
+			}
+			// both pods of a pair use the same journal, its lock decides which one is live
+			pairDir := getSharedStorePath(cr) + "/pair-" + pair
+			props = append(props, prefix+"journalDirectory="+pairDir+"/journal")
+			props = append(props, prefix+"bindingsDirectory="+pairDir+"/bindings")
+			props = append(props, prefix+"pagingDirectory="+pairDir+"/paging")
+			props = append(props, prefix+"largeMessagesDirectory="+pairDir+"/large-messages")
+		}
+	}
+	return props
+}
+
+// syncSharedStoreVolume creates the volume shared by the pairs, like the broker volumes it is not
+// owned by the CR so the journals survive its deletion
+func syncSharedStoreVolume(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, client rtclient.Client) {
+	namespacedName := types.NamespacedName{
+		Name:      getSharedStoreVolumeName(customResource),
+		Namespace: customResource.Namespace,
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := client.Get(context.TODO(), namespacedName, pvc)
+	if err == nil || !k8serrors.IsNotFound(err) {
+		return
+	}
+
+	capacity := "2Gi"
+	sharedStorage := customResource.Spec.DeploymentPlan.HAPolicy.SharedStorage
+	if sharedStorage.Size != "" {
+		capacity = sharedStorage.Size
+	}
+	pvc = persistentvolumeclaims.NewPersistentVolumeClaimWithCapacityAndStorageClassName(namespacedName, capacity, namer.LabelBuilder.Labels(), sharedStorage.StorageClassName)
+	pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+
+	clog.Info("Creating shared store volume", "name", namespacedName)
+	if err = client.Create(context.TODO(), pvc); err != nil {
+		clog.Error(err, "failed to create shared store volume", "name", namespacedName)
+	}
+}
+
+// RollPairedPods replaces the pods of a paired deployment that are not at the update revision of its
+// StatefulSet, which uses the OnDelete strategy because a rolling update waits for each updated pod to be
+// ready and a backup is never ready while its live broker is
+func RollPairedPods(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) ctrl.Result {
+	if !isHAPaired(cr) {
+		return ctrl.Result{}
+	}
+	reqLogger := ctrl.Log.WithValues("ActiveMQArtemis Name", cr.Name)
+
+	statefulSet := &appsv1.StatefulSet{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}, statefulSet); err != nil {
+		return ctrl.Result{}
+	}
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType || statefulSet.Status.UpdateRevision == "" || statefulSet.Spec.Selector == nil {
+		return ctrl.Result{}
+	}
+
+	pods := &corev1.PodList{}
+	if err := client.List(context.TODO(), pods, rtclient.InNamespace(cr.Namespace), rtclient.MatchingLabels(statefulSet.Spec.Selector.MatchLabels)); err != nil {
+		reqLogger.Error(err, "unable to list the pods of the paired deployment")
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}
+	}
+
+	polledAt := lastBrokersStatusPollMap[types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}]
+	pod, rolling := nextPairedPodToRoll(statefulSet, pods.Items, cr.Status.Brokers, polledAt)
+	if pod != nil {
+		reqLogger.Info("Replacing pod of the paired deployment", "pod", pod.Name, "revision", statefulSet.Status.UpdateRevision)
+		if err := client.Delete(context.TODO(), pod); err != nil && !k8serrors.IsNotFound(err) {
+			reqLogger.Error(err, "unable to delete pod of the paired deployment", "pod", pod.Name)
+		}
+	}
+	if rolling {
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}
+	}
+	return ctrl.Result{}
+}
+
+// nextPairedPodToRoll returns the pod to replace with the update revision, one pod at a time once all the
+// pods run. The backups are replaced first, a live broker is replaced once its updated backup is announced
+// in the cluster topology of a broker status polled after the backup started, so that the backup takes
+// over. The bool is true until all the pods are at the update revision
+func nextPairedPodToRoll(statefulSet *appsv1.StatefulSet, pods []corev1.Pod, brokers []brokerv1beta1.BrokerStatus, polledAt time.Time) (*corev1.Pod, bool) {
+	replicas := 0
+	if statefulSet.Spec.Replicas != nil {
+		replicas = int(*statefulSet.Spec.Replicas)
+	}
+	byOrdinal := map[int]*corev1.Pod{}
+	for i := range pods {
+		ordinal, err := strconv.Atoi(strings.TrimPrefix(pods[i].Name, statefulSet.Name+"-"))
+		if err == nil && ordinal < replicas {
+			byOrdinal[ordinal] = &pods[i]
+		}
+	}
+	brokersByOrdinal := map[int]*brokerv1beta1.BrokerStatus{}
+	for i := range brokers {
+		if ordinal, err := strconv.Atoi(brokers[i].Ordinal); err == nil {
+			brokersByOrdinal[ordinal] = &brokers[i]
+		}
+	}
+	isUpdated := func(ordinal int) bool {
+		return byOrdinal[ordinal].Labels[appsv1.StatefulSetRevisionLabel] == statefulSet.Status.UpdateRevision
+	}
+	role := func(ordinal int) string {
+		if broker := brokersByOrdinal[ordinal]; broker != nil && broker.Role != "" {
+			return broker.Role
+		}
+		return getHARole(ordinal)
+	}
+
+	updated := true
+	for ordinal := 0; ordinal < replicas; ordinal++ {
+		pod := byOrdinal[ordinal]
+		if pod == nil || pod.DeletionTimestamp != nil || podStartedAt(pod).IsZero() {
+			// a pod is being replaced
+			return nil, true
+		}
+		updated = updated && isUpdated(ordinal)
+	}
+	if updated {
+		return nil, false
+	}
+
+	for ordinal := replicas - 1; ordinal >= 0; ordinal-- {
+		if role(ordinal) == brokerv1beta1.BrokerRoleBackup && !isUpdated(ordinal) {
+			return byOrdinal[ordinal], true
+		}
+	}
+	for ordinal := replicas - 1; ordinal >= 0; ordinal-- {
+		backup := ordinal ^ 1
+		if isUpdated(ordinal) || backup >= replicas || !isUpdated(backup) || !podStartedAt(byOrdinal[backup]).Before(polledAt) {
+			continue
+		}
+		if live := brokersByOrdinal[ordinal]; live != nil && live.Error == "" && hasTopologyBackup(live) {
+			return byOrdinal[ordinal], true
+		}
+	}
+	return nil, true
+}
+
+func hasTopologyBackup(broker *brokerv1beta1.BrokerStatus) bool {
+	for _, member := range broker.ClusterTopology {
+		if member.NodeID == broker.NodeID && member.Backup != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// podStartedAt is the time the last container of a running pod started, zero when one is not running
+func podStartedAt(pod *corev1.Pod) time.Time {
+	if pod.Status.Phase != corev1.PodRunning || len(pod.Status.ContainerStatuses) == 0 {
+		return time.Time{}
+	}
+	var startedAt time.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running == nil {
+			return time.Time{}
+		}
+		if status.State.Running.StartedAt.After(startedAt) {
+			startedAt = status.State.Running.StartedAt.Time
+		}
+	}
+	return startedAt
+}
+
 func brokerPropertiesData(props []string) map[string]string {
 	buf := &bytes.Buffer{}
 	fmt.Fprintln(buf, "# generated by crd")
@@ -2095,6 +2665,34 @@ func configureAffinity(podSpec *corev1.PodSpec, affinity *brokerv1beta1.Affinity
 	}
 }
 
+// topologySpreadConstraintsForCR returns the configured constraints, or when zone aware a constraint
+// that spreads the pods of a clustered deployment evenly across zones so that each broker finds
+// its cluster peers in the other zones
+func topologySpreadConstraintsForCR(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers) []corev1.TopologySpreadConstraint {
+	if len(customResource.Spec.DeploymentPlan.TopologySpreadConstraints) > 0 {
+		return customResource.Spec.DeploymentPlan.TopologySpreadConstraints
+	}
+	if !isZoneAware(customResource) {
+		return nil
+	}
+	return []corev1.TopologySpreadConstraint{{
+		MaxSkew:     1,
+		TopologyKey: corev1.LabelTopologyZone,
+		// nodes without zones or with too few zones must not leave brokers unscheduled
+		WhenUnsatisfiable: corev1.ScheduleAnyway,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: namer.LabelBuilder.Labels(),
+		},
+	}}
+}
+
+func isZoneAware(customResource *brokerv1beta1.ActiveMQArtemis) bool {
+	if customResource.Spec.DeploymentPlan.ZoneAware != nil && !*customResource.Spec.DeploymentPlan.ZoneAware {
+		return false
+	}
+	return isClustered(customResource)
+}
+
 func configurePodSecurityContext(podSpec *corev1.PodSpec, podSecurityContext *corev1.PodSecurityContext) {
 	clog.V(1).Info("Configuring PodSecurityContext")
 
@@ -2263,9 +2861,20 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) NewStatefulSetForCR(customResou
 		Name:      customResource.Name,
 		Namespace: customResource.Namespace,
 	}
-	replicas := getDeploymentSize(customResource)
+	replicas := getBrokerPodCount(customResource)
 	currentStateFullSet = ss.MakeStatefulSet(currentStateFullSet, namer.SsNameBuilder.Name(), namer.SvcHeadlessNameBuilder.Name(), namespacedName, customResource.Annotations, namer.LabelBuilder.Labels(), &replicas)
 
+	if isHAPaired(customResource) {
+		if currentStateFullSet.CreationTimestamp.IsZero() {
+			// backups never become ready while their live broker is active, they must not block the next pair
+			currentStateFullSet.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+		}
+		// nor the update of the next pod, RollPairedPods replaces the pods instead
+		currentStateFullSet.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	} else if currentStateFullSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		currentStateFullSet.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}
+	}
+
 	podTemplateSpec, err := reconciler.NewPodTemplateSpecForCR(customResource, namer, &currentStateFullSet.Spec.Template, client)
 	if err != nil {
 		reqLogger.Error(err, "Error creating new pod template")
@@ -2430,12 +3039,25 @@ func getDeploymentCondition(cr *brokerv1beta1.ActiveMQArtemis, podStatus olm.Dep
 			Message: common.DeployedConditionZeroSizeMessage,
 		}
 	}
-	if len(podStatus.Ready) != int(deploymentSize) {
+	readyCount := int32(len(podStatus.Ready))
+	if isHAPaired(cr) {
+		// each pair serves with a single live broker, a backup is only ready once it takes over
+		// from its live broker, when both pods of a pair may briefly be ready
+		readyPairs := countReadyPairs(podStatus.Ready)
+		if readyPairs != deploymentSize {
+			return metav1.Condition{
+				Type:    brokerv1beta1.DeployedConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  brokerv1beta1.DeployedConditionNotReadyReason,
+				Message: fmt.Sprintf("%d/%d live brokers ready", readyPairs, deploymentSize),
+			}
+		}
+	} else if readyCount != deploymentSize {
 		return metav1.Condition{
 			Type:    brokerv1beta1.DeployedConditionType,
 			Status:  metav1.ConditionFalse,
 			Reason:  brokerv1beta1.DeployedConditionNotReadyReason,
-			Message: fmt.Sprintf("%d/%d pods ready", len(podStatus.Ready), deploymentSize),
+			Message: fmt.Sprintf("%d/%d pods ready", readyCount, deploymentSize),
 		}
 	}
 	return metav1.Condition{
@@ -2445,6 +3067,17 @@ func getDeploymentCondition(cr *brokerv1beta1.ActiveMQArtemis, podStatus olm.Dep
 	}
 }
 
+// countReadyPairs is the number of pairs with a ready pod, the pods of pair i have the ordinals 2i and 2i+1
+func countReadyPairs(readyPods []string) int32 {
+	pairs := map[int]bool{}
+	for _, podName := range readyPods {
+		if ordinal, err := strconv.Atoi(podName[strings.LastIndex(podName, "-")+1:]); err == nil {
+			pairs[ordinal/2] = true
+		}
+	}
+	return int32(len(pairs))
+}
+
 func updatePodStatus(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, namespacedName types.NamespacedName) olm.DeploymentStatus {
 
 	reqLogger := ctrl.Log.WithValues("ActiveMQArtemis Name", namespacedName.Name)
@@ -2488,7 +3121,8 @@ func getSingleStatefulSetStatus(ss *appsv1.StatefulSet, cr *brokerv1beta1.Active
 	if ss.Spec.Replicas != nil {
 		requestedCount = *ss.Spec.Replicas
 	}
-	cr.Status.DeploymentPlanSize = requestedCount
+	// the scale subresource reports the number of live brokers, as requested by the deployment plan size
+	cr.Status.DeploymentPlanSize = requestedCount / getPodsPerBroker(cr)
 
 	targetCount := ss.Status.Replicas
 	readyCount := ss.Status.ReadyReplicas
@@ -2545,6 +3179,9 @@ func MakeEnvVarArrayForCR(customResource *brokerv1beta1.ActiveMQArtemis, namer N
 	metricsPluginEnabled := "false"
 	if customResource.Spec.DeploymentPlan.EnableMetricsPlugin != nil {
 		metricsPluginEnabled = strconv.FormatBool(*customResource.Spec.DeploymentPlan.EnableMetricsPlugin)
+	} else if isMonitoringEnabled(customResource) {
+		// the service monitor scrapes the metrics of the plugin
+		metricsPluginEnabled = "true"
 	}
 
 	envVar := []corev1.EnvVar{}
@@ -2650,9 +3287,142 @@ func UpdateBrokerPropertiesStatus(cr *brokerv1beta1.ActiveMQArtemis, client rtcl
 	return result
 }
 
+// UpdateBrokersStatus refreshes the per broker status from the broker management api.
+// The brokers are polled at most once per resync period so that changing runtime
+// values, like the uptime or message counts, do not trigger a status update storm
+func UpdateBrokersStatus(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, scheme *runtime.Scheme) ctrl.Result {
+	reqLogger := ctrl.Log.WithValues("ActiveMQArtemis Name", cr.Name)
+
+	resource := types.NamespacedName{
+		Name:      cr.Name,
+		Namespace: cr.Namespace,
+	}
+
+	if err := AssertBrokersAvailable(cr, client, scheme); err != nil {
+		cr.Status.Brokers = nil
+		cr.Status.Diverts = nil
+		delete(lastBrokersStatusPollMap, resource)
+		return ctrl.Result{}
+	}
+
+	resyncPeriod := common.GetReconcileResyncPeriod()
+	if lastPoll, found := lastBrokersStatusPollMap[resource]; found {
+		if elapsed := time.Since(lastPoll); elapsed < resyncPeriod {
+			reqLogger.V(2).Info("brokers status recently polled, skipping", "elapsed", elapsed)
+			return ctrl.Result{RequeueAfter: resyncPeriod - elapsed}
+		}
+	}
+
+	ssInfos := ss.GetDeployedStatefulSetNames(client, []types.NamespacedName{resource})
+	jks := jolokia_client.GetBrokers(resource, ssInfos, client)
+
+	brokers := []brokerv1beta1.BrokerStatus{}
+	divertOrdinals := map[string][]string{}
+	nodeZones := map[string]string{}
+	for _, jk := range jks {
+		brokerStatus := brokerv1beta1.BrokerStatus{
+			Ordinal: jk.Ordinal,
+			PodName: namer.CrToSS(cr.Name) + "-" + jk.Ordinal,
+		}
+		brokerStatus.Zone = getPodZone(types.NamespacedName{Namespace: cr.Namespace, Name: brokerStatus.PodName}, client, nodeZones)
+
+		info, err := jk.Artemis.GetBrokerInfo(context.TODO())
+		if err != nil {
+			reqLogger.Info("unable to retrieve broker info from Jolokia", "IP", jk.IP, "Ordinal", jk.Ordinal, "error", err)
+			brokerStatus.Error = err.Error()
+			if isHAPaired(cr) {
+				// report the configured role until the broker answers
+				ordinal, _ := strconv.Atoi(jk.Ordinal)
+				brokerStatus.Role = getHARole(ordinal)
+			}
+		} else {
+			setBrokerStatusFromInfo(&brokerStatus, info)
+			for _, divertName := range info.DivertNames {
+				divertOrdinals[divertName] = append(divertOrdinals[divertName], jk.Ordinal)
+			}
+		}
+		brokers = append(brokers, brokerStatus)
+	}
+
+	sort.Slice(brokers, func(i, j int) bool {
+		first, _ := strconv.Atoi(brokers[i].Ordinal)
+		second, _ := strconv.Atoi(brokers[j].Ordinal)
+		return first < second
+	})
+
+	if len(brokers) > 0 {
+		cr.Status.Brokers = brokers
+	} else {
+		cr.Status.Brokers = nil
+	}
+	cr.Status.Diverts = divertsStatus(cr.Spec.Diverts, divertOrdinals, len(jks))
+	lastBrokersStatusPollMap[resource] = time.Now()
+
+	return ctrl.Result{RequeueAfter: resyncPeriod}
+}
+
+// getPodZone returns the zone label of the node of the pod, nodes are cluster scoped so reading them
+// requires the cluster role, the zone is left empty when it cannot be read
+func getPodZone(podNamespacedName types.NamespacedName, client rtclient.Client, nodeZones map[string]string) string {
+	pod := &corev1.Pod{}
+	if err := client.Get(context.TODO(), podNamespacedName, pod); err != nil || pod.Spec.NodeName == "" {
+		return ""
+	}
+
+	if zone, found := nodeZones[pod.Spec.NodeName]; found {
+		return zone
+	}
+
+	node := &corev1.Node{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+		clog.V(1).Info("unable to retrieve the zone of the node", "node", pod.Spec.NodeName, "error", err)
+	}
+	// remember misses too, the node is looked up once per poll
+	nodeZones[pod.Spec.NodeName] = node.Labels[corev1.LabelTopologyZone]
+	return nodeZones[pod.Spec.NodeName]
+}
+
+func divertsStatus(diverts []brokerv1beta1.DivertType, divertOrdinals map[string][]string, brokerCount int) []brokerv1beta1.DivertStatus {
+	var status []brokerv1beta1.DivertStatus
+	for _, divert := range diverts {
+		// ordered as the brokers were polled
+		ordinals := divertOrdinals[divert.Name]
+		status = append(status, brokerv1beta1.DivertStatus{
+			Name:     divert.Name,
+			Applied:  brokerCount > 0 && len(ordinals) == brokerCount,
+			Ordinals: ordinals,
+		})
+	}
+	return status
+}
+
+func setBrokerStatusFromInfo(brokerStatus *brokerv1beta1.BrokerStatus, info *mgmt.BrokerInfo) {
+	brokerStatus.Uptime = info.Uptime
+	brokerStatus.NodeID = info.NodeID
+	if info.Backup {
+		brokerStatus.Role = brokerv1beta1.BrokerRoleBackup
+	} else {
+		brokerStatus.Role = brokerv1beta1.BrokerRoleLive
+	}
+	brokerStatus.AddressCount = int32(len(info.AddressNames))
+	brokerStatus.QueueCount = int32(len(info.QueueNames))
+	brokerStatus.TotalMessageCount = info.TotalMessageCount
+	brokerStatus.PagingAddresses = info.PagingAddresses
+	brokerStatus.DiskStoreUsagePercentage = int32(info.DiskStoreUsage * 100)
+
+	brokerStatus.ClusterTopology = nil
+	for _, member := range info.Topology {
+		brokerStatus.ClusterTopology = append(brokerStatus.ClusterTopology, brokerv1beta1.TopologyMemberStatus{
+			NodeID: member.NodeID,
+			Live:   member.Live,
+			Backup: member.Backup,
+		})
+	}
+}
+
 func trapErrorAsCondition(err ArtemisError, conditionType string) metav1.Condition {
 	var condition metav1.Condition
-	switch err.(type) {
+	switch e := err.(type) {
 	case jolokiaClientNotFoundError:
 		condition = metav1.Condition{
 			Type:    conditionType,
@@ -2674,6 +3444,20 @@ func trapErrorAsCondition(err ArtemisError, conditionType string) metav1.Conditi
 			Reason:  brokerv1beta1.ConfigAppliedConditionOutOfSyncReason,
 			Message: err.Error(),
 		}
+	case jolokiaRequestError:
+		condition = metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionUnknown,
+			Reason:  brokerv1beta1.ConfigAppliedConditionUnknownReason,
+			Message: err.Error(),
+		}
+		switch jolokia.KindOf(e.cause) {
+		case jolokia.ErrorKindAuthFailed:
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = brokerv1beta1.ConfigAppliedConditionUnauthorizedReason
+		case jolokia.ErrorKindUnavailable:
+			condition.Reason = brokerv1beta1.ConfigAppliedConditionBrokerUnavailableReason
+		}
 	case inSyncApplyError:
 		condition = metav1.Condition{
 			Type:    conditionType,
@@ -2776,7 +3560,7 @@ func checkStatus(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, Proj
 
 		if err != nil {
 			reqLogger.Info("unknown status reported from Jolokia.", "IP", jk.IP, "Ordinal", jk.Ordinal, "error", err)
-			return NewUnknownJolokiaError(err)
+			return NewJolokiaRequestError(err)
 		}
 
 		brokerStatus, err := unmarshallStatus(currentJson)
@@ -2984,3 +3768,15 @@ func getDeploymentSize(cr *brokerv1beta1.ActiveMQArtemis) int32 {
 	}
 	return *cr.Spec.DeploymentPlan.Size
 }
+
+// getBrokerPodCount is the number of pods of the StatefulSet, a live broker and its backup per deployment plan size when paired
+func getBrokerPodCount(cr *brokerv1beta1.ActiveMQArtemis) int32 {
+	return getDeploymentSize(cr) * getPodsPerBroker(cr)
+}
+
+func getPodsPerBroker(cr *brokerv1beta1.ActiveMQArtemis) int32 {
+	if isHAPaired(cr) {
+		return 2
+	}
+	return 1
+}
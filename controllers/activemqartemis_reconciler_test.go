@@ -1,20 +1,30 @@
 package controllers
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/RHsyseng/operator-utils/pkg/olm"
 	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
 	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	mgmt "github.com/artemiscloud/activemq-artemis-operator/pkg/utils/artemis"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia"
 	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 )
 
 func TestHexShaHashOfMap(t *testing.T) {
@@ -617,3 +627,738 @@ func TestLoginConfigSyntaxCheck(t *testing.T) {
 	}
 
 }
+
+func TestBrokerPropertiesForCRWithDiverts(t *testing.T) {
+	exclusive := true
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Diverts: []brokerv1beta1.DivertType{
+				{
+					Name:                  "audit",
+					Address:               "orders",
+					ForwardingAddress:     "orders.audit",
+					Exclusive:             &exclusive,
+					Filter:                "priority > 5",
+					RoutingType:           "pass",
+					TransformerClassName:  "org.example.Stamp",
+					TransformerProperties: map[string]string{"b": "2", "a": "1"},
+				},
+			},
+			BrokerProperties: []string{"divertConfigurations.audit.exclusive=false"},
+		},
+	}
+
+	props := brokerPropertiesForCR(cr)
+
+	assert.Equal(t, []string{
+		"divertConfigurations.audit.address=orders",
+		"divertConfigurations.audit.forwardingAddress=orders.audit",
+		"divertConfigurations.audit.exclusive=true",
+		"divertConfigurations.audit.filterString=priority > 5",
+		"divertConfigurations.audit.routingType=PASS",
+		"divertConfigurations.audit.transformerConfiguration.className=org.example.Stamp",
+		"divertConfigurations.audit.transformerConfiguration.properties.a=1",
+		"divertConfigurations.audit.transformerConfiguration.properties.b=2",
+		"divertConfigurations.audit.exclusive=false",
+	}, props)
+}
+
+func TestValidateDiverts(t *testing.T) {
+	valid := brokerv1beta1.DivertType{Name: "audit", Address: "orders", ForwardingAddress: "orders.audit"}
+
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Spec.Diverts = []brokerv1beta1.DivertType{valid}
+	assert.Nil(t, validateDiverts(cr))
+
+	for name, divert := range map[string]brokerv1beta1.DivertType{
+		"dotted name":      {Name: "a.b", Address: "orders", ForwardingAddress: "audit"},
+		"missing address":  {Name: "a", ForwardingAddress: "audit"},
+		"missing target":   {Name: "a", Address: "orders"},
+		"same address":     {Name: "a", Address: "orders", ForwardingAddress: "orders"},
+		"bad routing type": {Name: "a", Address: "orders", ForwardingAddress: "audit", RoutingType: "DIRECT"},
+		"duplicate name":   {Name: "audit", Address: "orders", ForwardingAddress: "audit"},
+	} {
+		cr.Spec.Diverts = []brokerv1beta1.DivertType{valid, divert}
+		condition := validateDiverts(cr)
+		if assert.NotNil(t, condition, name) {
+			assert.Equal(t, brokerv1beta1.ValidConditionInvalidDivertReason, condition.Reason, name)
+			assert.Contains(t, condition.Message, ".Spec.Diverts[1]", name)
+		}
+	}
+}
+
+func TestValidateScaleToZeroDrainTarget(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	cr.Spec.Connectors = []brokerv1beta1.ConnectorType{
+		{Name: "dr", Host: "dr-broker", Port: 61616},
+		{Name: "amqp", Host: "dr-broker", Port: 5672},
+	}
+
+	for _, target := range []brokerv1beta1.DrainTargetType{
+		{ActiveMQArtemis: "dr"},
+		{ConnectorName: "dr", CredentialsSecretName: "dr-credentials"},
+		{ConnectorName: "amqp"},
+	} {
+		cr.Spec.DeploymentPlan.ScaleToZeroDrainTarget = &target
+		assert.Nil(t, validateScaleToZeroDrainTarget(cr))
+	}
+
+	for name, target := range map[string]brokerv1beta1.DrainTargetType{
+		"no target":         {},
+		"both targets":      {ActiveMQArtemis: "dr", ConnectorName: "dr"},
+		"itself":            {ActiveMQArtemis: "ex-aao"},
+		"unknown connector": {ConnectorName: "other"},
+	} {
+		cr.Spec.DeploymentPlan.ScaleToZeroDrainTarget = &target
+		condition := validateScaleToZeroDrainTarget(cr)
+		if assert.NotNil(t, condition, name) {
+			assert.Equal(t, brokerv1beta1.ValidConditionInvalidDrainTargetReason, condition.Reason, name)
+		}
+	}
+
+	messageMigration := false
+	cr.Spec.DeploymentPlan.MessageMigration = &messageMigration
+	cr.Spec.DeploymentPlan.ScaleToZeroDrainTarget = &brokerv1beta1.DrainTargetType{ActiveMQArtemis: "dr"}
+	assert.NotNil(t, validateScaleToZeroDrainTarget(cr))
+}
+
+func TestGetScaleToZeroTarget(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	cr.Namespace = "test"
+	cr.Spec.Connectors = []brokerv1beta1.ConnectorType{{Name: "dr", Host: "dr-broker", Port: 61617}}
+	namer := MakeNamers(cr)
+
+	assert.Nil(t, getScaleToZeroTarget(cr, *namer))
+
+	cr.Spec.DeploymentPlan.ScaleToZeroDrainTarget = &brokerv1beta1.DrainTargetType{ActiveMQArtemis: "other"}
+	assert.Equal(t, &brokerv1beta1.ScaleToZeroTargetType{
+		HeadlessServiceName:   "other-hdls-svc",
+		CredentialsSecretName: "other-credentials-secret",
+	}, getScaleToZeroTarget(cr, *namer))
+
+	cr.Spec.DeploymentPlan.ScaleToZeroDrainTarget = &brokerv1beta1.DrainTargetType{ConnectorName: "dr"}
+	assert.Equal(t, &brokerv1beta1.ScaleToZeroTargetType{
+		Host:                  "dr-broker",
+		Port:                  61617,
+		CredentialsSecretName: "ex-aao-credentials-secret",
+	}, getScaleToZeroTarget(cr, *namer))
+}
+
+func TestGetDeploymentConditionPaired(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	size := int32(2)
+	cr.Spec.DeploymentPlan.Size = &size
+	podStatus := func(ready ...string) olm.DeploymentStatus {
+		return olm.DeploymentStatus{Ready: ready}
+	}
+
+	condition := getDeploymentCondition(cr, podStatus("ex-aao-ss-0", "ex-aao-ss-1", "ex-aao-ss-2"), true)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "3/2 pods ready", condition.Message)
+
+	cr.Spec.DeploymentPlan.HAPolicy = &brokerv1beta1.HAPolicyType{Type: brokerv1beta1.HAPolicySharedStore}
+
+	// the backups ex-aao-ss-1 and ex-aao-ss-3 are not ready while their live brokers are
+	condition = getDeploymentCondition(cr, podStatus("ex-aao-ss-0", "ex-aao-ss-2"), true)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, brokerv1beta1.DeployedConditionReadyReason, condition.Reason)
+
+	// a backup that took over is ready along with the restarted live broker
+	condition = getDeploymentCondition(cr, podStatus("ex-aao-ss-0", "ex-aao-ss-1", "ex-aao-ss-2"), true)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	// the backup that took over serves the first pair
+	condition = getDeploymentCondition(cr, podStatus("ex-aao-ss-1", "ex-aao-ss-2"), true)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	// both pods of the first pair are ready, the second pair is down
+	condition = getDeploymentCondition(cr, podStatus("ex-aao-ss-0", "ex-aao-ss-1"), true)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "1/2 live brokers ready", condition.Message)
+
+	condition = getDeploymentCondition(cr, podStatus("ex-aao-ss-0"), true)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, brokerv1beta1.DeployedConditionNotReadyReason, condition.Reason)
+	assert.Equal(t, "1/2 live brokers ready", condition.Message)
+}
+
+func TestHAPolicyBrokerProperties(t *testing.T) {
+	size := int32(1)
+	allowFailBack := false
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	cr.Spec.DeploymentPlan.Size = &size
+
+	assert.Nil(t, haPolicyBrokerProperties(cr))
+	assert.Equal(t, int32(1), getBrokerPodCount(cr))
+
+	cr.Spec.DeploymentPlan.HAPolicy = &brokerv1beta1.HAPolicyType{Type: brokerv1beta1.HAPolicyReplication, AllowFailBack: &allowFailBack}
+	assert.Equal(t, int32(2), getBrokerPodCount(cr))
+	assert.Equal(t, []string{
+		"broker-0.HAPolicyConfiguration=REPLICATED",
+		"broker-0.HAPolicyConfiguration.checkForLiveServer=true",
+		"broker-0.HAPolicyConfiguration.groupName=ex-aao-0",
+		"broker-1.HAPolicyConfiguration=REPLICA",
+		"broker-1.HAPolicyConfiguration.allowFailBack=false",
+		"broker-1.HAPolicyConfiguration.groupName=ex-aao-0",
+	}, haPolicyBrokerProperties(cr))
+
+	cr.Spec.DeploymentPlan.HAPolicy = &brokerv1beta1.HAPolicyType{Type: brokerv1beta1.HAPolicySharedStore}
+	cr.Spec.BrokerProperties = []string{"broker-1.pagingDirectory=/tmp/paging"}
+	props := brokerPropertiesForCR(cr)
+	assert.Equal(t, []string{
+		"broker-0.HAPolicyConfiguration=SHARED_STORE_MASTER",
+		"broker-0.journalDirectory=/opt/ex-aao/shared-store/pair-0/journal",
+		"broker-0.bindingsDirectory=/opt/ex-aao/shared-store/pair-0/bindings",
+		"broker-0.pagingDirectory=/opt/ex-aao/shared-store/pair-0/paging",
+		"broker-0.largeMessagesDirectory=/opt/ex-aao/shared-store/pair-0/large-messages",
+		"broker-1.HAPolicyConfiguration=SHARED_STORE_SLAVE",
+		"broker-1.HAPolicyConfiguration.allowFailBack=true",
+		"broker-1.journalDirectory=/opt/ex-aao/shared-store/pair-0/journal",
+		"broker-1.bindingsDirectory=/opt/ex-aao/shared-store/pair-0/bindings",
+		"broker-1.pagingDirectory=/opt/ex-aao/shared-store/pair-0/paging",
+		"broker-1.largeMessagesDirectory=/opt/ex-aao/shared-store/pair-0/large-messages",
+		"broker-1.pagingDirectory=/tmp/paging",
+	}, props)
+
+	data := brokerPropertiesData(props)
+	assert.Contains(t, data, "broker-0."+BrokerPropertiesName)
+	assert.True(t, strings.HasSuffix(data["broker-1."+BrokerPropertiesName], "pagingDirectory=/tmp/paging\n"))
+}
+
+func TestGetSingleStatefulSetStatusWithBackups(t *testing.T) {
+	replicas := int32(4)
+	ss := &appsv1.StatefulSet{}
+	ss.Name = "ex-aao-ss"
+	ss.Spec.Replicas = &replicas
+
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Spec.DeploymentPlan.HAPolicy = &brokerv1beta1.HAPolicyType{Type: brokerv1beta1.HAPolicyReplication}
+	getSingleStatefulSetStatus(ss, cr)
+
+	assert.Equal(t, int32(2), cr.Status.DeploymentPlanSize)
+}
+
+func TestValidateHAPolicy(t *testing.T) {
+	clustered := false
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	cr.Namespace = "test"
+	cr.Spec.DeploymentPlan.HAPolicy = &brokerv1beta1.HAPolicyType{Type: brokerv1beta1.HAPolicySharedStore}
+	namer := MakeNamers(cr)
+
+	assert.Nil(t, validateHAPolicy(cr, fake.NewClientBuilder().Build(), *namer))
+
+	deployed := &appsv1.StatefulSet{}
+	deployed.Name = namer.SsNameBuilder.Name()
+	deployed.Namespace = cr.Namespace
+	deployed.Spec.PodManagementPolicy = appsv1.OrderedReadyPodManagement
+	condition := validateHAPolicy(cr, fake.NewClientBuilder().WithObjects(deployed).Build(), *namer)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, brokerv1beta1.ValidConditionInvalidHAPolicyReason, condition.Reason)
+	}
+
+	deployed.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+	assert.Nil(t, validateHAPolicy(cr, fake.NewClientBuilder().WithObjects(deployed).Build(), *namer))
+
+	cr.Spec.DeploymentPlan.Clustered = &clustered
+	condition = validateHAPolicy(cr, fake.NewClientBuilder().Build(), *namer)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, brokerv1beta1.ValidConditionInvalidHAPolicyReason, condition.Reason)
+	}
+}
+func TestGetQueueConfigurationDrift(t *testing.T) {
+	maxConsumers := int32(10)
+	exclusive := true
+	filter := "color='red'"
+
+	queueInfo := &mgmt.QueueInfo{
+		RoutingType:  "ANYCAST",
+		Filter:       filter,
+		MaxConsumers: -1,
+		Exclusive:    true,
+	}
+
+	assert.Empty(t, getQueueConfigurationDrift(nil, queueInfo))
+	assert.Empty(t, getQueueConfigurationDrift(&brokerv1beta1.QueueConfigurationType{Exclusive: &exclusive, FilterString: &filter}, queueInfo))
+
+	drift := getQueueConfigurationDrift(&brokerv1beta1.QueueConfigurationType{MaxConsumers: &maxConsumers, Exclusive: &exclusive}, queueInfo)
+	assert.Equal(t, []string{"maxConsumers (expected 10, found -1)"}, drift)
+}
+
+func TestGetAddressSynchronizedCondition(t *testing.T) {
+	condition := getAddressSynchronizedCondition(nil)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, brokerv1beta1.AddressSynchronizedNoBrokersReason, condition.Reason)
+
+	podStatus := []brokerv1beta1.AddressPodStatus{
+		{
+			PodName: "ex-aao-ss-0",
+			Conditions: []metav1.Condition{{
+				Type:   brokerv1beta1.AddressSynchronizedConditionType,
+				Status: metav1.ConditionTrue,
+				Reason: brokerv1beta1.AddressSynchronizedCreatedReason,
+			}},
+		},
+		{
+			PodName: "ex-aao-ss-1",
+			Conditions: []metav1.Condition{{
+				Type:   brokerv1beta1.AddressSynchronizedConditionType,
+				Status: metav1.ConditionTrue,
+				Reason: brokerv1beta1.AddressSynchronizedDriftedReason,
+			}},
+		},
+	}
+
+	condition = getAddressSynchronizedCondition(podStatus)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, brokerv1beta1.AddressSynchronizedDriftedReason, condition.Reason)
+	assert.Equal(t, "Repaired drift on ex-aao-ss-1", condition.Message)
+
+	podStatus[0].Conditions[0].Status = metav1.ConditionFalse
+	podStatus[0].Conditions[0].Reason = brokerv1beta1.AddressSynchronizedFailedReason
+
+	condition = getAddressSynchronizedCondition(podStatus)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, brokerv1beta1.AddressSynchronizedFailedReason, condition.Reason)
+	assert.Equal(t, "Failed to synchronize on ex-aao-ss-0", condition.Message)
+}
+
+func TestGetAddressMigration(t *testing.T) {
+	oldQueue := "orders"
+	newQueue := "orders.v2"
+	anycast := "anycast"
+
+	previous := &AddressDeployment{
+		AddressResource: brokerv1beta1.ActiveMQArtemisAddress{
+			Spec: brokerv1beta1.ActiveMQArtemisAddressSpec{
+				AddressName: "orders",
+				QueueName:   &oldQueue,
+				RoutingType: &anycast,
+			},
+		},
+	}
+
+	assert.Nil(t, getAddressMigration(nil, &previous.AddressResource.Spec))
+	assert.Nil(t, getAddressMigration(previous, &previous.AddressResource.Spec))
+
+	renamed := brokerv1beta1.ActiveMQArtemisAddressSpec{
+		AddressName:              "orders",
+		QueueName:                &newQueue,
+		RoutingType:              &anycast,
+		RemoveFromBrokerOnDelete: true,
+		MoveMessagesOnChange:     true,
+	}
+	migration := getAddressMigration(previous, &renamed)
+	assert.NotNil(t, migration)
+	assert.Equal(t, "orders", migration.OldQueueName)
+	assert.Equal(t, "ANYCAST", migration.OldRoutingType)
+	assert.True(t, migration.MoveMessages)
+	assert.True(t, migration.RemovePrevious)
+	assert.False(t, migration.RemoveOldAddress)
+
+	moved := brokerv1beta1.ActiveMQArtemisAddressSpec{
+		AddressName: "sales",
+	}
+	migration = getAddressMigration(previous, &moved)
+	assert.NotNil(t, migration)
+	assert.Equal(t, "queue orders/orders", migration.previousResource())
+	assert.False(t, migration.MoveMessages)
+	assert.False(t, migration.RemovePrevious)
+	assert.True(t, migration.RemoveOldAddress)
+	assert.Nil(t, validateAddressMigration(previous, &moved))
+
+	multicast := "multicast"
+	retyped := brokerv1beta1.ActiveMQArtemisAddressSpec{
+		AddressName: "orders",
+		QueueName:   &oldQueue,
+		RoutingType: &multicast,
+	}
+	condition := validateAddressMigration(previous, &retyped)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, brokerv1beta1.AddressSynchronizedInvalidReason, condition.Reason)
+		assert.Equal(t, "The routingType of the address orders can not change from ANYCAST to MULTICAST, change the addressName to migrate to a new address", condition.Message)
+	}
+
+	// the queue can not move to a new address, the queue of the current spec is never removed
+	readdressed := brokerv1beta1.ActiveMQArtemisAddressSpec{
+		AddressName:              "sales",
+		QueueName:                &oldQueue,
+		RoutingType:              &anycast,
+		RemoveFromBrokerOnDelete: true,
+		MoveMessagesOnChange:     true,
+	}
+	condition = validateAddressMigration(previous, &readdressed)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, brokerv1beta1.AddressSynchronizedInvalidReason, condition.Reason)
+		assert.Equal(t, "The queue orders can not move from the address orders to sales, change the queueName to migrate to a new queue", condition.Message)
+	}
+	migration = getAddressMigration(previous, &readdressed)
+	if assert.NotNil(t, migration) {
+		assert.Empty(t, migration.OldQueueName)
+		assert.False(t, migration.MoveMessages)
+		assert.True(t, migration.RemoveOldAddress)
+	}
+
+	// a new address may have another routing type, the messages move from the previous anycast queue
+	retyped.AddressName = "sales"
+	retyped.QueueName = &newQueue
+	retyped.MoveMessagesOnChange = true
+	assert.Nil(t, validateAddressMigration(previous, &retyped))
+	migration = getAddressMigration(previous, &retyped)
+	assert.Equal(t, "ANYCAST", migration.OldRoutingType)
+	assert.True(t, migration.MoveMessages)
+}
+
+func TestTopologySpreadConstraintsForCR(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	namer := MakeNamers(cr)
+
+	constraints := topologySpreadConstraintsForCR(cr, *namer)
+	if assert.Len(t, constraints, 1) {
+		assert.Equal(t, v1.LabelTopologyZone, constraints[0].TopologyKey)
+		assert.Equal(t, v1.ScheduleAnyway, constraints[0].WhenUnsatisfiable)
+		assert.Equal(t, namer.LabelBuilder.Labels(), constraints[0].LabelSelector.MatchLabels)
+	}
+
+	zoneAware := false
+	cr.Spec.DeploymentPlan.ZoneAware = &zoneAware
+	assert.Nil(t, topologySpreadConstraintsForCR(cr, *namer))
+
+	zoneAware = true
+	clustered := false
+	cr.Spec.DeploymentPlan.Clustered = &clustered
+	assert.Nil(t, topologySpreadConstraintsForCR(cr, *namer))
+
+	configured := []v1.TopologySpreadConstraint{{MaxSkew: 2, TopologyKey: v1.LabelHostname, WhenUnsatisfiable: v1.DoNotSchedule}}
+	cr.Spec.DeploymentPlan.TopologySpreadConstraints = configured
+	assert.Equal(t, configured, topologySpreadConstraintsForCR(cr, *namer))
+}
+
+func TestGetPodZone(t *testing.T) {
+	pod := &v1.Pod{}
+	pod.Name = "ex-aao-ss-0"
+	pod.Namespace = "test"
+	pod.Spec.NodeName = "worker-a"
+	unscheduled := &v1.Pod{}
+	unscheduled.Name = "ex-aao-ss-1"
+	unscheduled.Namespace = "test"
+	node := &v1.Node{}
+	node.Name = "worker-a"
+	node.Labels = map[string]string{v1.LabelTopologyZone: "eu-west-1a"}
+
+	nodeZones := map[string]string{}
+	client := fake.NewClientBuilder().WithObjects(pod, unscheduled, node).Build()
+	assert.Equal(t, "eu-west-1a", getPodZone(types.NamespacedName{Namespace: "test", Name: "ex-aao-ss-0"}, client, nodeZones))
+	assert.Equal(t, "", getPodZone(types.NamespacedName{Namespace: "test", Name: "ex-aao-ss-1"}, client, nodeZones))
+	assert.Equal(t, "", getPodZone(types.NamespacedName{Namespace: "test", Name: "ex-aao-ss-2"}, client, nodeZones))
+	assert.Equal(t, map[string]string{"worker-a": "eu-west-1a"}, nodeZones)
+}
+
+func TestTrapJolokiaRequestErrorAsCondition(t *testing.T) {
+	denied := NewJolokiaRequestError(&jolokia.JolokiaError{HttpCode: 401, Message: " Error: 401 Unauthorized"})
+	assert.False(t, denied.Requeue())
+	condition := trapErrorAsCondition(denied, brokerv1beta1.ConfigAppliedConditionType)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, brokerv1beta1.ConfigAppliedConditionUnauthorizedReason, condition.Reason)
+
+	unavailable := NewJolokiaRequestError(&jolokia.JolokiaError{HttpCode: 503, Message: " Error: 503 Service Unavailable"})
+	assert.True(t, unavailable.Requeue())
+	condition = trapErrorAsCondition(unavailable, brokerv1beta1.ConfigAppliedConditionType)
+	assert.Equal(t, metav1.ConditionUnknown, condition.Status)
+	assert.Equal(t, brokerv1beta1.ConfigAppliedConditionBrokerUnavailableReason, condition.Reason)
+
+	unknown := NewJolokiaRequestError(errors.New("boom"))
+	assert.True(t, unknown.Requeue())
+	condition = trapErrorAsCondition(unknown, brokerv1beta1.ConfigAppliedConditionType)
+	assert.Equal(t, brokerv1beta1.ConfigAppliedConditionUnknownReason, condition.Reason)
+}
+
+func TestAddressSynchronizedFailedReason(t *testing.T) {
+	assert.Equal(t, brokerv1beta1.AddressSynchronizedUnauthorizedReason, addressSynchronizedFailedReason(&jolokia.ManagementError{Kind: jolokia.ErrorKindAuthFailed}))
+	assert.Equal(t, brokerv1beta1.AddressSynchronizedInvalidReason, addressSynchronizedFailedReason(&jolokia.ManagementError{Kind: jolokia.ErrorKindInvalidArgument}))
+	assert.Equal(t, brokerv1beta1.AddressSynchronizedBrokerUnavailableReason, addressSynchronizedFailedReason(&jolokia.JolokiaError{HttpCode: 502}))
+	assert.Equal(t, brokerv1beta1.AddressSynchronizedFailedReason, addressSynchronizedFailedReason(errors.New("boom")))
+}
+
+func TestValidateManagementCredentials(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	cr.Namespace = "test"
+	cr.Spec.ManagementCredentials = &brokerv1beta1.ManagementCredentialsType{}
+
+	condition, _ := validateManagementCredentials(cr, fake.NewClientBuilder().Build(), nil)
+	assert.Nil(t, condition)
+
+	cr.Spec.ManagementCredentials.SecretName = "mgmt"
+	condition, retry := validateManagementCredentials(cr, fake.NewClientBuilder().Build(), nil)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, brokerv1beta1.ValidConditionInvalidCredentialsReason, condition.Reason)
+		assert.True(t, retry)
+	}
+
+	secret := &v1.Secret{}
+	secret.Name = "mgmt"
+	secret.Namespace = cr.Namespace
+	secret.Data = map[string][]byte{"username": []byte("operator")}
+	condition, _ = validateManagementCredentials(cr, fake.NewClientBuilder().WithObjects(secret).Build(), nil)
+	if assert.NotNil(t, condition) {
+		assert.Contains(t, condition.Message, "password")
+	}
+
+	secret.Data["password"] = []byte("s3cret")
+	condition, _ = validateManagementCredentials(cr, fake.NewClientBuilder().WithObjects(secret).Build(), nil)
+	assert.Nil(t, condition)
+}
+
+func TestCredentialsRotationDue(t *testing.T) {
+	generatedAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Spec.CredentialsRotation = &brokerv1beta1.CredentialsRotationType{Interval: &metav1.Duration{Duration: 24 * time.Hour}}
+
+	// not before the brokers are deployed
+	assert.False(t, credentialsRotationDue(cr, generatedAt, generatedAt.Add(48*time.Hour)))
+
+	cr.Status.Conditions = []metav1.Condition{{Type: brokerv1beta1.DeployedConditionType, Status: metav1.ConditionTrue}}
+	assert.False(t, credentialsRotationDue(cr, generatedAt, generatedAt.Add(23*time.Hour)))
+	assert.True(t, credentialsRotationDue(cr, generatedAt, generatedAt.Add(24*time.Hour)))
+
+	cr.Status.CredentialsRotation = &brokerv1beta1.CredentialsRotationStatus{LastRotationTime: metav1.NewTime(generatedAt.Add(24 * time.Hour))}
+	assert.False(t, credentialsRotationDue(cr, generatedAt, generatedAt.Add(30*time.Hour)))
+	assert.True(t, credentialsRotationDue(cr, generatedAt, generatedAt.Add(48*time.Hour)))
+
+	// on request
+	cr.Spec.CredentialsRotation = nil
+	assert.False(t, credentialsRotationDue(cr, generatedAt, generatedAt.Add(30*time.Hour)))
+	cr.Annotations = map[string]string{brokerv1beta1.RotateCredentialsAnnotation: "2023-01-02"}
+	assert.True(t, credentialsRotationDue(cr, generatedAt, generatedAt.Add(30*time.Hour)))
+	cr.Status.CredentialsRotation.LastRequest = "2023-01-02"
+	assert.False(t, credentialsRotationDue(cr, generatedAt, generatedAt.Add(30*time.Hour)))
+}
+
+func TestIsCredentialsRotationDueOnlyForOwnedSecret(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	cr.Namespace = "test"
+	cr.Annotations = map[string]string{brokerv1beta1.RotateCredentialsAnnotation: "now"}
+	cr.Status.Conditions = []metav1.Condition{{Type: brokerv1beta1.DeployedConditionType, Status: metav1.ConditionTrue}}
+
+	assert.False(t, isCredentialsRotationDue(cr, "ex-aao-credentials-secret", fake.NewClientBuilder().Build()))
+
+	secret := &v1.Secret{}
+	secret.Name = "ex-aao-credentials-secret"
+	secret.Namespace = cr.Namespace
+	assert.False(t, isCredentialsRotationDue(cr, secret.Name, fake.NewClientBuilder().WithObjects(secret).Build()))
+
+	secret.OwnerReferences = []metav1.OwnerReference{{Kind: "ActiveMQArtemis", Name: cr.Name}}
+	assert.True(t, isCredentialsRotationDue(cr, secret.Name, fake.NewClientBuilder().WithObjects(secret).Build()))
+}
+
+func TestClusterCredentials(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	cr.Namespace = "test"
+
+	// generated for each CR
+	user, password := clusterCredentials(cr, fake.NewClientBuilder().Build())
+	otherUser, otherPassword := clusterCredentials(cr, fake.NewClientBuilder().Build())
+	assert.True(t, user.AutoGen)
+	assert.True(t, password.AutoGen)
+	assert.NotEqual(t, user.Value, otherUser.Value)
+	assert.NotEqual(t, password.Value, otherPassword.Value)
+
+	secret := &v1.Secret{}
+	secret.Name = "cluster"
+	secret.Namespace = cr.Namespace
+	secret.Data = map[string][]byte{"user": []byte("clusterer"), "password": []byte("s3cret")}
+	cr.Spec.ClusterCredentials = &brokerv1beta1.ClusterCredentialsType{SecretName: "cluster", UserKey: "user"}
+
+	user, password = clusterCredentials(cr, fake.NewClientBuilder().WithObjects(secret).Build())
+	assert.Equal(t, ValueInfo{Value: "clusterer"}, user)
+	assert.Equal(t, ValueInfo{Value: "s3cret"}, password)
+
+	condition, _ := validateClusterCredentials(cr, fake.NewClientBuilder().WithObjects(secret).Build(), nil)
+	assert.Nil(t, condition)
+
+	cr.Spec.ClusterCredentials.UserKey = ""
+	condition, retry := validateClusterCredentials(cr, fake.NewClientBuilder().WithObjects(secret).Build(), nil)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, brokerv1beta1.ValidConditionInvalidClusterCredentialsReason, condition.Reason)
+		assert.Contains(t, condition.Message, "username")
+		assert.True(t, retry)
+	}
+}
+
+func TestProcessCredentialsRotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(scheme))
+
+	size := int32(2)
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	cr.Namespace = "test"
+	cr.Annotations = map[string]string{brokerv1beta1.RotateCredentialsAnnotation: "now"}
+	cr.Spec.DeploymentPlan.Size = &size
+	cr.Status.Conditions = []metav1.Condition{{Type: brokerv1beta1.DeployedConditionType, Status: metav1.ConditionTrue}}
+	namer := MakeNamers(cr)
+
+	secret := &v1.Secret{}
+	secret.Name = "ex-aao-credentials-secret"
+	secret.Namespace = cr.Namespace
+	secret.OwnerReferences = []metav1.OwnerReference{{Kind: "ActiveMQArtemis", Name: cr.Name}}
+	secret.Data = map[string][]byte{
+		"AMQ_USER":             []byte("admin"),
+		"AMQ_PASSWORD":         []byte("before"),
+		"AMQ_CLUSTER_USER":     []byte("cluster"),
+		"AMQ_CLUSTER_PASSWORD": []byte("cluster-before"),
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr, secret).Build()
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, cr))
+
+	statefulSet := &appsv1.StatefulSet{}
+	statefulSet.Name = "ex-aao-ss"
+	statefulSet.Namespace = cr.Namespace
+	statefulSet.Generation = 3
+	statefulSet.Spec.Template.Spec.Containers = []v1.Container{{Name: "ex-aao-container"}}
+
+	reconciler := &ActiveMQArtemisReconcilerImpl{}
+	reconciler.ProcessCredentials(cr, *namer, fakeClient, scheme, statefulSet)
+
+	// the rotation is recorded before the secret is written
+	stored := &brokerv1beta1.ActiveMQArtemis{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, stored))
+	assert.Equal(t, "now", stored.Status.CredentialsRotation.LastRequest)
+	assert.False(t, stored.Status.CredentialsRotation.ClusterCredentialsRotated)
+
+	requested := map[string]*v1.Secret{}
+	for _, obj := range reconciler.requestedResources {
+		if s, ok := obj.(*v1.Secret); ok {
+			requested[s.Name] = s
+		}
+	}
+	rotated := requested["ex-aao-credentials-secret"]
+	assert.NotEqual(t, "before", string(rotated.Data["AMQ_PASSWORD"]))
+	// the cluster credentials of the clustered brokers are kept
+	assert.Equal(t, "cluster-before", string(rotated.Data["AMQ_CLUSTER_PASSWORD"]))
+
+	previous := requested["ex-aao-credentials-previous"]
+	assert.Equal(t, map[string]string{"AMQ_USER": "admin", "AMQ_PASSWORD": "before"}, previous.StringData)
+	assert.Equal(t, "3", previous.Annotations[rotatedGenerationAnnotation])
+
+	// no new rotation while the previous credentials are in use
+	cr.Annotations[brokerv1beta1.RotateCredentialsAnnotation] = "again"
+	statefulSet.Status.ObservedGeneration = 3
+	reconciler = &ActiveMQArtemisReconcilerImpl{deployed: map[reflect.Type][]client.Object{reflect.TypeOf(v1.Secret{}): {previous}}}
+	reconciler.ProcessCredentials(cr, *namer, fakeClient, scheme, statefulSet)
+	assert.Equal(t, "now", cr.Status.CredentialsRotation.LastRequest)
+	assert.Contains(t, reconciler.requestedResources, client.Object(previous))
+
+	// nor while the pods are replaced
+	statefulSet.Status.ObservedGeneration = 4
+	statefulSet.Status.Replicas = 2
+	statefulSet.Status.UpdatedReplicas = 1
+	reconciler = &ActiveMQArtemisReconcilerImpl{deployed: map[reflect.Type][]client.Object{reflect.TypeOf(v1.Secret{}): {previous}}}
+	reconciler.ProcessCredentials(cr, *namer, fakeClient, scheme, statefulSet)
+	assert.Contains(t, reconciler.requestedResources, client.Object(previous))
+
+	// they are dropped once the statefulset has rolled out
+	statefulSet.Status.UpdatedReplicas = 2
+	reconciler = &ActiveMQArtemisReconcilerImpl{deployed: map[reflect.Type][]client.Object{reflect.TypeOf(v1.Secret{}): {previous}}}
+	reconciler.ProcessCredentials(cr, *namer, fakeClient, scheme, statefulSet)
+	assert.NotContains(t, reconciler.requestedResources, client.Object(previous))
+}
+
+func TestRollPairedPods(t *testing.T) {
+	size := int32(2)
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	cr.Name = "ex-aao"
+	cr.Namespace = "test"
+	cr.Spec.DeploymentPlan.Size = &size
+	cr.Spec.DeploymentPlan.HAPolicy = &brokerv1beta1.HAPolicyType{Type: brokerv1beta1.HAPolicyReplication}
+	namer := MakeNamers(cr)
+
+	reconciler := &ActiveMQArtemisReconcilerImpl{}
+	statefulSet, err := reconciler.NewStatefulSetForCR(cr, *namer, nil, fake.NewClientBuilder().Build())
+	if assert.NoError(t, err) {
+		assert.Equal(t, appsv1.ParallelPodManagement, statefulSet.Spec.PodManagementPolicy)
+		assert.Equal(t, appsv1.OnDeleteStatefulSetStrategyType, statefulSet.Spec.UpdateStrategy.Type)
+	}
+	statefulSet.Status.UpdateRevision = "v2"
+
+	started := time.Now().Add(-time.Hour)
+	runningPod := func(ordinal string, revision string, startedAt time.Time) *v1.Pod {
+		pod := &v1.Pod{}
+		pod.Name = "ex-aao-ss-" + ordinal
+		pod.Namespace = "test"
+		pod.Labels = map[string]string{appsv1.StatefulSetRevisionLabel: revision}
+		for key, value := range statefulSet.Spec.Selector.MatchLabels {
+			pod.Labels[key] = value
+		}
+		pod.Status.Phase = v1.PodRunning
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)}}}}
+		return pod
+	}
+	fakeClient := fake.NewClientBuilder().WithObjects(statefulSet, runningPod("0", "v1", started), runningPod("1", "v1", started), runningPod("2", "v1", started), runningPod("3", "v1", started)).Build()
+
+	for _, ordinal := range []string{"0", "1", "2", "3"} {
+		broker := brokerv1beta1.BrokerStatus{Ordinal: ordinal, Role: brokerv1beta1.BrokerRoleBackup}
+		if ordinal == "0" || ordinal == "2" {
+			broker.Role = brokerv1beta1.BrokerRoleLive
+			broker.NodeID = "node-" + ordinal
+			broker.ClusterTopology = []brokerv1beta1.TopologyMemberStatus{{NodeID: broker.NodeID, Live: "live", Backup: "backup"}}
+		}
+		cr.Status.Brokers = append(cr.Status.Brokers, broker)
+	}
+	resource := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	defer delete(lastBrokersStatusPollMap, resource)
+
+	exists := func(ordinal string) bool {
+		return fakeClient.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-ss-" + ordinal, Namespace: "test"}, &v1.Pod{}) == nil
+	}
+	replace := func(ordinal string, startedAt time.Time) {
+		assert.NoError(t, fakeClient.Create(context.TODO(), runningPod(ordinal, "v2", startedAt)))
+	}
+
+	// the backups are replaced first, one at a time
+	assert.True(t, RollPairedPods(cr, fakeClient).RequeueAfter > 0)
+	assert.False(t, exists("3"))
+	assert.True(t, RollPairedPods(cr, fakeClient).RequeueAfter > 0)
+	assert.True(t, exists("1"))
+
+	backupStarted := time.Now().Add(-time.Minute)
+	replace("3", backupStarted)
+	RollPairedPods(cr, fakeClient)
+	assert.False(t, exists("1"))
+	replace("1", backupStarted)
+
+	// a live broker waits for a status of its updated backup
+	lastBrokersStatusPollMap[resource] = backupStarted.Add(-time.Second)
+	assert.True(t, RollPairedPods(cr, fakeClient).RequeueAfter > 0)
+	assert.True(t, exists("2"))
+
+	lastBrokersStatusPollMap[resource] = time.Now()
+	// nor while its backup is not announced
+	cr.Status.Brokers[0].ClusterTopology[0].Backup = ""
+	cr.Status.Brokers[2].ClusterTopology[0].Backup = ""
+	RollPairedPods(cr, fakeClient)
+	assert.True(t, exists("0"))
+	assert.True(t, exists("2"))
+
+	cr.Status.Brokers[0].ClusterTopology[0].Backup = "backup"
+	cr.Status.Brokers[2].ClusterTopology[0].Backup = "backup"
+	RollPairedPods(cr, fakeClient)
+	assert.False(t, exists("2"))
+	replace("2", time.Now())
+	RollPairedPods(cr, fakeClient)
+	assert.False(t, exists("0"))
+	replace("0", time.Now())
+
+	// all the pods are at the update revision
+	assert.Equal(t, ctrl.Result{}, RollPairedPods(cr, fakeClient))
+}
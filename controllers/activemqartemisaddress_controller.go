@@ -18,18 +18,25 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
 
 	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
 	ss "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/statefulsets"
 	mgmt "github.com/artemiscloud/activemq-artemis-operator/pkg/utils/artemis"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/channels"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia"
 	jc "github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia_client"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/lsrcrs"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/namer"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/selectors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -102,7 +109,10 @@ func (r *ActiveMQArtemisAddressReconciler) Reconcile(ctx context.Context, reques
 		SsTargetNameBuilders: createNameBuilders(instance),
 	}
 
-	if !lookupSucceeded {
+	var previousDeployment *AddressDeployment
+	if lookupSucceeded {
+		previousDeployment = &addressInstance
+	} else {
 		//check stored cr
 		if existingCr := lsrcrs.RetrieveLastSuccessfulReconciledCR(request.NamespacedName, "address", r.Client, getAddressLabels(instance)); existingCr != nil {
 			//compare resource version
@@ -112,10 +122,28 @@ func (r *ActiveMQArtemisAddressReconciler) Reconcile(ctx context.Context, reques
 				namespacedNameToAddressName[request.NamespacedName] = addressDeployment
 				return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
 			}
+			previousDeployment = getPreviousAddressDeployment(existingCr)
+		}
+	}
+	if condition := validateAddressMigration(previousDeployment, &instance.Spec); condition != nil {
+		// the previous spec stays deployed until the change is reverted
+		reqLogger.Info("Rejecting the address spec", "reason", condition.Message)
+		meta.SetStatusCondition(&instance.Status.Conditions, *condition)
+		if statusErr := updateAddressStatus(instance, r.Client); statusErr != nil {
+			return ctrl.Result{}, statusErr
 		}
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
 	}
+	migration := getAddressMigration(previousDeployment, &instance.Spec)
 
 	err = createQueue(&addressDeployment, request, r.Client, r.Scheme)
+	if migration != nil {
+		reqLogger.Info("Migrating from the previous address spec", "previous", migration.previousResource())
+		startAddressMigration(migration, &addressDeployment, err)
+		if err == nil {
+			err = migrateAddress(migration, &addressDeployment, request, r.Client, r.Scheme)
+		}
+	}
 	if nil == err {
 		namespacedNameToAddressName[request.NamespacedName] = addressDeployment
 		crstr, merr := common.ToJson(instance)
@@ -126,9 +154,13 @@ func (r *ActiveMQArtemisAddressReconciler) Reconcile(ctx context.Context, reques
 	} else {
 		reqLogger.Error(err, "failed to create address resource, request will be requeued")
 	}
-	if err != nil {
+	if statusErr := updateAddressStatus(&addressDeployment.AddressResource, r.Client); statusErr != nil && err == nil {
+		err = statusErr
+	}
+	if err != nil && !jolokia.IsPermanent(err) {
 		return ctrl.Result{}, err
 	}
+	// a permanent failure is reported in the status and retried at the resync period
 	return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
 }
 
@@ -181,12 +213,16 @@ func (r *ActiveMQArtemisAddressReconciler) SetupWithManager(mgr ctrl.Manager, ct
 }
 
 // This method deals with creating queues and addresses.
+// The address and queue deployed on every target broker are compared with the CR,
+// missing resources are created, drifted queue configuration is updated and the
+// outcome is recorded per broker pod in the CR status
 func createQueue(instance *AddressDeployment, request ctrl.Request, client client.Client, scheme *runtime.Scheme) error {
 
 	reqLogger := ctrl.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Creating ActiveMQArtemisAddress")
 
 	var err error = nil
+	var podStatus []brokerv1beta1.AddressPodStatus
 	artemisArray := getPodBrokers(instance, request, client, scheme)
 	if nil != artemisArray {
 		for _, a := range artemisArray {
@@ -194,14 +230,20 @@ func createQueue(instance *AddressDeployment, request ctrl.Request, client clien
 				reqLogger.Info("Creating ActiveMQArtemisAddress artemisArray had a nil!")
 				continue
 			}
-			err = createAddressResource(a, &instance.AddressResource)
-			if err != nil {
+			status := getAddressPodStatus(instance.AddressResource.Status.PodStatus, a.PodName)
+			condition, syncErr := syncAddressResource(a, &instance.AddressResource, meta.FindStatusCondition(status.Conditions, brokerv1beta1.AddressSynchronizedConditionType))
+			if syncErr != nil {
 				reqLogger.V(1).Info("Failed to create address resource", "failed broker", a)
-				continue
+				err = syncErr
 			}
+			meta.SetStatusCondition(&status.Conditions, condition)
+			podStatus = append(podStatus, status)
 		}
 	}
 
+	instance.AddressResource.Status.PodStatus = podStatus
+	meta.SetStatusCondition(&instance.AddressResource.Status.Conditions, getAddressSynchronizedCondition(podStatus))
+
 	if err == nil {
 		reqLogger.V(1).Info("Successfully created resources on all brokers", "size", len(artemisArray))
 	}
@@ -209,13 +251,210 @@ func createQueue(instance *AddressDeployment, request ctrl.Request, client clien
 	return err
 }
 
+// syncAddressResource brings the address and queue deployed on a broker in line with the CR.
+// Resources missing from a broker where they were previously created, or a queue whose
+// configuration no longer matches, are reported as drift once repaired
+func syncAddressResource(a *jc.JkInfo, addressRes *brokerv1beta1.ActiveMQArtemisAddress, previous *metav1.Condition) (metav1.Condition, error) {
+	condition := metav1.Condition{
+		Type:   brokerv1beta1.AddressSynchronizedConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: brokerv1beta1.AddressSynchronizedCreatedReason,
+	}
+	created := previous != nil && previous.Status == metav1.ConditionTrue
+
+	drift, err := detectAddressDrift(a.Artemis, addressRes)
+	if err == nil && (!created || len(drift) > 0) {
+		err = createAddressResource(a, addressRes)
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = addressSynchronizedFailedReason(err)
+		condition.Message = err.Error()
+		return condition, err
+	}
+
+	if created {
+		if len(drift) > 0 {
+			glog.Info("Repaired drifted address", "address", addressRes.Spec.AddressName, "broker", a.IP, "drift", drift)
+			condition.Reason = brokerv1beta1.AddressSynchronizedDriftedReason
+			condition.Message = "Repaired " + strings.Join(drift, ", ")
+		} else {
+			// in sync, keep how the resources last got synchronized
+			condition.Reason = previous.Reason
+			condition.Message = previous.Message
+		}
+	}
+	return condition, nil
+}
+
+// addressSynchronizedFailedReason tells from the failure of the management request whether
+// the broker refused the credentials or the address, or could not be reached
+func addressSynchronizedFailedReason(err error) string {
+	switch jolokia.KindOf(err) {
+	case jolokia.ErrorKindAuthFailed:
+		return brokerv1beta1.AddressSynchronizedUnauthorizedReason
+	case jolokia.ErrorKindInvalidArgument:
+		return brokerv1beta1.AddressSynchronizedInvalidReason
+	case jolokia.ErrorKindUnavailable:
+		return brokerv1beta1.AddressSynchronizedBrokerUnavailableReason
+	}
+	return brokerv1beta1.AddressSynchronizedFailedReason
+}
+
+// detectAddressDrift lists the differences between the address and queue deployed on the broker and the CR
+func detectAddressDrift(a *mgmt.Artemis, addressRes *brokerv1beta1.ActiveMQArtemisAddress) ([]string, error) {
+	addressName := addressRes.Spec.AddressName
+	exists, err := a.AddressExists(addressName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []string{"missing address " + addressName}, nil
+	}
+
+	if addressRes.Spec.QueueName == nil || *addressRes.Spec.QueueName == "" {
+		return nil, nil
+	}
+	queueName := *addressRes.Spec.QueueName
+
+	bindings, err := a.ListBindingsForAddress(addressName)
+	if err != nil {
+		return nil, err
+	}
+	if bindings == nil || !mgmt.IsQueueBound(bindings.Value, queueName) {
+		return []string{"missing queue " + queueName}, nil
+	}
+
+	routingType := defaultRoutingType
+	if addressRes.Spec.RoutingType != nil {
+		routingType = *addressRes.Spec.RoutingType
+	}
+	queueInfo, err := a.GetQueueInfo(addressName, queueName, routingType)
+	if err != nil {
+		return nil, err
+	}
+	if queueInfo == nil {
+		return []string{"missing " + strings.ToLower(routingType) + " queue " + queueName}, nil
+	}
+
+	return getQueueConfigurationDrift(addressRes.Spec.QueueConfiguration, queueInfo), nil
+}
+
+// getQueueConfigurationDrift compares the queue attributes that can be updated on a deployed queue
+func getQueueConfigurationDrift(config *brokerv1beta1.QueueConfigurationType, queueInfo *mgmt.QueueInfo) []string {
+	var drift []string
+	if config == nil {
+		return drift
+	}
+
+	compare := func(name string, expected interface{}, actual interface{}) {
+		if expected != actual {
+			drift = append(drift, fmt.Sprintf("%s (expected %v, found %v)", name, expected, actual))
+		}
+	}
+	if config.FilterString != nil {
+		compare("filterString", *config.FilterString, queueInfo.Filter)
+	}
+	if config.MaxConsumers != nil {
+		compare("maxConsumers", *config.MaxConsumers, queueInfo.MaxConsumers)
+	}
+	if config.Exclusive != nil {
+		compare("exclusive", *config.Exclusive, queueInfo.Exclusive)
+	}
+	if config.PurgeOnNoConsumers != nil {
+		compare("purgeOnNoConsumers", *config.PurgeOnNoConsumers, queueInfo.PurgeOnNoConsumers)
+	}
+	if config.GroupRebalance != nil {
+		compare("groupRebalance", *config.GroupRebalance, queueInfo.GroupRebalance)
+	}
+	if config.GroupBuckets != nil {
+		compare("groupBuckets", *config.GroupBuckets, queueInfo.GroupBuckets)
+	}
+	if config.ConsumersBeforeDispatch != nil {
+		compare("consumersBeforeDispatch", *config.ConsumersBeforeDispatch, queueInfo.ConsumersBeforeDispatch)
+	}
+	if config.DelayBeforeDispatch != nil {
+		compare("delayBeforeDispatch", *config.DelayBeforeDispatch, queueInfo.DelayBeforeDispatch)
+	}
+	return drift
+}
+
+func getAddressPodStatus(podStatus []brokerv1beta1.AddressPodStatus, podName string) brokerv1beta1.AddressPodStatus {
+	for _, status := range podStatus {
+		if status.PodName == podName {
+			return *status.DeepCopy()
+		}
+	}
+	return brokerv1beta1.AddressPodStatus{PodName: podName}
+}
+
+func getAddressSynchronizedCondition(podStatus []brokerv1beta1.AddressPodStatus) metav1.Condition {
+	if len(podStatus) == 0 {
+		return metav1.Condition{
+			Type:    brokerv1beta1.AddressSynchronizedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.AddressSynchronizedNoBrokersReason,
+			Message: "No target broker pods found",
+		}
+	}
+
+	var failed, drifted []string
+	for _, status := range podStatus {
+		condition := meta.FindStatusCondition(status.Conditions, brokerv1beta1.AddressSynchronizedConditionType)
+		if condition == nil || condition.Status != metav1.ConditionTrue {
+			failed = append(failed, status.PodName)
+		} else if condition.Reason == brokerv1beta1.AddressSynchronizedDriftedReason {
+			drifted = append(drifted, status.PodName)
+		}
+	}
+
+	if len(failed) > 0 {
+		return metav1.Condition{
+			Type:    brokerv1beta1.AddressSynchronizedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.AddressSynchronizedFailedReason,
+			Message: "Failed to synchronize on " + strings.Join(failed, ", "),
+		}
+	}
+	if len(drifted) > 0 {
+		return metav1.Condition{
+			Type:    brokerv1beta1.AddressSynchronizedConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  brokerv1beta1.AddressSynchronizedDriftedReason,
+			Message: "Repaired drift on " + strings.Join(drifted, ", "),
+		}
+	}
+	return metav1.Condition{
+		Type:   brokerv1beta1.AddressSynchronizedConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: brokerv1beta1.AddressSynchronizedCreatedReason,
+	}
+}
+
+func updateAddressStatus(cr *brokerv1beta1.ActiveMQArtemisAddress, client client.Client) error {
+
+	common.SetReadyCondition(&cr.Status.Conditions)
+
+	current := &brokerv1beta1.ActiveMQArtemisAddress{}
+	namespacedName := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	if err := client.Get(context.TODO(), namespacedName, current); err != nil {
+		glog.Error(err, "unable to retrieve current resource", "ActiveMQArtemisAddress", namespacedName)
+		return err
+	}
+
+	if reflect.DeepEqual(current.Status, cr.Status) {
+		return nil
+	}
+	return resources.UpdateStatus(client, cr)
+}
+
 func createAddressResource(a *jc.JkInfo, addressRes *brokerv1beta1.ActiveMQArtemisAddress) error {
 	//Now checking if create queue or address
 	if addressRes.Spec.QueueName == nil || *addressRes.Spec.QueueName == "" {
 		//create address
-		response, err := a.Artemis.CreateAddress(addressRes.Spec.AddressName, *addressRes.Spec.RoutingType)
+		_, err := a.Artemis.CreateAddress(addressRes.Spec.AddressName, *addressRes.Spec.RoutingType)
 		if nil != err {
-			if mgmt.GetCreationError(response) == mgmt.ADDRESS_ALREADY_EXISTS {
+			if jolokia.IsAlreadyExists(err) {
 				glog.Info("Address already exists, no retry", "address", addressRes.Spec.AddressName)
 				return nil
 			} else {
@@ -228,8 +467,8 @@ func createAddressResource(a *jc.JkInfo, addressRes *brokerv1beta1.ActiveMQArtem
 	} else {
 		glog.Info("Queue name is not empty so create queue", "name", *addressRes.Spec.QueueName, "broker", a.IP)
 		//first make sure address exists
-		response, err := a.Artemis.CreateAddress(addressRes.Spec.AddressName, *addressRes.Spec.RoutingType)
-		if nil != err && mgmt.GetCreationError(response) != mgmt.ADDRESS_ALREADY_EXISTS {
+		_, err := a.Artemis.CreateAddress(addressRes.Spec.AddressName, *addressRes.Spec.RoutingType)
+		if nil != err && !jolokia.IsAlreadyExists(err) {
 			glog.Error(err, "Error creating ActiveMQArtemisAddress", "address", addressRes.Spec.AddressName)
 			return err
 		}
@@ -255,9 +494,9 @@ func createAddressResource(a *jc.JkInfo, addressRes *brokerv1beta1.ActiveMQArtem
 			//here we return nil as no point to requeue reconcile again
 			return nil
 		}
-		respData, err := a.Artemis.CreateQueueFromConfig(queueCfg, ignoreIfExists)
+		_, err = a.Artemis.CreateQueueFromConfig(queueCfg, ignoreIfExists)
 		if nil != err {
-			if mgmt.GetCreationError(respData) == mgmt.QUEUE_ALREADY_EXISTS {
+			if jolokia.IsAlreadyExists(err) {
 				glog.Info("The queue already exists, updating", "queue", queueCfg)
 				respData, err := a.Artemis.UpdateQueue(queueCfg)
 				if err != nil {
@@ -326,7 +565,10 @@ func deleteQueue(instance *AddressDeployment, request ctrl.Request, client clien
 			if queueName == "" {
 				//delete address
 				_, err = a.Artemis.DeleteAddress(addressName)
-				if nil != err {
+				if jolokia.IsNotFound(err) {
+					reqLogger.Info("ActiveMQArtemisAddress already removed for address " + addressName)
+					err = nil
+				} else if nil != err {
 					reqLogger.Error(err, "Deleting ActiveMQArtemisAddress error", "address", addressName)
 					break
 				}
@@ -334,6 +576,10 @@ func deleteQueue(instance *AddressDeployment, request ctrl.Request, client clien
 			} else {
 				//delete queues
 				_, err = a.Artemis.DeleteQueue(queueName)
+				if jolokia.IsNotFound(err) {
+					reqLogger.Info("ActiveMQArtemisAddress already removed for queue " + queueName)
+					err = nil
+				}
 				if nil != err {
 					reqLogger.Error(err, "Deleting ActiveMQArtemisAddress error for queue "+queueName)
 					break
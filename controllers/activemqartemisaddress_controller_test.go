@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -129,6 +130,62 @@ var _ = Describe("Address controller tests", func() {
 		}
 	})
 
+	Context("address migration", Label("address-migration"), func() {
+		if os.Getenv("USE_EXISTING_CLUSTER") == "true" {
+			It("keeps the queue when the address changes but the queue name does not", func() {
+				queueName := "migratedqueue"
+				addressName := "migratedaddress"
+				anycastType := "ANYCAST"
+
+				By("deploy a broker cr")
+				brokerCr, createdBrokerCr := DeployCustomBroker(defaultNamespace, nil)
+
+				By("deploy an address cr")
+				addressCr, createdAddressCr := DeployCustomAddress(defaultNamespace, func(candidate *brokerv1beta1.ActiveMQArtemisAddress) {
+					candidate.Spec.AddressName = addressName
+					candidate.Spec.QueueName = &queueName
+					candidate.Spec.RoutingType = &anycastType
+					candidate.Spec.RemoveFromBrokerOnDelete = true
+				})
+
+				podName := namer.CrToSS(brokerCr.Name) + "-0"
+				CheckQueueExistInPod(brokerCr.Name, podName, queueName, defaultNamespace)
+
+				By("moving the address and keeping the queue name")
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: addressCr.Name, Namespace: defaultNamespace}, createdAddressCr)).Should(Succeed())
+					createdAddressCr.Spec.AddressName = addressName + "-v2"
+					g.Expect(k8sClient.Update(ctx, createdAddressCr)).Should(Succeed())
+				}, timeout, interval).Should(Succeed())
+
+				By("verify the change is rejected")
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: addressCr.Name, Namespace: defaultNamespace}, createdAddressCr)).Should(Succeed())
+					condition := meta.FindStatusCondition(createdAddressCr.Status.Conditions, brokerv1beta1.AddressSynchronizedConditionType)
+					g.Expect(condition).ShouldNot(BeNil())
+					g.Expect(condition.Reason).Should(Equal(brokerv1beta1.AddressSynchronizedInvalidReason))
+				}, existingClusterTimeout, existingClusterInterval).Should(Succeed())
+
+				By("verify the queue is kept on the previous address")
+				CheckQueueExistInPod(brokerCr.Name, podName, queueName, defaultNamespace)
+				CheckQueueAttribute(brokerCr.Name, podName, defaultNamespace, queueName, addressName, "anycast", "ConfigurationManaged", "true")
+
+				//cleanup
+				Expect(k8sClient.Delete(ctx, createdBrokerCr)).Should(Succeed())
+				Eventually(func() bool {
+					return checkCrdDeleted(brokerCr.Name, defaultNamespace, createdBrokerCr)
+				}, existingClusterTimeout, existingClusterInterval).Should(BeTrue())
+
+				Expect(k8sClient.Delete(ctx, createdAddressCr)).Should(Succeed())
+				Eventually(func() bool {
+					return checkCrdDeleted(addressCr.Name, defaultNamespace, createdAddressCr)
+				}, existingClusterTimeout, existingClusterInterval).Should(BeTrue())
+			})
+		} else {
+			fmt.Println("Test skipped as it requires an existing cluster")
+		}
+	})
+
 	Context("broker with address custom resources", Label("broker-address-res"), func() {
 		if os.Getenv("USE_EXISTING_CLUSTER") == "true" {
 			queueName := "myqueue"
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	mgmt "github.com/artemiscloud/activemq-artemis-operator/pkg/utils/artemis"
+	jc "github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia_client"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/lsrcrs"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var mlog = ctrl.Log.WithName("addressmigration_activemqartemisaddress")
+
+// AddressMigration is the plan to move from the address and queue applied
+// for the previous spec of an ActiveMQArtemisAddress to the current one
+type AddressMigration struct {
+	Previous AddressDeployment
+
+	// the queue left behind, empty when the previous spec had no queue
+	OldQueueName   string
+	OldAddressName string
+	OldRoutingType string
+
+	NewQueueName string
+
+	MoveMessages     bool
+	RemovePrevious   bool
+	RemoveOldAddress bool
+}
+
+// getPreviousAddressDeployment returns the deployment of an address from the last successful
+// reconciled CR, used after an operator restart
+func getPreviousAddressDeployment(stored *lsrcrs.LastSuccessfulReconciledCR) *AddressDeployment {
+	if stored == nil || stored.CR == "" {
+		return nil
+	}
+
+	previous := brokerv1beta1.ActiveMQArtemisAddress{}
+	if err := json.Unmarshal([]byte(stored.CR), &previous); err != nil {
+		mlog.Error(err, "failed to unmarshal the last successful reconciled address cr")
+		return nil
+	}
+	return &AddressDeployment{
+		AddressResource:      previous,
+		SsTargetNameBuilders: createNameBuilders(&previous),
+	}
+}
+
+// getAddressMigration computes the plan to clean up what the previous spec created on the
+// brokers, nil is returned when the current spec still applies to the same address and queue
+func getAddressMigration(previous *AddressDeployment, current *brokerv1beta1.ActiveMQArtemisAddressSpec) *AddressMigration {
+	if previous == nil {
+		return nil
+	}
+	previousSpec := &previous.AddressResource.Spec
+
+	oldQueueName := getQueueName(previousSpec)
+	newQueueName := getQueueName(current)
+	addressChanged := previousSpec.AddressName != current.AddressName
+	// a queue that keeps its name is the queue of the current spec, it is never removed
+	queueChanged := oldQueueName != "" && oldQueueName != newQueueName
+
+	if !addressChanged && !queueChanged {
+		return nil
+	}
+
+	migration := &AddressMigration{
+		Previous:         *previous,
+		OldAddressName:   previousSpec.AddressName,
+		OldRoutingType:   getRoutingType(previousSpec),
+		NewQueueName:     newQueueName,
+		RemovePrevious:   current.RemoveFromBrokerOnDelete,
+		RemoveOldAddress: addressChanged,
+	}
+	if queueChanged {
+		migration.OldQueueName = oldQueueName
+		migration.MoveMessages = current.MoveMessagesOnChange && newQueueName != "" && newQueueName != oldQueueName
+	}
+	return migration
+}
+
+// validateAddressMigration rejects a routing type change that keeps the address and a new address
+// that keeps the queue, the brokers can not change the routing type of a deployed address and its
+// queues, and a queue name is unique on a broker so the queue can not be created on the new address
+func validateAddressMigration(previous *AddressDeployment, current *brokerv1beta1.ActiveMQArtemisAddressSpec) *metav1.Condition {
+	if previous == nil {
+		return nil
+	}
+	previousSpec := &previous.AddressResource.Spec
+	if previousSpec.AddressName != current.AddressName {
+		queueName := getQueueName(previousSpec)
+		if queueName == "" || queueName != getQueueName(current) {
+			return nil
+		}
+		return &metav1.Condition{
+			Type:    brokerv1beta1.AddressSynchronizedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.AddressSynchronizedInvalidReason,
+			Message: "The queue " + queueName + " can not move from the address " + previousSpec.AddressName + " to " + current.AddressName + ", change the queueName to migrate to a new queue",
+		}
+	}
+	oldRoutingType := getRoutingType(previousSpec)
+	newRoutingType := getRoutingType(current)
+	if oldRoutingType == newRoutingType {
+		return nil
+	}
+	return &metav1.Condition{
+		Type:    brokerv1beta1.AddressSynchronizedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  brokerv1beta1.AddressSynchronizedInvalidReason,
+		Message: "The routingType of the address " + current.AddressName + " can not change from " + oldRoutingType + " to " + newRoutingType + ", change the addressName to migrate to a new address",
+	}
+}
+
+func getRoutingType(spec *brokerv1beta1.ActiveMQArtemisAddressSpec) string {
+	if spec.RoutingType == nil || *spec.RoutingType == "" {
+		return defaultRoutingType
+	}
+	return strings.ToUpper(*spec.RoutingType)
+}
+
+func getQueueName(spec *brokerv1beta1.ActiveMQArtemisAddressSpec) string {
+	if spec.QueueName == nil {
+		return ""
+	}
+	return *spec.QueueName
+}
+
+func (m *AddressMigration) previousResource() string {
+	if m.OldQueueName != "" {
+		return "queue " + m.OldAddressName + "/" + m.OldQueueName
+	}
+	return "address " + m.OldAddressName
+}
+
+// migrateAddress moves the messages out of the previous queue and removes the previous
+// queue and address from the brokers, each step is recorded as a condition on the CR.
+// It is only called once the resources for the current spec are created
+func migrateAddress(migration *AddressMigration, instance *AddressDeployment, request ctrl.Request, client client.Client, scheme *runtime.Scheme) error {
+	reqLogger := mlog.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	conditions := &instance.AddressResource.Status.Conditions
+
+	// the previous spec may have targeted other brokers
+	artemisArray := getPodBrokers(&migration.Previous, request, client, scheme)
+
+	moved := metav1.Condition{
+		Type:    brokerv1beta1.AddressMigrationMessagesMovedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  brokerv1beta1.AddressMigrationSkippedReason,
+		Message: "Moving messages is not enabled or not applicable",
+	}
+	if migration.MoveMessages {
+		moved.Reason = brokerv1beta1.AddressMigrationSucceededReason
+		moved.Message = "Moved messages from " + migration.previousResource() + " to queue " + migration.NewQueueName
+		for _, a := range artemisArray {
+			bound, err := isOldQueueBound(a.Artemis, migration)
+			if err != nil {
+				setMigrationFailed(conditions, moved, err)
+				return err
+			}
+			if !bound {
+				continue
+			}
+			if _, err := a.Artemis.MoveMessages(migration.OldAddressName, migration.OldQueueName, migration.OldRoutingType, migration.NewQueueName); err != nil {
+				reqLogger.Error(err, "Failed to move messages", "queue", migration.OldQueueName, "broker", a.IP)
+				setMigrationFailed(conditions, moved, err)
+				return err
+			}
+		}
+	}
+	meta.SetStatusCondition(conditions, moved)
+
+	removed := metav1.Condition{
+		Type:    brokerv1beta1.AddressMigrationPreviousRemovedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  brokerv1beta1.AddressMigrationSkippedReason,
+		Message: "RemoveFromBrokerOnDelete is false, " + migration.previousResource() + " is kept on the brokers",
+	}
+	if migration.RemovePrevious {
+		removed.Reason = brokerv1beta1.AddressMigrationSucceededReason
+		removed.Message = "Removed " + migration.previousResource()
+		if err := removePreviousAddressResources(migration, artemisArray); err != nil {
+			reqLogger.Error(err, "Failed to remove previous resources", "previous", migration.previousResource())
+			setMigrationFailed(conditions, removed, err)
+			return err
+		}
+	}
+	meta.SetStatusCondition(conditions, removed)
+
+	return nil
+}
+
+func removePreviousAddressResources(migration *AddressMigration, artemisArray []*jc.JkInfo) error {
+	addressRetry := &AddressRetry{
+		address: migration.OldAddressName,
+		artemis: make([]*mgmt.Artemis, 0),
+	}
+	for _, a := range artemisArray {
+		if migration.OldQueueName != "" {
+			bound, err := isOldQueueBound(a.Artemis, migration)
+			if err != nil {
+				return err
+			}
+			if bound {
+				if _, err = a.Artemis.DeleteQueue(migration.OldQueueName); err != nil {
+					return err
+				}
+			}
+		}
+		if migration.RemoveOldAddress {
+			addressRetry.addToDelete(a.Artemis)
+		}
+	}
+	// the old address is only removed once it has no bindings left
+	addressRetry.safeDelete()
+	return nil
+}
+
+func isOldQueueBound(a *mgmt.Artemis, migration *AddressMigration) (bool, error) {
+	bindings, err := a.ListBindingsForAddress(migration.OldAddressName)
+	if err != nil {
+		return false, err
+	}
+	return bindings != nil && mgmt.IsQueueBound(bindings.Value, migration.OldQueueName), nil
+}
+
+func setMigrationFailed(conditions *[]metav1.Condition, step metav1.Condition, err error) {
+	step.Status = metav1.ConditionFalse
+	step.Reason = brokerv1beta1.AddressMigrationFailedReason
+	step.Message = err.Error()
+	meta.SetStatusCondition(conditions, step)
+}
+
+// startAddressMigration records the migration steps as pending along with the outcome of
+// creating the resources for the current spec
+func startAddressMigration(migration *AddressMigration, instance *AddressDeployment, createErr error) {
+	conditions := &instance.AddressResource.Status.Conditions
+
+	created := metav1.Condition{
+		Type:    brokerv1beta1.AddressMigrationCreatedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  brokerv1beta1.AddressMigrationSucceededReason,
+		Message: "Created the resources replacing " + migration.previousResource(),
+	}
+	if createErr != nil {
+		setMigrationFailed(conditions, created, createErr)
+	} else {
+		meta.SetStatusCondition(conditions, created)
+	}
+
+	for _, step := range []string{brokerv1beta1.AddressMigrationMessagesMovedConditionType, brokerv1beta1.AddressMigrationPreviousRemovedConditionType} {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:    step,
+			Status:  metav1.ConditionUnknown,
+			Reason:  brokerv1beta1.AddressMigrationPendingReason,
+			Message: "Waiting for the resources replacing " + migration.previousResource() + " to be created",
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia_client"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// the type and the provider entries of the service binding specification
+	clientBindingType     = "artemis"
+	clientBindingProvider = "activemq-artemis-operator"
+
+	defaultClientBindingProtocol = "core"
+)
+
+func clientBindingSecretName(customResource *brokerv1beta1.ActiveMQArtemis, bindingName string) string {
+	return customResource.Name + "-" + bindingName + "-binding"
+}
+
+// ProcessClientBindings writes a secret with the connection details of an acceptor for each client
+// binding, in the layout of the service binding specification
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessClientBindings(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, client rtclient.Client) {
+	reqLogger := ctrl.Log.WithValues("ActiveMQArtemis Name", customResource.Name)
+
+	for _, binding := range customResource.Spec.ClientBindings {
+		acceptor := findAcceptor(customResource, binding.Acceptor)
+		if acceptor == nil {
+			continue
+		}
+		secretName := clientBindingSecretName(customResource, binding.Name)
+		deployed := reconciler.cloneOfDeployed(reflect.TypeOf(corev1.Secret{}), secretName)
+
+		acceptorStatus, _ := newAcceptorStatus(customResource, client, *acceptor)
+		data := clientBindingData(acceptorStatus, binding)
+		if data == nil {
+			if deployed != nil {
+				// keep the last address until the new one is assigned
+				reconciler.trackDesired(deployed)
+			}
+			reqLogger.V(1).Info("the external address of the client binding is not assigned yet", "binding", binding.Name)
+			continue
+		}
+
+		username, password := reconciler.clientBindingCredentials(customResource, namer, client)
+		data["username"] = []byte(username)
+		data["password"] = []byte(password)
+		if ca := clientBindingCA(customResource, *acceptor, binding, client); len(ca) > 0 {
+			data[jolokia_client.TrustSecretCAKey] = ca
+		}
+
+		var secret *corev1.Secret
+		if deployed != nil {
+			secret = deployed.(*corev1.Secret)
+			secret.Labels = namer.LabelBuilder.Labels()
+		} else {
+			secret = secrets.NewSecret(types.NamespacedName{Name: secretName, Namespace: customResource.Namespace}, secretName, nil, namer.LabelBuilder.Labels())
+		}
+		secret.StringData = nil
+		secret.Data = data
+		reconciler.trackDesired(secret)
+	}
+}
+
+// clientBindingData returns the address entries of a binding, nil while the external address is
+// not assigned. The load balanced service is preferred over the service of the first broker pod
+func clientBindingData(acceptorStatus brokerv1beta1.AcceptorStatus, binding brokerv1beta1.ClientBindingType) map[string][]byte {
+	endpoint := acceptorStatus.LoadBalanced
+	if endpoint == nil && len(acceptorStatus.Pods) > 0 {
+		endpoint = &acceptorStatus.Pods[0]
+	}
+	if endpoint == nil {
+		return nil
+	}
+
+	protocol := clientBindingProtocol(acceptorStatus, binding)
+	host, port, urls, sslEnabled := endpoint.Host, acceptorStatus.Port, endpoint.InternalURLs, acceptorStatus.SSLEnabled
+	if binding.External {
+		host, port, urls, sslEnabled = endpoint.ExternalHost, endpoint.ExternalPort, endpoint.ExternalURLs, acceptorStatus.ExternalSSLEnabled
+	}
+	if urls[protocol] == "" {
+		return nil
+	}
+
+	return map[string][]byte{
+		"type":       []byte(clientBindingType),
+		"provider":   []byte(clientBindingProvider),
+		"host":       []byte(host),
+		"port":       []byte(strconv.Itoa(int(port))),
+		"protocol":   []byte(protocol),
+		"uri":        []byte(urls[protocol]),
+		"sslEnabled": []byte(strconv.FormatBool(sslEnabled)),
+	}
+}
+
+// clientBindingProtocol is the protocol of the binding, core by default when the acceptor accepts
+// it, else the first protocol with a connection url
+func clientBindingProtocol(acceptorStatus brokerv1beta1.AcceptorStatus, binding brokerv1beta1.ClientBindingType) string {
+	if binding.Protocol != "" {
+		return binding.Protocol
+	}
+	urls := connectionURLs("", 0, acceptorStatus.Protocols, false)
+	if _, found := urls[defaultClientBindingProtocol]; found {
+		return defaultClientBindingProtocol
+	}
+	for _, protocol := range acceptorStatus.Protocols {
+		if _, found := urls[strings.ToLower(protocol)]; found {
+			return strings.ToLower(protocol)
+		}
+	}
+	return defaultClientBindingProtocol
+}
+
+// clientBindingCredentials are the user and the password of the credentials secret, the ones
+// requested in this reconcile when the operator owns the secret
+func (reconciler *ActiveMQArtemisReconcilerImpl) clientBindingCredentials(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, client rtclient.Client) (string, string) {
+	secretName := namer.SecretsCredentialsNameBuilder.Name()
+	for _, obj := range reconciler.requestedResources {
+		if secret, ok := obj.(*corev1.Secret); ok && secret.Name == secretName {
+			return secretValue(secret, "AMQ_USER"), secretValue(secret, "AMQ_PASSWORD")
+		}
+	}
+
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: customResource.Namespace}, secret); err != nil {
+		clog.Error(err, "failed to retrieve the credentials secret", "secret", secretName)
+		return "", ""
+	}
+	return secretValue(secret, "AMQ_USER"), secretValue(secret, "AMQ_PASSWORD")
+}
+
+func secretValue(secret *corev1.Secret, key string) string {
+	if value, found := secret.StringData[key]; found {
+		return value
+	}
+	return string(secret.Data[key])
+}
+
+// clientBindingCA is the ca certificate of the ssl secret of the acceptor, cert-manager adds it, or
+// of the tls secret of the exposure for an external binding to an ingress or route that ends the
+// tls connections
+func clientBindingCA(customResource *brokerv1beta1.ActiveMQArtemis, acceptor brokerv1beta1.AcceptorType, binding brokerv1beta1.ClientBindingType, client rtclient.Client) []byte {
+	secretName := ""
+	switch termination := tlsTermination(acceptor.SSLEnabled, acceptor.Exposure); {
+	case binding.External && (termination == brokerv1beta1.TLSTerminationEdge || termination == brokerv1beta1.TLSTerminationReencrypt):
+		secretName = acceptor.Exposure.TLSSecret
+	case acceptor.SSLEnabled:
+		secretName = acceptorSSLSecretName(customResource, acceptor)
+	}
+	if secretName == "" {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: customResource.Namespace}, secret); err != nil {
+		return nil
+	}
+	return secret.Data[jolokia_client.TrustSecretCAKey]
+}
+
+func findAcceptor(customResource *brokerv1beta1.ActiveMQArtemis, name string) *brokerv1beta1.AcceptorType {
+	for index := range customResource.Spec.Acceptors {
+		if customResource.Spec.Acceptors[index].Name == name {
+			return &customResource.Spec.Acceptors[index]
+		}
+	}
+	return nil
+}
+
+func validateClientBindings(customResource *brokerv1beta1.ActiveMQArtemis) *metav1.Condition {
+	names := map[string]bool{}
+	for index, binding := range customResource.Spec.ClientBindings {
+		var reason string
+		acceptor := findAcceptor(customResource, binding.Acceptor)
+		switch {
+		case names[binding.Name]:
+			reason = fmt.Sprintf("duplicate name %v", binding.Name)
+		case acceptor == nil:
+			reason = fmt.Sprintf("acceptor %v not found", binding.Acceptor)
+		case binding.External && !acceptor.Expose:
+			reason = fmt.Sprintf("external requires the acceptor %v to be exposed", binding.Acceptor)
+		case binding.Protocol != "" && connectionURLs("", 0, acceptorProtocols(*acceptor), false)[binding.Protocol] == "":
+			reason = fmt.Sprintf("the acceptor %v does not accept %v", binding.Acceptor, binding.Protocol)
+		}
+		names[binding.Name] = true
+		if reason != "" {
+			return &metav1.Condition{
+				Type:    brokerv1beta1.ValidConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  brokerv1beta1.ValidConditionInvalidBindingReason,
+				Message: fmt.Sprintf(".Spec.ClientBindings[%d] %v", index, reason),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestProcessClientBindings(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			IngressDomain: "example.com",
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, Protocols: "amqp,core", SSLEnabled: true, Expose: true, ServiceMode: brokerv1beta1.ServiceModeLoadBalanced},
+				{Name: "mqtt", Port: 1883, Protocols: "mqtt"},
+			},
+			ClientBindings: []brokerv1beta1.ClientBindingType{
+				{Name: "orders", Acceptor: "amqps", Protocol: "amqp", External: true},
+				{Name: "sensors", Acceptor: "mqtt"},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+
+	credentials := &v1.Secret{}
+	credentials.Name = "ex-aao-credentials-secret"
+	credentials.Namespace = "test"
+	credentials.Data = map[string][]byte{"AMQ_USER": []byte("admin"), "AMQ_PASSWORD": []byte("secret")}
+	sslSecret := &v1.Secret{}
+	sslSecret.Name = "ex-aao-amqps-secret"
+	sslSecret.Namespace = "test"
+	sslSecret.Data = map[string][]byte{"ca.crt": []byte("ca")}
+	client := fake.NewClientBuilder().WithObjects(credentials, sslSecret).Build()
+
+	// the ingress of the external binding is not created yet
+	reconciler := ActiveMQArtemisReconcilerImpl{}
+	reconciler.ProcessClientBindings(cr, *namer, client)
+	if assert.Len(t, reconciler.requestedResources, 1) {
+		sensors := reconciler.requestedResources[0].(*v1.Secret)
+		assert.Equal(t, "ex-aao-sensors-binding", sensors.Name)
+		assert.Equal(t, map[string][]byte{
+			"type":       []byte("artemis"),
+			"provider":   []byte("activemq-artemis-operator"),
+			"host":       []byte("ex-aao-mqtt-0-svc.test.svc.cluster.local"),
+			"port":       []byte("1883"),
+			"protocol":   []byte("mqtt"),
+			"uri":        []byte("tcp://ex-aao-mqtt-0-svc.test.svc.cluster.local:1883"),
+			"sslEnabled": []byte("false"),
+			"username":   []byte("admin"),
+			"password":   []byte("secret"),
+		}, sensors.Data)
+		assert.Equal(t, namer.LabelBuilder.Labels(), sensors.Labels)
+	}
+
+	// the credentials requested in this reconcile are the ones of the binding
+	reconciler = ActiveMQArtemisReconcilerImpl{}
+	rotated := credentials.DeepCopy()
+	rotated.Data = nil
+	rotated.StringData = map[string]string{"AMQ_USER": "admin", "AMQ_PASSWORD": "rotated"}
+	reconciler.trackDesired(rotated)
+	reconciler.configureAcceptorsExposure(cr, *namer, client, nil)
+	for _, obj := range reconciler.requestedResources {
+		if obj.GetName() == "ex-aao-amqps-svc-ing" {
+			assert.NoError(t, client.Create(context.TODO(), obj))
+		}
+	}
+	reconciler.ProcessClientBindings(cr, *namer, client)
+
+	var orders *v1.Secret
+	for _, obj := range reconciler.requestedResources {
+		if obj.GetName() == "ex-aao-orders-binding" {
+			orders = obj.(*v1.Secret)
+		}
+	}
+	if assert.NotNil(t, orders) {
+		assert.Equal(t, "ex-aao-amqps-svc-ing.example.com", string(orders.Data["host"]))
+		assert.Equal(t, "443", string(orders.Data["port"]))
+		assert.Equal(t, "amqps://ex-aao-amqps-svc-ing.example.com:443", string(orders.Data["uri"]))
+		assert.Equal(t, "true", string(orders.Data["sslEnabled"]))
+		assert.Equal(t, "rotated", string(orders.Data["password"]))
+		assert.Equal(t, "ca", string(orders.Data["ca.crt"]))
+	}
+
+	// a deployed binding is kept while its external address is not assigned
+	deployed := orders.DeepCopy()
+	reconciler = ActiveMQArtemisReconcilerImpl{deployed: map[reflect.Type][]rtclient.Object{reflect.TypeOf(v1.Secret{}): {deployed}}}
+	cr.Spec.Acceptors[0].ExposeMode = brokerv1beta1.ExposeModeLoadBalancer
+	reconciler.ProcessClientBindings(cr, *namer, client)
+	found := false
+	for _, obj := range reconciler.requestedResources {
+		if obj.GetName() == "ex-aao-orders-binding" {
+			found = true
+			assert.Equal(t, deployed.Data, obj.(*v1.Secret).Data)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestValidateClientBindings(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, Protocols: "amqp,core", SSLEnabled: true, Expose: true, ServiceMode: brokerv1beta1.ServiceModeLoadBalanced},
+				{Name: "mqtt", Port: 1883, Protocols: "mqtt"},
+			},
+			ClientBindings: []brokerv1beta1.ClientBindingType{
+				{Name: "orders", Acceptor: "amqps", Protocol: "amqp", External: true},
+				{Name: "sensors", Acceptor: "mqtt"},
+			},
+		},
+	}
+	assert.Nil(t, validateClientBindings(cr))
+
+	cr.Spec.ClientBindings[1].Protocol = "amqp"
+	condition := validateClientBindings(cr)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, brokerv1beta1.ValidConditionInvalidBindingReason, condition.Reason)
+		assert.Equal(t, ".Spec.ClientBindings[1] the acceptor mqtt does not accept amqp", condition.Message)
+	}
+
+	cr = &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, Protocols: "amqp,core", SSLEnabled: true, Expose: true, ServiceMode: brokerv1beta1.ServiceModeLoadBalanced},
+				{Name: "mqtt", Port: 1883, Protocols: "mqtt"},
+			},
+			ClientBindings: []brokerv1beta1.ClientBindingType{
+				{Name: "orders", Acceptor: "amqps", Protocol: "amqp", External: true},
+				{Name: "sensors", Acceptor: "mqtt", External: true},
+			},
+		},
+	}
+	condition = validateClientBindings(cr)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, ".Spec.ClientBindings[1] external requires the acceptor mqtt to be exposed", condition.Message)
+	}
+
+	cr = &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, Protocols: "amqp,core", SSLEnabled: true, Expose: true, ServiceMode: brokerv1beta1.ServiceModeLoadBalanced},
+				{Name: "mqtt", Port: 1883, Protocols: "mqtt"},
+			},
+			ClientBindings: []brokerv1beta1.ClientBindingType{
+				{Name: "orders", Acceptor: "amqps", Protocol: "amqp", External: true},
+				{Name: "sensors", Acceptor: "stomp"},
+			},
+		},
+	}
+	condition = validateClientBindings(cr)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, ".Spec.ClientBindings[1] acceptor stomp not found", condition.Message)
+	}
+
+	cr = &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, Protocols: "amqp,core", SSLEnabled: true, Expose: true, ServiceMode: brokerv1beta1.ServiceModeLoadBalanced},
+				{Name: "mqtt", Port: 1883, Protocols: "mqtt"},
+			},
+			ClientBindings: []brokerv1beta1.ClientBindingType{
+				{Name: "orders", Acceptor: "amqps", Protocol: "amqp", External: true},
+				{Name: "orders", Acceptor: "mqtt"},
+			},
+		},
+	}
+	condition = validateClientBindings(cr)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, ".Spec.ClientBindings[1] duplicate name orders", condition.Message)
+	}
+}
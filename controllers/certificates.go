@@ -0,0 +1,629 @@
+package controllers
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/adler32"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/certificates"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
+	ss "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/statefulsets"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia_client"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/random"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	keyStoreSecretSuffix = "-keystore"
+	pemConfigKey         = "tls.pemcfg"
+
+	// the brokers roll when a certificate they use is renewed
+	certificatesCheckSumEnvVar = "TRIGGERED_CERTIFICATES_ROLL_COUNT"
+)
+
+// certificateRequest is a certificate requested from cert-manager for an ssl secret, the
+// acceptors, connectors and console that share the secret share the certificate
+type certificateRequest struct {
+	secretName  string
+	certManager *brokerv1beta1.CertManagerType
+	dnsNames    []string
+	pkcs12      bool
+	// the connectors and the console only load a renewed certificate when the broker restarts
+	restartRequired bool
+}
+
+func certificateRequests(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers) []*certificateRequest {
+	var requests []*certificateRequest
+	bySecret := map[string]*certificateRequest{}
+
+	add := func(secretName string, certManager *brokerv1beta1.CertManagerType, dnsNames []string, pkcs12 bool, restartRequired bool) {
+		request, found := bySecret[secretName]
+		if !found {
+			request = &certificateRequest{secretName: secretName, certManager: certManager}
+			bySecret[secretName] = request
+			requests = append(requests, request)
+		}
+		request.dnsNames = appendUnique(request.dnsNames, dnsNames...)
+		request.dnsNames = appendUnique(request.dnsNames, certManager.DNSNames...)
+		request.pkcs12 = request.pkcs12 || pkcs12
+		request.restartRequired = request.restartRequired || restartRequired
+	}
+
+	podDNSNames := brokerPodDNSNames(customResource, namer)
+	for _, acceptor := range customResource.Spec.Acceptors {
+		if !acceptor.SSLEnabled || acceptor.CertManager == nil {
+			continue
+		}
+		secretName := customResource.Name + "-" + acceptor.Name + "-secret"
+		if acceptor.SSLSecret != "" {
+			secretName = acceptor.SSLSecret
+		}
+		dnsNames := podDNSNames
+		if hasPerPodServices(acceptor) {
+			dnsNames = append(dnsNames, serviceDNSNames(customResource, acceptor.Name, acceptor.Expose, acceptor.ExposeMode, acceptor.Exposure)...)
+		}
+		if hasLoadBalancedService(acceptor) {
+			dnsNames = append(dnsNames, serviceHostNames(customResource, loadBalancedServiceName(customResource, acceptor.Name), "", acceptor.Expose, acceptor.ExposeMode, acceptor.Exposure)...)
+		}
+		add(secretName, acceptor.CertManager, dnsNames, false, false)
+	}
+
+	for _, connector := range customResource.Spec.Connectors {
+		if !connector.SSLEnabled || connector.CertManager == nil {
+			continue
+		}
+		secretName := customResource.Name + "-" + connector.Name + "-secret"
+		if connector.SSLSecret != "" {
+			secretName = connector.SSLSecret
+		}
+		add(secretName, connector.CertManager, podDNSNames, false, true)
+	}
+
+	console := customResource.Spec.Console
+	if console.SSLEnabled && console.CertManager != nil {
+		// the console takes a key store file, cert-manager adds a pkcs12 one to the secret
+		add(namer.SecretsConsoleNameBuilder.Name(), console.CertManager, append(podDNSNames, serviceDNSNames(customResource, "wconsj", console.Expose, console.ExposeMode, console.Exposure)...), true, true)
+	}
+
+	return requests
+}
+
+// brokerPodDNSNames are the names of the broker pods on the headless service
+func brokerPodDNSNames(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers) []string {
+	headlessService := namer.SvcHeadlessNameBuilder.Name() + "." + customResource.Namespace + ".svc"
+	return []string{
+		"*." + headlessService + "." + common.GetClusterDomain(),
+		"*." + headlessService,
+	}
+}
+
+// serviceDNSNames are the names of the services of each broker pod for a port, and the hosts
+// of their ingresses, routes or tls routes when exposed
+func serviceDNSNames(customResource *brokerv1beta1.ActiveMQArtemis, portName string, expose bool, exposeMode string, exposure *brokerv1beta1.ExposureType) []string {
+	var names []string
+	for i := int32(0); i < getBrokerPodCount(customResource); i++ {
+		ordinal := strconv.Itoa(int(i))
+		names = append(names, serviceHostNames(customResource, perPodServiceName(customResource, portName, ordinal), ordinal, expose, exposeMode, exposure)...)
+	}
+	return names
+}
+
+// serviceHostNames are the names of a service in the cluster and the host it is exposed on
+func serviceHostNames(customResource *brokerv1beta1.ActiveMQArtemis, serviceName string, ordinal string, expose bool, exposeMode string, exposure *brokerv1beta1.ExposureType) []string {
+	names := []string{
+		serviceName,
+		serviceName + "." + customResource.Namespace,
+		serviceName + "." + customResource.Namespace + ".svc",
+		serviceName + "." + customResource.Namespace + ".svc." + common.GetClusterDomain(),
+	}
+	if expose {
+		if host := exposedHost(customResource, serviceName, ordinal, exposeMode, exposure); host != "" {
+			names = append(names, host)
+		}
+	}
+	return names
+}
+
+func appendUnique(values []string, more ...string) []string {
+	for _, value := range more {
+		found := false
+		for _, existing := range values {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ProcessCertificates requests the certificates of the ssl secrets from cert-manager and reports
+// whether they are issued with the CertificatesReady condition
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessCertificates(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, client rtclient.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) {
+
+	requests := certificateRequests(customResource, namer)
+	// the certificates are removed with the certManager sections as they are no longer desired
+	deleteUndesiredCertificates(customResource, namer, requests, client)
+	if len(requests) == 0 {
+		meta.RemoveStatusCondition(&customResource.Status.Conditions, brokerv1beta1.CertificatesReadyConditionType)
+		environments.Delete(currentStatefulSet.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar)
+		return
+	}
+
+	namespacedName := types.NamespacedName{
+		Name:      customResource.Name,
+		Namespace: customResource.Namespace,
+	}
+
+	var pending []string
+	digest := adler32.New()
+	for _, request := range requests {
+		keyStoreSecret := reconciler.keyStoreSecret(namespacedName, namer, request)
+		reconciler.trackDesired(keyStoreSecret)
+
+		keyStorePasswordSecret := ""
+		if request.pkcs12 {
+			keyStorePasswordSecret = keyStoreSecret.Name
+		}
+		desired := certificates.NewCertificateForCR(namespacedName, namer.LabelBuilder.Labels(), request.secretName, request.certManager, request.dnsNames, keyStorePasswordSecret)
+
+		revision, message, err := syncCertificate(customResource, desired, client, scheme)
+		if err != nil {
+			pending = append(pending, fmt.Sprintf("%v: %v", request.secretName, err))
+			continue
+		}
+		if revision == 0 {
+			pending = append(pending, fmt.Sprintf("%v: %v", request.secretName, message))
+			continue
+		}
+		if revision == 1 {
+			// the brokers wait for the secret of the first certificate to start, only the
+			// renewals restart them
+			continue
+		}
+
+		secret := &corev1.Secret{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: request.secretName, Namespace: customResource.Namespace}, secret); err != nil {
+			pending = append(pending, fmt.Sprintf("%v: %v", request.secretName, err))
+			continue
+		}
+		if request.restartRequired || customResource.Spec.CertificateReloadPolicy != brokerv1beta1.CertificateReloadPolicyReload {
+			digest.Write(secret.Data[corev1.TLSCertKey])
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:   brokerv1beta1.CertificatesReadyConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: brokerv1beta1.CertificatesReadyConditionReason,
+	}
+	if len(pending) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = brokerv1beta1.CertificatesPendingReason
+		condition.Message = strings.Join(pending, ", ")
+	}
+	meta.SetStatusCondition(&customResource.Status.Conditions, condition)
+
+	// a renewed certificate is loaded by a restarted broker, unless the acceptors reload it. The
+	// env var is set from the start so that the first certificates do not change the pod template,
+	// and kept while a renewal is pending
+	checkSumEnvVar := &corev1.EnvVar{
+		Name:  certificatesCheckSumEnvVar,
+		Value: hex.EncodeToString(digest.Sum(nil)),
+	}
+	if environments.Retrieve(currentStatefulSet.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar) == nil {
+		environments.Create(currentStatefulSet.Spec.Template.Spec.Containers, checkSumEnvVar)
+	} else if len(pending) == 0 {
+		environments.Update(currentStatefulSet.Spec.Template.Spec.Containers, checkSumEnvVar)
+	}
+}
+
+// deleteUndesiredCertificates deletes the certificates the operator requested for ssl secrets that
+// no longer have a certManager section
+func deleteUndesiredCertificates(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, requests []*certificateRequest, client rtclient.Client) {
+	reqLogger := ctrl.Log.WithValues("ActiveMQArtemis Name", customResource.Name)
+
+	if !environments.DetectCertManager() {
+		return
+	}
+
+	desired := map[string]bool{}
+	for _, request := range requests {
+		desired[request.secretName] = true
+	}
+
+	deployed := certificates.NewCertificateList()
+	if err := client.List(context.TODO(), deployed, rtclient.InNamespace(customResource.Namespace), rtclient.MatchingLabels(namer.LabelBuilder.Labels())); err != nil {
+		reqLogger.Error(err, "unable to list the certificates")
+		return
+	}
+	for index := range deployed.Items {
+		certificate := &deployed.Items[index]
+		if desired[certificate.GetName()] || !metav1.IsControlledBy(certificate, customResource) {
+			continue
+		}
+		if err := deleteUnstructured(certificate, certificate.GetNamespace(), certificate.GetName(), client); err != nil {
+			reqLogger.Error(err, "unable to delete the certificate", "name", certificate.GetName())
+		}
+	}
+}
+
+// keyStoreSecret holds the pem config of the key store the brokers load from the issued secret,
+// and the password of the pkcs12 stores when requested
+func (reconciler *ActiveMQArtemisReconcilerImpl) keyStoreSecret(namespacedName types.NamespacedName, namer Namers, request *certificateRequest) *corev1.Secret {
+	volumePath := "/etc/" + request.secretName + "-volume/"
+	stringData := map[string]string{
+		pemConfigKey: "source.key=" + volumePath + corev1.TLSPrivateKeyKey + "\n" +
+			"source.cert=" + volumePath + corev1.TLSCertKey + "\n",
+	}
+	if request.pkcs12 {
+		stringData[certificates.KeyStorePasswordKey] = reconciler.keyStorePassword(request.secretName)
+	}
+	return secrets.NewSecret(namespacedName, request.secretName+keyStoreSecretSuffix, stringData, namer.LabelBuilder.Labels())
+}
+
+// keyStorePassword keeps the password of the pkcs12 stores once generated
+func (reconciler *ActiveMQArtemisReconcilerImpl) keyStorePassword(secretName string) string {
+	name := secretName + keyStoreSecretSuffix
+	for _, obj := range reconciler.requestedResources {
+		if secret, ok := obj.(*corev1.Secret); ok && secret.Name == name && secret.StringData[certificates.KeyStorePasswordKey] != "" {
+			return secret.StringData[certificates.KeyStorePasswordKey]
+		}
+	}
+	if obj := reconciler.getFromDeployed(reflect.TypeOf(corev1.Secret{}), name); obj != nil {
+		if password := obj.(*corev1.Secret).Data[certificates.KeyStorePasswordKey]; len(password) > 0 {
+			return string(password)
+		}
+	}
+	return random.GenerateRandomString(16)
+}
+
+// syncCertificate creates or updates the certificate and returns the revision cert-manager issued,
+// 0 until it is issued
+func syncCertificate(customResource *brokerv1beta1.ActiveMQArtemis, desired *unstructured.Unstructured, client rtclient.Client, scheme *runtime.Scheme) (int64, string, error) {
+	current := certificates.NewCertificate()
+	err := client.Get(context.TODO(), types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, current)
+	if k8serrors.IsNotFound(err) {
+		if err := resources.Create(customResource, client, scheme, desired); err != nil {
+			return 0, "", err
+		}
+		return 0, "requested", nil
+	}
+	if err != nil {
+		// also the error when cert-manager is not installed
+		return 0, "", err
+	}
+
+	if !equality.Semantic.DeepEqual(current.Object["spec"], desired.Object["spec"]) || !equality.Semantic.DeepEqual(current.GetLabels(), desired.GetLabels()) {
+		current.Object["spec"] = desired.Object["spec"]
+		current.SetLabels(desired.GetLabels())
+		if err := resources.Update(client, current); err != nil {
+			return 0, "", err
+		}
+		return 0, "updated", nil
+	}
+
+	ready, message := certificates.IsReady(current)
+	if !ready {
+		return 0, message, nil
+	}
+	if revision := certificates.Revision(current); revision > 0 {
+		return revision, message, nil
+	}
+	return 1, message, nil
+}
+
+func generateCertManagerSSLArguments(secretName string) string {
+	volumePath := "\\/etc\\/" + secretName + "-volume\\/"
+	keyStorePath := "\\/etc\\/" + secretName + keyStoreSecretSuffix + "-volume\\/" + pemConfigKey
+
+	sslArguments := "sslEnabled=true"
+	sslArguments = sslArguments + ";" + "keyStoreType=PEMCFG"
+	sslArguments = sslArguments + ";" + "keyStorePath=" + keyStorePath
+	sslArguments = sslArguments + ";" + "trustStoreType=PEMCA"
+	sslArguments = sslArguments + ";" + "trustStorePath=" + volumePath + certificates.CAKey
+	return sslArguments
+}
+
+func generateCertManagerConsoleSSLFlags(customResource *brokerv1beta1.ActiveMQArtemis, reconciler *ActiveMQArtemisReconcilerImpl, secretName string) string {
+	volumePath := "/etc/" + secretName + "-volume/"
+	password := reconciler.keyStorePassword(secretName)
+
+	sslFlags := ""
+	sslFlags = sslFlags + " " + "--ssl-key" + " " + volumePath + certificates.PKCS12KeyStoreKey
+	sslFlags = sslFlags + " " + "--ssl-key-password" + " " + password
+	sslFlags = sslFlags + " " + "--ssl-trust" + " " + volumePath + certificates.PKCS12TrustStoreKey
+	sslFlags = sslFlags + " " + "--ssl-trust-password" + " " + password
+	if customResource.Spec.Console.UseClientAuth {
+		sslFlags = sslFlags + " " + "--use-client-auth"
+	}
+	return sslFlags
+}
+
+func validateCertManager(customResource *brokerv1beta1.ActiveMQArtemis) *metav1.Condition {
+	type candidate struct {
+		contextMessage string
+		sslEnabled     bool
+		certManager    *brokerv1beta1.CertManagerType
+	}
+	var candidates []candidate
+	for index, acceptor := range customResource.Spec.Acceptors {
+		candidates = append(candidates, candidate{fmt.Sprintf(".Spec.Acceptors[%d].CertManager", index), acceptor.SSLEnabled, acceptor.CertManager})
+	}
+	for index, connector := range customResource.Spec.Connectors {
+		candidates = append(candidates, candidate{fmt.Sprintf(".Spec.Connectors[%d].CertManager", index), connector.SSLEnabled, connector.CertManager})
+	}
+	candidates = append(candidates, candidate{".Spec.Console.CertManager", customResource.Spec.Console.SSLEnabled, customResource.Spec.Console.CertManager})
+
+	for _, c := range candidates {
+		if c.certManager == nil {
+			continue
+		}
+		var reason string
+		switch kind := c.certManager.IssuerRef.Kind; {
+		case !c.sslEnabled:
+			reason = "requires sslEnabled"
+		case c.certManager.IssuerRef.Name == "":
+			reason = "issuerRef.name is required"
+		case kind != "" && kind != certificates.DefaultIssuerKind && kind != "ClusterIssuer" && c.certManager.IssuerRef.Group == "":
+			reason = fmt.Sprintf("issuerRef.kind %q must be Issuer or ClusterIssuer", kind)
+		}
+		if reason != "" {
+			return &metav1.Condition{
+				Type:    brokerv1beta1.ValidConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  brokerv1beta1.ValidConditionInvalidCertManagerReason,
+				Message: fmt.Sprintf("%v %v", c.contextMessage, reason),
+			}
+		}
+	}
+	return nil
+}
+
+// certificateKeyStoreSecretNames are the secrets with the key store config of the certificates
+// issued by cert-manager, the brokers mount them next to the issued secrets
+func certificateKeyStoreSecretNames(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers) []string {
+	var names []string
+	for _, request := range certificateRequests(customResource, namer) {
+		names = append(names, request.secretName+keyStoreSecretSuffix)
+	}
+	return names
+}
+
+// reloadableAcceptor is an ssl acceptor with the checksum of the data of its secret, the key and
+// trust stores it loads whatever their format
+type reloadableAcceptor struct {
+	name       string
+	secretName string
+	checksum   string
+	// when the operator first saw the current data of the secret
+	changedAt time.Time
+}
+
+// the kubelet updates the mounted secrets within its sync period, a minute by default
+var secretMountDelay = 90 * time.Second
+
+// secretChanges is when the operator first saw the current data of the ssl secrets
+var secretChanges = map[types.NamespacedName]secretChange{}
+var secretChangesLock sync.Mutex
+
+type secretChange struct {
+	checksum string
+	seenAt   time.Time
+}
+
+func secretChangedAt(secret types.NamespacedName, checksum string, now time.Time) time.Time {
+	secretChangesLock.Lock()
+	defer secretChangesLock.Unlock()
+
+	change, found := secretChanges[secret]
+	if !found || change.checksum != checksum {
+		change = secretChange{checksum: checksum, seenAt: now}
+		secretChanges[secret] = change
+	}
+	return change.seenAt
+}
+
+// secretDataCheckSum digests the keys and values of the data of a secret
+func secretDataCheckSum(secret *corev1.Secret) string {
+	digest := adler32.New()
+	for _, k := range sortedKeysStringKeyByteValue(secret.Data) {
+		digest.Write([]byte(k))
+		digest.Write(secret.Data[k])
+	}
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+// reloadableAcceptors are the ssl acceptors with the checksums of their secrets
+func reloadableAcceptors(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, now time.Time) ([]reloadableAcceptor, []string) {
+	var acceptors []reloadableAcceptor
+	var failures []string
+	for _, acceptor := range customResource.Spec.Acceptors {
+		if !acceptor.SSLEnabled {
+			continue
+		}
+		secretName := customResource.Name + "-" + acceptor.Name + "-secret"
+		if acceptor.SSLSecret != "" {
+			secretName = acceptor.SSLSecret
+		}
+		secret := &corev1.Secret{}
+		namespacedName := types.NamespacedName{Name: secretName, Namespace: customResource.Namespace}
+		if err := client.Get(context.TODO(), namespacedName, secret); err != nil {
+			failures = append(failures, fmt.Sprintf("%v: %v", acceptor.Name, err))
+			continue
+		}
+		checksum := secretDataCheckSum(secret)
+		acceptors = append(acceptors, reloadableAcceptor{
+			name:       acceptor.Name,
+			secretName: secretName,
+			checksum:   checksum,
+			changedAt:  secretChangedAt(namespacedName, checksum, now),
+		})
+	}
+	return acceptors, failures
+}
+
+// the checksums of the secrets the acceptors of a pod loaded, by acceptor name
+const loadedAcceptorSecretsAnnotation = "broker.amq.io/loaded-acceptor-secrets"
+
+// reloadPodAcceptors reloads the acceptors of a pod that have not loaded the current data of their
+// secret, once it is mounted, and records the loaded checksums in the annotations of the pod. It
+// returns the pending acceptors and whether the annotations changed
+func reloadPodAcceptors(pod *corev1.Pod, acceptors []reloadableAcceptor, reload func(acceptorName string) error, now time.Time) ([]string, bool) {
+	loaded := map[string]string{}
+	if value, found := pod.Annotations[loadedAcceptorSecretsAnnotation]; found {
+		// unreadable checksums are replaced
+		_ = json.Unmarshal([]byte(value), &loaded)
+	}
+
+	var startedAt time.Time
+	if pod.Status.StartTime != nil {
+		startedAt = pod.Status.StartTime.Time
+	}
+
+	var pending []string
+	current := map[string]string{}
+	for _, acceptor := range acceptors {
+		mountedAt := acceptor.changedAt.Add(secretMountDelay)
+		checksum, found := loaded[acceptor.name]
+		if found {
+			current[acceptor.name] = checksum
+		}
+		switch {
+		case checksum == acceptor.checksum:
+		case !found && !startedAt.IsZero() && !startedAt.Before(mountedAt):
+			// the broker started with the current data
+			current[acceptor.name] = acceptor.checksum
+		case now.Before(mountedAt):
+			pending = append(pending, fmt.Sprintf("%v: waiting for %v to be mounted", acceptor.name, acceptor.secretName))
+		default:
+			if err := reload(acceptor.name); err != nil {
+				pending = append(pending, fmt.Sprintf("%v: %v", acceptor.name, err))
+				continue
+			}
+			current[acceptor.name] = acceptor.checksum
+		}
+	}
+
+	if reflect.DeepEqual(loaded, current) {
+		return pending, false
+	}
+	value, _ := json.Marshal(current)
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[loadedAcceptorSecretsAnnotation] = string(value)
+	return pending, true
+}
+
+// ReloadAcceptorCertificates asks the brokers to reload the acceptors whose secret changed since
+// they loaded it, and reports the pods that loaded the current secrets with the CertificatesLoaded
+// condition
+func ReloadAcceptorCertificates(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, scheme *runtime.Scheme) ctrl.Result {
+	reqLogger := ctrl.Log.WithValues("ActiveMQArtemis Name", customResource.Name)
+
+	if customResource.Spec.CertificateReloadPolicy != brokerv1beta1.CertificateReloadPolicyReload {
+		meta.RemoveStatusCondition(&customResource.Status.Conditions, brokerv1beta1.CertificatesLoadedConditionType)
+		return ctrl.Result{}
+	}
+
+	acceptors, pending := reloadableAcceptors(customResource, client, time.Now())
+	if len(acceptors) == 0 && len(pending) == 0 {
+		meta.RemoveStatusCondition(&customResource.Status.Conditions, brokerv1beta1.CertificatesLoadedConditionType)
+		return ctrl.Result{}
+	}
+
+	if err := AssertBrokersAvailable(customResource, client, scheme); err != nil {
+		return ctrl.Result{}
+	}
+
+	resource := types.NamespacedName{
+		Name:      customResource.Name,
+		Namespace: customResource.Namespace,
+	}
+	ssInfos := ss.GetDeployedStatefulSetNames(client, []types.NamespacedName{resource})
+	jks := jolokia_client.GetBrokers(resource, ssInfos, client)
+	if len(jks) == 0 {
+		pending = append(pending, "waiting for the brokers")
+	}
+
+	var loaded []string
+	for _, jk := range jks {
+		pod := &corev1.Pod{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: jk.PodName, Namespace: customResource.Namespace}, pod); err != nil {
+			pending = append(pending, fmt.Sprintf("%v %v", jk.PodName, err))
+			continue
+		}
+		original := pod.DeepCopy()
+
+		artemis := jk.Artemis
+		podPending, changed := reloadPodAcceptors(pod, acceptors, func(acceptorName string) error {
+			reqLogger.Info("reloading acceptor with a changed secret", "acceptor", acceptorName, "pod", jk.PodName)
+			return artemis.ReloadAcceptor(acceptorName)
+		}, time.Now())
+		if changed {
+			if err := client.Patch(context.TODO(), pod, rtclient.MergeFrom(original)); err != nil {
+				// the acceptors are reloaded again
+				podPending = append(podPending, err.Error())
+			}
+		}
+
+		if len(podPending) == 0 {
+			loaded = append(loaded, jk.PodName)
+		} else {
+			pending = append(pending, fmt.Sprintf("%v %v", jk.PodName, strings.Join(podPending, ", ")))
+		}
+	}
+
+	meta.SetStatusCondition(&customResource.Status.Conditions, certificatesLoadedCondition(loaded, pending))
+	if len(pending) > 0 {
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}
+	}
+	return ctrl.Result{}
+}
+
+func certificatesLoadedCondition(loaded []string, pending []string) metav1.Condition {
+	sort.Strings(loaded)
+	sort.Strings(pending)
+
+	condition := metav1.Condition{
+		Type:    brokerv1beta1.CertificatesLoadedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  brokerv1beta1.CertificatesLoadedConditionReason,
+		Message: "loaded by " + strings.Join(loaded, ", "),
+	}
+	if len(pending) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = brokerv1beta1.CertificatesReloadPendingReason
+		condition.Message = "pending on " + strings.Join(pending, "; ")
+		if len(loaded) > 0 {
+			condition.Message = "loaded by " + strings.Join(loaded, ", ") + ", " + condition.Message
+		}
+	}
+	return condition
+}
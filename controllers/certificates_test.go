@@ -0,0 +1,440 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/certificates"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCertificateRequests(t *testing.T) {
+	certManager := &brokerv1beta1.CertManagerType{
+		IssuerRef: brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer"},
+		DNSNames:  []string{"broker.example.com"},
+	}
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, SSLEnabled: true, CertManager: certManager},
+				{Name: "plain", Port: 5672},
+			},
+			Connectors: []brokerv1beta1.ConnectorType{
+				{Name: "amqps", Host: "remote", Port: 5671, SSLEnabled: true, SSLSecret: "ex-aao-amqps-secret", CertManager: certManager},
+			},
+		},
+	}
+	requests := certificateRequests(cr, *MakeNamers(cr))
+
+	// the acceptor and the connector share the secret
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, "ex-aao-amqps-secret", requests[0].secretName)
+		assert.False(t, requests[0].pkcs12)
+		assert.Equal(t, []string{
+			"*.ex-aao-hdls-svc.test.svc.cluster.local",
+			"*.ex-aao-hdls-svc.test.svc",
+			"ex-aao-amqps-0-svc",
+			"ex-aao-amqps-0-svc.test",
+			"ex-aao-amqps-0-svc.test.svc",
+			"ex-aao-amqps-0-svc.test.svc.cluster.local",
+			"broker.example.com",
+		}, requests[0].dnsNames)
+	}
+
+	cr.Spec.Console.SSLEnabled = true
+	cr.Spec.Console.CertManager = &brokerv1beta1.CertManagerType{IssuerRef: brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer"}}
+	requests = certificateRequests(cr, *MakeNamers(cr))
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, "ex-aao-console-secret", requests[1].secretName)
+		assert.True(t, requests[1].pkcs12)
+		assert.Contains(t, requests[1].dnsNames, "ex-aao-wconsj-0-svc.test.svc")
+	}
+	assert.Equal(t, []string{"ex-aao-amqps-secret-keystore", "ex-aao-console-secret-keystore"}, certificateKeyStoreSecretNames(cr, *MakeNamers(cr)))
+}
+
+func TestNewCertificateForCR(t *testing.T) {
+	certManager := &brokerv1beta1.CertManagerType{
+		IssuerRef:   brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer", Kind: "ClusterIssuer"},
+		Duration:    &metav1.Duration{Duration: 2160 * time.Hour},
+		RenewBefore: &metav1.Duration{Duration: 360 * time.Hour},
+	}
+	certificate := certificates.NewCertificateForCR(types.NamespacedName{Name: "ex-aao", Namespace: "test"}, nil, "ex-aao-console-secret", certManager, []string{"a", "b"}, "ex-aao-console-secret-keystore")
+
+	assert.Equal(t, "Certificate", certificate.GetKind())
+	assert.Equal(t, "ex-aao-console-secret", certificate.GetName())
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	assert.Equal(t, "ex-aao-console-secret", secretName)
+	issuerRef, _, _ := unstructured.NestedStringMap(certificate.Object, "spec", "issuerRef")
+	assert.Equal(t, map[string]string{"name": "ca-issuer", "kind": "ClusterIssuer", "group": "cert-manager.io"}, issuerRef)
+	dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	assert.Equal(t, []string{"a", "b"}, dnsNames)
+	duration, _, _ := unstructured.NestedString(certificate.Object, "spec", "duration")
+	assert.Equal(t, "2160h0m0s", duration)
+	passwordSecret, _, _ := unstructured.NestedString(certificate.Object, "spec", "keystores", "pkcs12", "passwordSecretRef", "name")
+	assert.Equal(t, "ex-aao-console-secret-keystore", passwordSecret)
+
+	ready, _ := certificates.IsReady(certificate)
+	assert.False(t, ready)
+	assert.NoError(t, unstructured.SetNestedSlice(certificate.Object, []interface{}{
+		map[string]interface{}{"type": "Ready", "status": "True", "message": "Certificate is up to date and has not expired"},
+	}, "status", "conditions"))
+	ready, message := certificates.IsReady(certificate)
+	assert.True(t, ready)
+	assert.Equal(t, "Certificate is up to date and has not expired", message)
+}
+
+func TestProcessCertificates(t *testing.T) {
+	certManager := &brokerv1beta1.CertManagerType{
+		IssuerRef: brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer"},
+		DNSNames:  []string{"broker.example.com"},
+	}
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{APIVersion: brokerv1beta1.GroupVersion.String(), Kind: "ActiveMQArtemis"},
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, SSLEnabled: true, CertManager: certManager},
+			},
+			Connectors: []brokerv1beta1.ConnectorType{
+				{Name: "amqps", Host: "remote", Port: 5671, SSLEnabled: true, SSLSecret: "ex-aao-amqps-secret", CertManager: certManager},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+	client := fake.NewClientBuilder().Build()
+	sts := &appsv1.StatefulSet{}
+	sts.Spec.Template.Spec.Containers = []v1.Container{{Name: "broker"}}
+
+	reconciler := ActiveMQArtemisReconcilerImpl{}
+	reconciler.ProcessCertificates(cr, *namer, client, nil, sts)
+
+	condition := meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.CertificatesReadyConditionType)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, brokerv1beta1.CertificatesPendingReason, condition.Reason)
+	}
+	// set before the certificates are issued so that they do not restart the brokers
+	initialCheckSum := environments.Retrieve(sts.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar)
+	assert.NotNil(t, initialCheckSum)
+
+	// the pem config of the key store is tracked
+	if assert.Len(t, reconciler.requestedResources, 1) {
+		keyStore := reconciler.requestedResources[0].(*v1.Secret)
+		assert.Equal(t, "ex-aao-amqps-secret-keystore", keyStore.Name)
+		assert.Equal(t, "source.key=/etc/ex-aao-amqps-secret-volume/tls.key\nsource.cert=/etc/ex-aao-amqps-secret-volume/tls.crt\n", keyStore.StringData[pemConfigKey])
+	}
+
+	certificate := certificates.NewCertificate()
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-amqps-secret", Namespace: "test"}, certificate))
+	assert.Equal(t, "ex-aao", certificate.GetOwnerReferences()[0].Name)
+
+	// issued
+	assert.NoError(t, unstructured.SetNestedSlice(certificate.Object, []interface{}{
+		map[string]interface{}{"type": "Ready", "status": "True"},
+	}, "status", "conditions"))
+	assert.NoError(t, unstructured.SetNestedField(certificate.Object, int64(1), "status", "revision"))
+	assert.NoError(t, client.Update(context.TODO(), certificate))
+	secret := &v1.Secret{}
+	secret.Name = "ex-aao-amqps-secret"
+	secret.Namespace = "test"
+	secret.Data = map[string][]byte{v1.TLSCertKey: []byte("cert")}
+	assert.NoError(t, client.Create(context.TODO(), secret))
+
+	reconciler = ActiveMQArtemisReconcilerImpl{}
+	reconciler.ProcessCertificates(cr, *namer, client, nil, sts)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, brokerv1beta1.CertificatesReadyConditionType))
+	checkSum := environments.Retrieve(sts.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar)
+	if assert.NotNil(t, checkSum) {
+		assert.Equal(t, initialCheckSum.Value, checkSum.Value)
+
+		// a renewal rolls the brokers
+		assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-amqps-secret", Namespace: "test"}, certificate))
+		assert.NoError(t, unstructured.SetNestedField(certificate.Object, int64(2), "status", "revision"))
+		assert.NoError(t, client.Update(context.TODO(), certificate))
+		secret.Data[v1.TLSCertKey] = []byte("renewed")
+		assert.NoError(t, client.Update(context.TODO(), secret))
+		previous := checkSum.Value
+		reconciler.ProcessCertificates(cr, *namer, client, nil, sts)
+		assert.NotEqual(t, previous, environments.Retrieve(sts.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar).Value)
+
+		// the acceptors reload a renewal, the connector still rolls the brokers
+		cr.Spec.CertificateReloadPolicy = brokerv1beta1.CertificateReloadPolicyReload
+		secret.Data[v1.TLSCertKey] = []byte("renewed again")
+		assert.NoError(t, client.Update(context.TODO(), secret))
+		previous = environments.Retrieve(sts.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar).Value
+		reconciler.ProcessCertificates(cr, *namer, client, nil, sts)
+		assert.NotEqual(t, previous, environments.Retrieve(sts.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar).Value)
+
+		cr.Spec.Connectors = nil
+		reconciler.ProcessCertificates(cr, *namer, client, nil, sts)
+		previous = environments.Retrieve(sts.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar).Value
+		secret.Data[v1.TLSCertKey] = []byte("renewed once more")
+		assert.NoError(t, client.Update(context.TODO(), secret))
+		reconciler.ProcessCertificates(cr, *namer, client, nil, sts)
+		assert.Equal(t, previous, environments.Retrieve(sts.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar).Value)
+	}
+
+	cr.Spec.Acceptors[0].CertManager = nil
+	cr.Spec.Connectors = nil
+	reconciler.ProcessCertificates(cr, *namer, client, nil, sts)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.CertificatesReadyConditionType))
+	assert.Nil(t, environments.Retrieve(sts.Spec.Template.Spec.Containers, certificatesCheckSumEnvVar))
+}
+
+func TestDeleteUndesiredCertificates(t *testing.T) {
+	common.GetStateManager().SetState(common.CertificateKind, true)
+	defer common.GetStateManager().SetState(common.CertificateKind, false)
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{APIVersion: brokerv1beta1.GroupVersion.String(), Kind: "ActiveMQArtemis"},
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test", UID: "ex-aao-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, SSLEnabled: true, CertManager: &brokerv1beta1.CertManagerType{
+					IssuerRef: brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer"},
+				}},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+	isController := true
+	owner := metav1.OwnerReference{APIVersion: cr.APIVersion, Kind: cr.Kind, Name: cr.Name, UID: cr.UID, Controller: &isController}
+
+	newCertificate := func(name string) *unstructured.Unstructured {
+		certificate := certificates.NewCertificate()
+		certificate.SetName(name)
+		certificate.SetNamespace(cr.Namespace)
+		certificate.SetLabels(namer.LabelBuilder.Labels())
+		certificate.SetOwnerReferences([]metav1.OwnerReference{owner})
+		return certificate
+	}
+	unowned := newCertificate("other-secret")
+	unowned.SetOwnerReferences(nil)
+	client := fake.NewClientBuilder().WithObjects(newCertificate("ex-aao-amqps-secret"), newCertificate("ex-aao-console-secret"), unowned).Build()
+
+	deleteUndesiredCertificates(cr, *namer, certificateRequests(cr, *namer), client)
+
+	deployed := certificates.NewCertificateList()
+	assert.NoError(t, client.List(context.TODO(), deployed))
+	var names []string
+	for _, certificate := range deployed.Items {
+		names = append(names, certificate.GetName())
+	}
+	assert.ElementsMatch(t, []string{"ex-aao-amqps-secret", "other-secret"}, names)
+}
+
+func TestReloadableAcceptors(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, SSLEnabled: true, CertManager: &brokerv1beta1.CertManagerType{
+					IssuerRef: brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer"},
+				}},
+				{Name: "plain", Port: 5672},
+				{Name: "jks", Port: 61617, SSLEnabled: true, SSLSecret: "jks-secret"},
+			},
+		},
+	}
+
+	pemSecret := &v1.Secret{}
+	pemSecret.Name = "ex-aao-amqps-secret"
+	pemSecret.Namespace = "test"
+	pemSecret.Data = map[string][]byte{v1.TLSCertKey: []byte("cert"), certificates.CAKey: []byte("ca")}
+	jksSecret := &v1.Secret{}
+	jksSecret.Name = "jks-secret"
+	jksSecret.Namespace = "test"
+	jksSecret.Data = map[string][]byte{"broker.ks": []byte("jks"), "client.ts": []byte("trust")}
+	client := fake.NewClientBuilder().WithObjects(pemSecret, jksSecret).Build()
+
+	seenAt := time.Now()
+	acceptors, failures := reloadableAcceptors(cr, client, seenAt)
+	assert.Empty(t, failures)
+	if assert.Len(t, acceptors, 2) {
+		assert.Equal(t, "amqps", acceptors[0].name)
+		assert.Equal(t, "jks", acceptors[1].name)
+		assert.Equal(t, seenAt, acceptors[1].changedAt)
+	}
+
+	// a change of the trust store only is a change of the secret
+	jksSecret.Data["client.ts"] = []byte("new trust")
+	assert.NoError(t, client.Update(context.TODO(), jksSecret))
+	changed, _ := reloadableAcceptors(cr, client, seenAt.Add(time.Hour))
+	if assert.Len(t, changed, 2) {
+		assert.Equal(t, acceptors[0], changed[0])
+		assert.NotEqual(t, acceptors[1].checksum, changed[1].checksum)
+		assert.Equal(t, seenAt.Add(time.Hour), changed[1].changedAt)
+	}
+
+	assert.NoError(t, client.Delete(context.TODO(), pemSecret))
+	acceptors, failures = reloadableAcceptors(cr, client, seenAt)
+	assert.Len(t, acceptors, 1)
+	assert.Len(t, failures, 1)
+}
+
+func TestReloadPodAcceptors(t *testing.T) {
+	changedAt := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	mountedAt := changedAt.Add(secretMountDelay)
+	acceptors := []reloadableAcceptor{{name: "amqps", secretName: "ex-aao-amqps-secret", checksum: "new", changedAt: changedAt}}
+
+	var reloaded []string
+	reload := func(acceptorName string) error {
+		reloaded = append(reloaded, acceptorName)
+		return nil
+	}
+
+	// started before the change
+	pod := &v1.Pod{}
+	startTime := metav1.NewTime(changedAt.Add(-time.Hour))
+	pod.Status.StartTime = &startTime
+	pod.Annotations = map[string]string{loadedAcceptorSecretsAnnotation: `{"amqps":"old"}`}
+
+	pending, changed := reloadPodAcceptors(pod, acceptors, reload, changedAt.Add(time.Second))
+	assert.Equal(t, []string{"amqps: waiting for ex-aao-amqps-secret to be mounted"}, pending)
+	assert.False(t, changed)
+	assert.Empty(t, reloaded)
+
+	pending, changed = reloadPodAcceptors(pod, acceptors, reload, mountedAt)
+	assert.Empty(t, pending)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"amqps"}, reloaded)
+	assert.Equal(t, `{"amqps":"new"}`, pod.Annotations[loadedAcceptorSecretsAnnotation])
+
+	pending, changed = reloadPodAcceptors(pod, acceptors, reload, mountedAt.Add(time.Hour))
+	assert.Empty(t, pending)
+	assert.False(t, changed)
+	assert.Len(t, reloaded, 1)
+
+	// started with the mounted secret
+	pod = &v1.Pod{}
+	startTime = metav1.NewTime(mountedAt.Add(time.Minute))
+	pod.Status.StartTime = &startTime
+	pending, changed = reloadPodAcceptors(pod, acceptors, reload, mountedAt.Add(time.Hour))
+	assert.Empty(t, pending)
+	assert.True(t, changed)
+	assert.Len(t, reloaded, 1)
+
+	// the reload is retried
+	pod = &v1.Pod{}
+	pending, _ = reloadPodAcceptors(pod, acceptors, func(string) error { return errors.New("unavailable") }, mountedAt)
+	assert.Equal(t, []string{"amqps: unavailable"}, pending)
+	assert.Empty(t, pod.Annotations)
+}
+
+func TestCertificatesLoadedCondition(t *testing.T) {
+	condition := certificatesLoadedCondition([]string{"ex-aao-ss-1", "ex-aao-ss-0"}, nil)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, brokerv1beta1.CertificatesLoadedConditionReason, condition.Reason)
+	assert.Equal(t, "loaded by ex-aao-ss-0, ex-aao-ss-1", condition.Message)
+
+	condition = certificatesLoadedCondition([]string{"ex-aao-ss-0"}, []string{"ex-aao-ss-1 amqps: reload requested"})
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, brokerv1beta1.CertificatesReloadPendingReason, condition.Reason)
+	assert.Equal(t, "loaded by ex-aao-ss-0, pending on ex-aao-ss-1 amqps: reload requested", condition.Message)
+}
+
+func TestReloadAcceptorCertificatesRestartPolicy(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+	}
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{Type: brokerv1beta1.CertificatesLoadedConditionType, Status: metav1.ConditionFalse, Reason: brokerv1beta1.CertificatesReloadPendingReason})
+
+	result := ReloadAcceptorCertificates(cr, fake.NewClientBuilder().Build(), nil)
+	assert.True(t, result.IsZero())
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.CertificatesLoadedConditionType))
+}
+
+func TestCertManagerSSLArguments(t *testing.T) {
+	certManager := &brokerv1beta1.CertManagerType{
+		IssuerRef: brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer"},
+		DNSNames:  []string{"broker.example.com"},
+	}
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, SSLEnabled: true, CertManager: certManager},
+				{Name: "plain", Port: 5672},
+			},
+			Connectors: []brokerv1beta1.ConnectorType{
+				{Name: "amqps", Host: "remote", Port: 5671, SSLEnabled: true, SSLSecret: "ex-aao-amqps-secret", CertManager: certManager},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+
+	acceptors := generateAcceptorsString(cr, *namer, fake.NewClientBuilder().Build())
+	assert.Contains(t, acceptors, "sslEnabled=true;keyStoreType=PEMCFG;keyStorePath=\\/etc\\/ex-aao-amqps-secret-keystore-volume\\/tls.pemcfg;trustStoreType=PEMCA;trustStorePath=\\/etc\\/ex-aao-amqps-secret-volume\\/ca.crt")
+	connectors := generateConnectorsString(cr, *namer, fake.NewClientBuilder().Build())
+	assert.Contains(t, connectors, "keyStoreType=PEMCFG")
+
+	mounts := MakeVolumeMounts(cr, *namer)
+	var mountPaths []string
+	for _, mount := range mounts {
+		mountPaths = append(mountPaths, mount.MountPath)
+	}
+	assert.Equal(t, []string{"/etc/ex-aao-amqps-secret-volume", "/etc/ex-aao-amqps-secret-keystore-volume"}, mountPaths)
+	assert.Len(t, MakeVolumes(cr, *namer), 2)
+
+	cr.Spec.Console.SSLEnabled = true
+	cr.Spec.Console.CertManager = &brokerv1beta1.CertManagerType{IssuerRef: brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer"}}
+	reconciler := &ActiveMQArtemisReconcilerImpl{}
+	flags := generateCertManagerConsoleSSLFlags(cr, reconciler, "ex-aao-console-secret")
+	assert.True(t, strings.HasPrefix(flags, " --ssl-key /etc/ex-aao-console-secret-volume/keystore.p12 --ssl-key-password "))
+	assert.Contains(t, flags, "--ssl-trust /etc/ex-aao-console-secret-volume/truststore.p12")
+}
+
+func TestValidateCertManager(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqps", Port: 5671, SSLEnabled: true, CertManager: &brokerv1beta1.CertManagerType{
+					IssuerRef: brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer"},
+				}},
+				{Name: "plain", Port: 5672},
+			},
+		},
+	}
+	assert.Nil(t, validateCertManager(cr))
+
+	cr.Spec.Acceptors[0].SSLEnabled = false
+	condition := validateCertManager(cr)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, brokerv1beta1.ValidConditionInvalidCertManagerReason, condition.Reason)
+		assert.Equal(t, ".Spec.Acceptors[0].CertManager requires sslEnabled", condition.Message)
+	}
+
+	cr = &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Console: brokerv1beta1.ConsoleType{
+				SSLEnabled:  true,
+				CertManager: &brokerv1beta1.CertManagerType{IssuerRef: brokerv1beta1.CertManagerIssuerRefType{Name: "ca-issuer", Kind: "Vault"}},
+			},
+		},
+	}
+	condition = validateCertManager(cr)
+	if assert.NotNil(t, condition) {
+		assert.Contains(t, condition.Message, ".Spec.Console.CertManager issuerRef.kind")
+	}
+
+	// external issuers have their own kinds
+	cr.Spec.Console.CertManager.IssuerRef.Group = "cert-manager.k8s.cloudflare.com"
+	assert.Nil(t, validateCertManager(cr))
+}
@@ -0,0 +1,536 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/gateways"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/ingresses"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia_client"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveExposeMode returns the expose mode, a route on openshift and an ingress otherwise by default
+func resolveExposeMode(exposeMode string) string {
+	if exposeMode != "" {
+		return exposeMode
+	}
+	if isOpenshift, _ := environments.DetectOpenshift(); isOpenshift {
+		return brokerv1beta1.ExposeModeRoute
+	}
+	return brokerv1beta1.ExposeModeIngress
+}
+
+func hasPerPodServices(acceptor brokerv1beta1.AcceptorType) bool {
+	return acceptor.ServiceMode != brokerv1beta1.ServiceModeLoadBalanced
+}
+
+func hasLoadBalancedService(acceptor brokerv1beta1.AcceptorType) bool {
+	return acceptor.ServiceMode == brokerv1beta1.ServiceModeLoadBalanced || acceptor.ServiceMode == brokerv1beta1.ServiceModeBoth
+}
+
+func perPodServiceName(customResource *brokerv1beta1.ActiveMQArtemis, portName string, ordinal string) string {
+	return customResource.Name + "-" + portName + "-" + ordinal + "-svc"
+}
+
+func loadBalancedServiceName(customResource *brokerv1beta1.ActiveMQArtemis, acceptorName string) string {
+	return customResource.Name + "-" + acceptorName + "-svc"
+}
+
+func acceptorSSLSecretName(customResource *brokerv1beta1.ActiveMQArtemis, acceptor brokerv1beta1.AcceptorType) string {
+	if acceptor.SSLSecret != "" {
+		return acceptor.SSLSecret
+	}
+	return customResource.Name + "-" + acceptor.Name + "-secret"
+}
+
+// exposedPort is a port of a service of the brokers to expose
+type exposedPort struct {
+	targetPortName string
+	port           int32
+	sslEnabled     bool
+	// the secret of the certificate of the port, its ca verifies the brokers for the reencrypt
+	// tls termination
+	sslSecretName string
+	// the ordinal of the broker pod, empty for the load balanced service of an acceptor
+	ordinal    string
+	exposeMode string
+	exposure   *brokerv1beta1.ExposureType
+}
+
+// exposePort exposes the service of a port of the brokers the way the expose mode requires
+func (reconciler *ActiveMQArtemisReconcilerImpl) exposePort(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, labels map[string]string, service *corev1.Service, port exposedPort) {
+	namespacedName := types.NamespacedName{
+		Name:      customResource.Name,
+		Namespace: customResource.Namespace,
+	}
+
+	exposure := resolveExposure(port.exposure, port.ordinal)
+	switch mode := resolveExposeMode(port.exposeMode); mode {
+	case brokerv1beta1.ExposeModeLoadBalancer:
+		reconciler.exposeServiceWithType(service, corev1.ServiceTypeLoadBalancer, exposure)
+	case brokerv1beta1.ExposeModeNodePort:
+		reconciler.exposeServiceWithType(service, corev1.ServiceTypeNodePort, exposure)
+	case brokerv1beta1.ExposeModeGateway:
+		clog.Info("creating tls route for "+port.targetPortName, "service", service.Name)
+		reconciler.requestedTLSRoutes = append(reconciler.requestedTLSRoutes, gateways.NewTLSRouteForCR(namespacedName, labels, service.Name, port.port, exposure, customResource.Spec.IngressDomain))
+	default:
+		desired := reconciler.ExposureDefinitionForCR(namespacedName, labels, service.Name, port.targetPortName, tlsTermination(port.sslEnabled, exposure), customResource.Spec.IngressDomain, mode, exposure)
+		if route, ok := desired.(*routev1.Route); ok {
+			addRouteCertificates(customResource, client, route, exposure, port.sslSecretName)
+		}
+		reconciler.trackDesired(desired)
+	}
+}
+
+// resolveExposure returns a copy of the exposure with the ordinal of the broker pod in its host
+func resolveExposure(exposure *brokerv1beta1.ExposureType, ordinal string) *brokerv1beta1.ExposureType {
+	if exposure == nil {
+		return nil
+	}
+	resolved := exposure.DeepCopy()
+	resolved.Host = strings.ReplaceAll(resolved.Host, brokerv1beta1.ExposureHostOrdinalPlaceholder, ordinal)
+	return resolved
+}
+
+// tlsTermination returns where the tls connections to an exposed port end, the ingress or route
+// passes them through to a port with ssl by default
+func tlsTermination(sslEnabled bool, exposure *brokerv1beta1.ExposureType) string {
+	if exposure != nil && exposure.TLSTermination != "" {
+		return exposure.TLSTermination
+	}
+	if sslEnabled {
+		return brokerv1beta1.TLSTerminationPassthrough
+	}
+	return ""
+}
+
+// exposedHost returns the host of the ingress, route or tls route of a service, empty when openshift
+// or the gateway assign it
+func exposedHost(customResource *brokerv1beta1.ActiveMQArtemis, serviceName string, ordinal string, exposeMode string, exposure *brokerv1beta1.ExposureType) string {
+	mode := resolveExposeMode(exposeMode)
+	if mode != brokerv1beta1.ExposeModeIngress && mode != brokerv1beta1.ExposeModeRoute && mode != brokerv1beta1.ExposeModeGateway {
+		return ""
+	}
+	if exposure != nil && exposure.Host != "" {
+		return resolveExposure(exposure, ordinal).Host
+	}
+	domain := customResource.Spec.IngressDomain
+	switch {
+	case mode == brokerv1beta1.ExposeModeIngress:
+		return ingresses.HostForService(serviceName, domain)
+	case domain == "":
+		return ""
+	case mode == brokerv1beta1.ExposeModeRoute:
+		return serviceName + "-rte." + domain
+	default:
+		return gateways.HostForService(serviceName, domain)
+	}
+}
+
+// addRouteCertificates adds the certificate of the tls secret of the exposure to a route that ends
+// the tls connections, and the ca of the ssl secret of the port to a route that reencrypts them
+func addRouteCertificates(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, route *routev1.Route, exposure *brokerv1beta1.ExposureType, sslSecretName string) {
+	if route.Spec.TLS == nil || route.Spec.TLS.Termination == routev1.TLSTerminationPassthrough {
+		return
+	}
+	if exposure != nil && exposure.TLSSecret != "" {
+		if secret := getExposureSecret(customResource, client, exposure.TLSSecret); secret != nil {
+			route.Spec.TLS.Certificate = string(secret.Data[corev1.TLSCertKey])
+			route.Spec.TLS.Key = string(secret.Data[corev1.TLSPrivateKeyKey])
+			route.Spec.TLS.CACertificate = string(secret.Data[jolokia_client.TrustSecretCAKey])
+		}
+	}
+	if route.Spec.TLS.Termination == routev1.TLSTerminationReencrypt && sslSecretName != "" {
+		if secret := getExposureSecret(customResource, client, sslSecretName); secret != nil {
+			route.Spec.TLS.DestinationCACertificate = string(secret.Data[jolokia_client.TrustSecretCAKey])
+		}
+	}
+}
+
+func getExposureSecret(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, secretName string) *corev1.Secret {
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: customResource.Namespace}, secret); err != nil {
+		clog.Error(err, "failed to retrieve the secret of the exposure", "secret", secretName)
+		return nil
+	}
+	return secret
+}
+
+// exposeServiceWithType changes the type of the service of a broker pod, it keeps the node ports
+// kubernetes allocated
+func (reconciler *ActiveMQArtemisReconcilerImpl) exposeServiceWithType(service *corev1.Service, serviceType corev1.ServiceType, exposure *brokerv1beta1.ExposureType) {
+	service.Spec.Type = serviceType
+
+	if deployed := reconciler.getFromDeployed(reflect.TypeOf(corev1.Service{}), service.Name); deployed != nil {
+		for index := range service.Spec.Ports {
+			for _, deployedPort := range deployed.(*corev1.Service).Spec.Ports {
+				if deployedPort.Name == service.Spec.Ports[index].Name {
+					service.Spec.Ports[index].NodePort = deployedPort.NodePort
+				}
+			}
+		}
+	}
+
+	if exposure == nil {
+		return
+	}
+	applyExposureAnnotations(service, exposure)
+	if serviceType == corev1.ServiceTypeLoadBalancer && exposure.ClassName != "" {
+		className := exposure.ClassName
+		service.Spec.LoadBalancerClass = &className
+	}
+}
+
+func applyExposureAnnotations(obj metav1.Object, exposure *brokerv1beta1.ExposureType) {
+	if exposure == nil || len(exposure.Annotations) == 0 {
+		return
+	}
+	annotations := map[string]string{}
+	for key, value := range obj.GetAnnotations() {
+		annotations[key] = value
+	}
+	for key, value := range exposure.Annotations {
+		annotations[key] = value
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// ProcessTLSRoutes syncs the tls routes of the ports exposed with the gateway mode and deletes the
+// ones that are no longer desired
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessTLSRoutes(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, client rtclient.Client, scheme *runtime.Scheme) {
+	reqLogger := ctrl.Log.WithValues("ActiveMQArtemis Name", customResource.Name)
+
+	requested := reconciler.requestedTLSRoutes
+	reconciler.requestedTLSRoutes = nil
+	if !environments.DetectGatewayAPI() {
+		return
+	}
+
+	desired := map[string]bool{}
+	for _, tlsRoute := range requested {
+		desired[tlsRoute.GetName()] = true
+		if err := syncUnstructured(customResource, tlsRoute, client, scheme); err != nil {
+			reqLogger.Error(err, "unable to sync the tls route", "name", tlsRoute.GetName())
+		}
+	}
+
+	deployed := gateways.NewTLSRouteList()
+	if err := client.List(context.TODO(), deployed, rtclient.InNamespace(customResource.Namespace), rtclient.MatchingLabels(namer.LabelBuilder.Labels())); err != nil {
+		reqLogger.Error(err, "unable to list the tls routes")
+		return
+	}
+	for index := range deployed.Items {
+		tlsRoute := &deployed.Items[index]
+		if desired[tlsRoute.GetName()] || !metav1.IsControlledBy(tlsRoute, customResource) {
+			continue
+		}
+		if err := deleteUnstructured(tlsRoute, tlsRoute.GetNamespace(), tlsRoute.GetName(), client); err != nil {
+			reqLogger.Error(err, "unable to delete the tls route", "name", tlsRoute.GetName())
+		}
+	}
+}
+
+// UpdateAcceptorsStatus publishes the endpoints and the client connection urls of the acceptors,
+// it requeues while an external address is not assigned as the services and the exposing
+// resources are not watched
+func UpdateAcceptorsStatus(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) ctrl.Result {
+	var acceptorsStatus []brokerv1beta1.AcceptorStatus
+	pending := false
+	for _, acceptor := range customResource.Spec.Acceptors {
+		acceptorStatus, acceptorPending := newAcceptorStatus(customResource, client, acceptor)
+		pending = pending || acceptorPending
+		acceptorsStatus = append(acceptorsStatus, acceptorStatus)
+	}
+	customResource.Status.Acceptors = acceptorsStatus
+
+	if pending {
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}
+	}
+	return ctrl.Result{}
+}
+
+// newAcceptorStatus reads the endpoints of an acceptor, pending while an external address of the
+// exposed acceptor is not assigned
+func newAcceptorStatus(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, acceptor brokerv1beta1.AcceptorType) (brokerv1beta1.AcceptorStatus, bool) {
+	acceptorStatus := brokerv1beta1.AcceptorStatus{
+		Name:       acceptor.Name,
+		Port:       acceptor.Port,
+		Protocols:  acceptorProtocols(acceptor),
+		SSLEnabled: acceptor.SSLEnabled,
+		// the ingress or route may end the tls connections of the clients
+		ExternalSSLEnabled: acceptor.Expose && tlsTermination(acceptor.SSLEnabled, acceptor.Exposure) != "",
+	}
+	pending := false
+	endpointFor := func(serviceName string) brokerv1beta1.AcceptorEndpointStatus {
+		endpoint := brokerv1beta1.AcceptorEndpointStatus{Service: serviceName}
+		if acceptor.Expose {
+			endpoint = externalEndpoint(customResource, client, serviceName, acceptor.Port, acceptorStatus.ExternalSSLEnabled, acceptor.ExposeMode, acceptor.Exposure)
+			pending = pending || endpoint.ExternalPort == 0
+		}
+		endpoint.Host = serviceName + "." + customResource.Namespace + ".svc." + common.GetClusterDomain()
+		endpoint.InternalURLs = connectionURLs(endpoint.Host, acceptor.Port, acceptorStatus.Protocols, acceptor.SSLEnabled)
+		if endpoint.ExternalHost != "" && endpoint.ExternalPort != 0 {
+			endpoint.ExternalURLs = connectionURLs(endpoint.ExternalHost, endpoint.ExternalPort, acceptorStatus.Protocols, acceptorStatus.ExternalSSLEnabled)
+		}
+		return endpoint
+	}
+	if hasLoadBalancedService(acceptor) {
+		endpoint := endpointFor(loadBalancedServiceName(customResource, acceptor.Name))
+		acceptorStatus.LoadBalanced = &endpoint
+	}
+	if hasPerPodServices(acceptor) {
+		for i := int32(0); i < getBrokerPodCount(customResource); i++ {
+			ordinal := strconv.Itoa(int(i))
+			endpoint := endpointFor(perPodServiceName(customResource, acceptor.Name, ordinal))
+			endpoint.Ordinal = ordinal
+			acceptorStatus.Pods = append(acceptorStatus.Pods, endpoint)
+		}
+	}
+	return acceptorStatus, pending
+}
+
+// acceptorProtocols are the protocols the broker configuration of the acceptor enables
+func acceptorProtocols(acceptor brokerv1beta1.AcceptorType) []string {
+	protocols := acceptor.Protocols
+	if protocols == "" || strings.ToLower(protocols) == "all" {
+		protocols = "AMQP,CORE,HORNETQ,MQTT,OPENWIRE,STOMP"
+	}
+	var result []string
+	for _, protocol := range strings.Split(strings.ToUpper(protocols), ",") {
+		if protocol = strings.TrimSpace(protocol); protocol != "" {
+			result = appendUnique(result, protocol)
+		}
+	}
+	// the broker configuration always enables core on 61616
+	if acceptor.Port == 61616 {
+		result = appendUnique(result, "CORE")
+	}
+	return result
+}
+
+// connectionURLs are the urls the clients of each protocol connect with, keyed by the lower case
+// protocol, hornetq clients are not covered
+func connectionURLs(host string, port int32, protocols []string, sslEnabled bool) map[string]string {
+	address := fmt.Sprintf("%s:%d", host, port)
+	urls := map[string]string{}
+	for _, protocol := range protocols {
+		var url string
+		switch protocol {
+		case "CORE":
+			url = "tcp://" + address
+			if sslEnabled {
+				url += "?sslEnabled=true"
+			}
+		case "AMQP":
+			url = "amqp://" + address
+			if sslEnabled {
+				url = "amqps://" + address
+			}
+		case "OPENWIRE", "MQTT":
+			url = "tcp://" + address
+			if sslEnabled {
+				url = "ssl://" + address
+			}
+		case "STOMP":
+			url = "stomp://" + address
+			if sslEnabled {
+				url = "stomp+ssl://" + address
+			}
+		default:
+			continue
+		}
+		urls[strings.ToLower(protocol)] = url
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+	return urls
+}
+
+// externalEndpoint reads the external address of a service from the resource that exposes it,
+// the port stays unset until the address is assigned
+func externalEndpoint(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, serviceName string, port int32, externalSSLEnabled bool, exposeMode string, exposure *brokerv1beta1.ExposureType) brokerv1beta1.AcceptorEndpointStatus {
+	endpoint := brokerv1beta1.AcceptorEndpointStatus{Service: serviceName}
+	key := types.NamespacedName{Name: serviceName, Namespace: customResource.Namespace}
+
+	exposedPort := int32(80)
+	if externalSSLEnabled {
+		exposedPort = 443
+	}
+
+	switch resolveExposeMode(exposeMode) {
+	case brokerv1beta1.ExposeModeLoadBalancer:
+		service := &corev1.Service{}
+		if client.Get(context.TODO(), key, service) != nil {
+			break
+		}
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			endpoint.ExternalHost = ingress.Hostname
+			if endpoint.ExternalHost == "" {
+				endpoint.ExternalHost = ingress.IP
+			}
+			endpoint.ExternalPort = port
+			break
+		}
+	case brokerv1beta1.ExposeModeNodePort:
+		service := &corev1.Service{}
+		if client.Get(context.TODO(), key, service) != nil {
+			break
+		}
+		// the node port is open on every node, the node of a broker pod of the service is published
+		if endpoint.ExternalHost = nodePortHost(client, service); endpoint.ExternalHost == "" {
+			break
+		}
+		for _, servicePort := range service.Spec.Ports {
+			if servicePort.Port == port {
+				endpoint.ExternalPort = servicePort.NodePort
+				break
+			}
+		}
+	case brokerv1beta1.ExposeModeGateway:
+		tlsRoute := gateways.NewTLSRoute()
+		if client.Get(context.TODO(), types.NamespacedName{Name: serviceName + "-tlsr", Namespace: customResource.Namespace}, tlsRoute) != nil {
+			break
+		}
+		if hostnames, _, _ := unstructured.NestedStringSlice(tlsRoute.Object, "spec", "hostnames"); len(hostnames) > 0 {
+			endpoint.ExternalHost = hostnames[0]
+		}
+		if exposure == nil || exposure.GatewayRef == nil {
+			break
+		}
+		gatewayKey := types.NamespacedName{Name: exposure.GatewayRef.Name, Namespace: exposure.GatewayRef.Namespace}
+		if gatewayKey.Namespace == "" {
+			gatewayKey.Namespace = customResource.Namespace
+		}
+		gateway := gateways.NewGateway()
+		if client.Get(context.TODO(), gatewayKey, gateway) == nil {
+			endpoint.ExternalPort = gateways.ListenerPort(gateway, exposure.GatewayRef.SectionName)
+		}
+	case brokerv1beta1.ExposeModeRoute:
+		// without a domain the host is assigned by openshift
+		route := &routev1.Route{}
+		if client.Get(context.TODO(), types.NamespacedName{Name: serviceName + "-rte", Namespace: customResource.Namespace}, route) != nil || route.Spec.Host == "" {
+			break
+		}
+		endpoint.ExternalHost = route.Spec.Host
+		endpoint.ExternalPort = exposedPort
+	default:
+		ingress := &netv1.Ingress{}
+		if client.Get(context.TODO(), types.NamespacedName{Name: serviceName + "-ing", Namespace: customResource.Namespace}, ingress) != nil || len(ingress.Spec.Rules) == 0 {
+			break
+		}
+		endpoint.ExternalHost = ingress.Spec.Rules[0].Host
+		endpoint.ExternalPort = exposedPort
+	}
+	return endpoint
+}
+
+// nodePortHost is the address of the node of the first broker pod the service selects
+func nodePortHost(client rtclient.Client, service *corev1.Service) string {
+	pods := &corev1.PodList{}
+	if len(service.Spec.Selector) == 0 || client.List(context.TODO(), pods, rtclient.InNamespace(service.Namespace), rtclient.MatchingLabels(service.Spec.Selector)) != nil {
+		return ""
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	for _, pod := range pods.Items {
+		if pod.Status.HostIP != "" {
+			return pod.Status.HostIP
+		}
+	}
+	return ""
+}
+
+func validateExposeModes(customResource *brokerv1beta1.ActiveMQArtemis) *metav1.Condition {
+	type candidate struct {
+		contextMessage string
+		expose         bool
+		sslEnabled     bool
+		exposeMode     string
+		exposure       *brokerv1beta1.ExposureType
+		// the number of services that are exposed with the host of the exposure
+		services int32
+	}
+	podCount := getBrokerPodCount(customResource)
+	var candidates []candidate
+	for index, acceptor := range customResource.Spec.Acceptors {
+		services := int32(0)
+		if hasPerPodServices(acceptor) {
+			services += podCount
+		}
+		if hasLoadBalancedService(acceptor) {
+			services++
+		}
+		candidates = append(candidates, candidate{fmt.Sprintf(".Spec.Acceptors[%d]", index), acceptor.Expose, acceptor.SSLEnabled, acceptor.ExposeMode, acceptor.Exposure, services})
+	}
+	for index, connector := range customResource.Spec.Connectors {
+		candidates = append(candidates, candidate{fmt.Sprintf(".Spec.Connectors[%d]", index), connector.Expose, connector.SSLEnabled, connector.ExposeMode, connector.Exposure, podCount})
+	}
+	console := customResource.Spec.Console
+	candidates = append(candidates, candidate{".Spec.Console", console.Expose, console.SSLEnabled, console.ExposeMode, console.Exposure, podCount})
+
+	isOpenshift, _ := environments.DetectOpenshift()
+	for _, c := range candidates {
+		if !c.expose {
+			continue
+		}
+		exposure := c.exposure
+		if exposure == nil {
+			exposure = &brokerv1beta1.ExposureType{}
+		}
+		termination := tlsTermination(c.sslEnabled, exposure)
+		mode := resolveExposeMode(c.exposeMode)
+
+		var reason string
+		switch {
+		case c.exposeMode == brokerv1beta1.ExposeModeRoute && !isOpenshift:
+			reason = "ExposeMode route requires OpenShift"
+		case mode == brokerv1beta1.ExposeModeGateway && !c.sslEnabled:
+			reason = "ExposeMode gateway requires sslEnabled, the tls routes pass the tls connections through"
+		case mode == brokerv1beta1.ExposeModeGateway && (exposure.GatewayRef == nil || exposure.GatewayRef.Name == ""):
+			reason = "ExposeMode gateway requires exposure.gatewayRef.name"
+		case mode == brokerv1beta1.ExposeModeGateway && !environments.DetectGatewayAPI():
+			reason = "ExposeMode gateway requires the TLSRoute kind of the gateway api"
+		case exposure.TLSTermination != "" && mode != brokerv1beta1.ExposeModeIngress && mode != brokerv1beta1.ExposeModeRoute && !(mode == brokerv1beta1.ExposeModeGateway && termination == brokerv1beta1.TLSTerminationPassthrough):
+			reason = fmt.Sprintf("Exposure.TLSTermination %v requires the ingress or route expose mode", termination)
+		case termination == brokerv1beta1.TLSTerminationPassthrough && !c.sslEnabled:
+			reason = "Exposure.TLSTermination passthrough requires sslEnabled"
+		case termination == brokerv1beta1.TLSTerminationReencrypt && !c.sslEnabled:
+			reason = "Exposure.TLSTermination reencrypt requires sslEnabled"
+		case termination == brokerv1beta1.TLSTerminationEdge && c.sslEnabled:
+			reason = "Exposure.TLSTermination edge requires the port without sslEnabled"
+		case exposure.TLSSecret != "" && termination != brokerv1beta1.TLSTerminationEdge && termination != brokerv1beta1.TLSTerminationReencrypt:
+			reason = "Exposure.TLSSecret requires the edge or reencrypt tls termination"
+		case exposure.Path != "" && (termination == brokerv1beta1.TLSTerminationPassthrough || mode != brokerv1beta1.ExposeModeIngress && mode != brokerv1beta1.ExposeModeRoute):
+			reason = "Exposure.Path requires an ingress or a route that does not pass the tls connections through"
+		case exposure.Host != "" && c.services > 1 && !strings.Contains(exposure.Host, brokerv1beta1.ExposureHostOrdinalPlaceholder):
+			reason = fmt.Sprintf("Exposure.Host requires %v as more than one service is exposed", brokerv1beta1.ExposureHostOrdinalPlaceholder)
+		}
+		if reason != "" {
+			return &metav1.Condition{
+				Type:    brokerv1beta1.ValidConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  brokerv1beta1.ValidConditionInvalidExposeModeReason,
+				Message: fmt.Sprintf("%v.%v", c.contextMessage, reason),
+			}
+		}
+	}
+	return nil
+}
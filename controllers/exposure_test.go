@@ -0,0 +1,551 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/gateways"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigureAcceptorsExposureModes(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			IngressDomain: "example.com",
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqp", Port: 5672, Expose: true, Exposure: &brokerv1beta1.ExposureType{
+					Annotations: map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "TCP"},
+					ClassName:   "nginx",
+				}},
+				{Name: "lb", Port: 5673, Expose: true, ExposeMode: brokerv1beta1.ExposeModeLoadBalancer, Exposure: &brokerv1beta1.ExposureType{
+					Annotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-type": "nlb"},
+					ClassName:   "service.k8s.aws/nlb",
+				}},
+				{Name: "np", Port: 5674, Expose: true, ExposeMode: brokerv1beta1.ExposeModeNodePort},
+				{Name: "tls", Port: 5675, Expose: true, SSLEnabled: true, ExposeMode: brokerv1beta1.ExposeModeGateway, Exposure: &brokerv1beta1.ExposureType{
+					GatewayRef: &brokerv1beta1.GatewayRefType{Name: "brokers", Namespace: "gateways", SectionName: "tls"},
+				}},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+
+	deployed := &v1.Service{}
+	deployed.Name = "ex-aao-np-0-svc"
+	deployed.Spec.Ports = []v1.ServicePort{{Name: "np-0", Port: 5674, NodePort: 30674}}
+
+	reconciler := ActiveMQArtemisReconcilerImpl{deployed: map[reflect.Type][]rtclient.Object{reflect.TypeOf(v1.Service{}): {deployed}}}
+	reconciler.configureAcceptorsExposure(cr, *namer, fake.NewClientBuilder().Build(), nil)
+
+	services := map[string]*v1.Service{}
+	var ingresses []*netv1.Ingress
+	for _, obj := range reconciler.requestedResources {
+		switch resource := obj.(type) {
+		case *v1.Service:
+			services[resource.Name] = resource
+		case *netv1.Ingress:
+			ingresses = append(ingresses, resource)
+		}
+	}
+
+	// the ingress mode is the default on kubernetes
+	if assert.Len(t, ingresses, 1) {
+		assert.Equal(t, "ex-aao-amqp-0-svc-ing", ingresses[0].Name)
+		assert.Equal(t, "nginx", *ingresses[0].Spec.IngressClassName)
+		assert.Equal(t, "TCP", ingresses[0].Annotations["nginx.ingress.kubernetes.io/backend-protocol"])
+	}
+	assert.Equal(t, v1.ServiceTypeClusterIP, services["ex-aao-amqp-0-svc"].Spec.Type)
+
+	loadBalancer := services["ex-aao-lb-0-svc"]
+	assert.Equal(t, v1.ServiceTypeLoadBalancer, loadBalancer.Spec.Type)
+	assert.Equal(t, "service.k8s.aws/nlb", *loadBalancer.Spec.LoadBalancerClass)
+	assert.Equal(t, "nlb", loadBalancer.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"])
+
+	// the allocated node port is kept
+	nodePort := services["ex-aao-np-0-svc"]
+	assert.Equal(t, v1.ServiceTypeNodePort, nodePort.Spec.Type)
+	assert.Equal(t, int32(30674), nodePort.Spec.Ports[0].NodePort)
+
+	if assert.Len(t, reconciler.requestedTLSRoutes, 1) {
+		tlsRoute := reconciler.requestedTLSRoutes[0]
+		assert.Equal(t, "ex-aao-tls-0-svc-tlsr", tlsRoute.GetName())
+		parentRefs, _, _ := unstructured.NestedSlice(tlsRoute.Object, "spec", "parentRefs")
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "brokers", "namespace": "gateways", "sectionName": "tls"}}, parentRefs)
+		hostnames, _, _ := unstructured.NestedStringSlice(tlsRoute.Object, "spec", "hostnames")
+		assert.Equal(t, []string{"ex-aao-tls-0-svc-tlsr.example.com"}, hostnames)
+		rules, _, _ := unstructured.NestedSlice(tlsRoute.Object, "spec", "rules")
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "ex-aao-tls-0-svc", "port": int64(5675)}}, rules[0].(map[string]interface{})["backendRefs"])
+	}
+	assert.Equal(t, v1.ServiceTypeClusterIP, services["ex-aao-tls-0-svc"].Spec.Type)
+}
+
+func TestProcessResourcesIngressAnnotations(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			IngressDomain: "example.com",
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqp", Port: 5672, Expose: true, Exposure: &brokerv1beta1.ExposureType{
+					Annotations: map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "TCP"},
+				}},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+	client := fake.NewClientBuilder().Build()
+
+	reconciler := ActiveMQArtemisReconcilerImpl{}
+	reconciler.configureAcceptorsExposure(cr, *namer, client, nil)
+	for _, obj := range reconciler.requestedResources {
+		if obj.GetName() == "ex-aao-amqp-0-svc-ing" {
+			assert.NoError(t, client.Create(context.TODO(), obj))
+		}
+	}
+	deployed := &netv1.Ingress{}
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-amqp-0-svc-ing", Namespace: "test"}, deployed))
+
+	// a change of the annotations only updates the ingress
+	cr.Spec.Acceptors[0].Exposure.Annotations = nil
+	reconciler = ActiveMQArtemisReconcilerImpl{deployed: map[reflect.Type][]rtclient.Object{reflect.TypeOf(netv1.Ingress{}): {deployed}}}
+	reconciler.configureAcceptorsExposure(cr, *namer, client, nil)
+	var requested []rtclient.Object
+	for _, obj := range reconciler.requestedResources {
+		if _, ok := obj.(*netv1.Ingress); ok {
+			requested = append(requested, obj)
+		}
+	}
+	reconciler.requestedResources = requested
+	assert.NoError(t, reconciler.ProcessResources(cr, client, nil))
+
+	updated := &netv1.Ingress{}
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-amqp-0-svc-ing", Namespace: "test"}, updated))
+	assert.Empty(t, updated.Annotations)
+}
+
+func TestConfigureAcceptorsLoadBalancedService(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			IngressDomain: "example.com",
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size: common.Int32ToPtr(2),
+			},
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqp", Port: 5672, Expose: true, ServiceMode: brokerv1beta1.ServiceModeLoadBalanced},
+				{Name: "core", Port: 61616, Expose: true, ServiceMode: brokerv1beta1.ServiceModeBoth},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+
+	reconciler := ActiveMQArtemisReconcilerImpl{}
+	reconciler.configureAcceptorsExposure(cr, *namer, fake.NewClientBuilder().Build(), nil)
+
+	services := map[string]*v1.Service{}
+	ingresses := map[string]*netv1.Ingress{}
+	for _, obj := range reconciler.requestedResources {
+		switch resource := obj.(type) {
+		case *v1.Service:
+			services[resource.Name] = resource
+		case *netv1.Ingress:
+			ingresses[resource.Name] = resource
+		}
+	}
+
+	assert.Len(t, services, 4)
+	assert.NotContains(t, services, "ex-aao-amqp-0-svc")
+	assert.Contains(t, services, "ex-aao-core-0-svc")
+	assert.Contains(t, services, "ex-aao-core-1-svc")
+
+	// the load balanced service selects every ready broker pod
+	for _, name := range []string{"ex-aao-amqp-svc", "ex-aao-core-svc"} {
+		service := services[name]
+		if assert.NotNil(t, service, name) {
+			assert.Equal(t, namer.LabelBuilder.Labels(), service.Spec.Selector)
+			assert.False(t, service.Spec.PublishNotReadyAddresses)
+		}
+	}
+	assert.True(t, services["ex-aao-core-0-svc"].Spec.PublishNotReadyAddresses)
+
+	assert.Len(t, ingresses, 4)
+	if ingress := ingresses["ex-aao-amqp-svc-ing"]; assert.NotNil(t, ingress) {
+		assert.Equal(t, "ex-aao-amqp-svc-ing.example.com", ingress.Spec.Rules[0].Host)
+		assert.Equal(t, "amqp", ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Name)
+	}
+}
+
+func TestUpdateAcceptorsStatus(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			IngressDomain: "example.com",
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqp", Port: 5672, Expose: true, ServiceMode: brokerv1beta1.ServiceModeBoth},
+				{Name: "lb", Port: 5673, Expose: true, ExposeMode: brokerv1beta1.ExposeModeLoadBalancer, ServiceMode: brokerv1beta1.ServiceModeLoadBalanced},
+				{Name: "np", Port: 5674, Expose: true, ExposeMode: brokerv1beta1.ExposeModeNodePort},
+				{Name: "tls", Port: 5675, Expose: true, SSLEnabled: true, ExposeMode: brokerv1beta1.ExposeModeGateway, Exposure: &brokerv1beta1.ExposureType{
+					GatewayRef: &brokerv1beta1.GatewayRefType{Name: "brokers", Namespace: "gateways", SectionName: "tls"},
+				}},
+				{Name: "internal", Port: 5676},
+			},
+		},
+	}
+
+	ingress := &netv1.Ingress{}
+	ingress.Name = "ex-aao-amqp-svc-ing"
+	ingress.Namespace = "test"
+	ingress.Spec.Rules = []netv1.IngressRule{{Host: "ex-aao-amqp-svc-ing.example.com"}}
+	loadBalancer := &v1.Service{}
+	loadBalancer.Name = "ex-aao-lb-svc"
+	loadBalancer.Namespace = "test"
+	loadBalancer.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	nodePort := &v1.Service{}
+	nodePort.Name = "ex-aao-np-0-svc"
+	nodePort.Namespace = "test"
+	nodePort.Spec.Selector = map[string]string{"statefulset.kubernetes.io/pod-name": "ex-aao-ss-0"}
+	nodePort.Spec.Ports = []v1.ServicePort{{Name: "np-0", Port: 5674, NodePort: 30674}, {Name: "other", Port: 5699, NodePort: 30699}}
+	nodePortPod := &v1.Pod{}
+	nodePortPod.Name = "ex-aao-ss-0"
+	nodePortPod.Namespace = "test"
+	nodePortPod.Labels = nodePort.Spec.Selector
+	nodePortPod.Status.HostIP = "192.168.0.10"
+	tlsRoute := gateways.NewTLSRoute()
+	tlsRoute.SetName("ex-aao-tls-0-svc-tlsr")
+	tlsRoute.SetNamespace("test")
+	tlsRoute.Object["spec"] = map[string]interface{}{"hostnames": []interface{}{"ex-aao-tls-0-svc-tlsr.example.com"}}
+	gateway := gateways.NewGateway()
+	gateway.SetName("brokers")
+	gateway.SetNamespace("gateways")
+	gateway.Object["spec"] = map[string]interface{}{"listeners": []interface{}{
+		map[string]interface{}{"name": "http", "port": int64(80)},
+		map[string]interface{}{"name": "tls", "port": int64(8443)},
+	}}
+	client := fake.NewClientBuilder().WithObjects(ingress, loadBalancer, nodePort, nodePortPod, tlsRoute, gateway).Build()
+
+	// the per pod ingress of the amqp acceptor is not created yet
+	result := UpdateAcceptorsStatus(cr, client)
+	assert.True(t, result.RequeueAfter > 0)
+
+	if !assert.Len(t, cr.Status.Acceptors, 5) {
+		return
+	}
+	amqp := cr.Status.Acceptors[0]
+	assert.Equal(t, int32(5672), amqp.Port)
+	assert.Equal(t, []string{"AMQP", "CORE", "HORNETQ", "MQTT", "OPENWIRE", "STOMP"}, amqp.Protocols)
+	assert.Equal(t, &brokerv1beta1.AcceptorEndpointStatus{
+		Service:      "ex-aao-amqp-svc",
+		Host:         "ex-aao-amqp-svc.test.svc.cluster.local",
+		ExternalHost: "ex-aao-amqp-svc-ing.example.com",
+		ExternalPort: 80,
+		InternalURLs: map[string]string{
+			"amqp":     "amqp://ex-aao-amqp-svc.test.svc.cluster.local:5672",
+			"core":     "tcp://ex-aao-amqp-svc.test.svc.cluster.local:5672",
+			"mqtt":     "tcp://ex-aao-amqp-svc.test.svc.cluster.local:5672",
+			"openwire": "tcp://ex-aao-amqp-svc.test.svc.cluster.local:5672",
+			"stomp":    "stomp://ex-aao-amqp-svc.test.svc.cluster.local:5672",
+		},
+		ExternalURLs: map[string]string{
+			"amqp":     "amqp://ex-aao-amqp-svc-ing.example.com:80",
+			"core":     "tcp://ex-aao-amqp-svc-ing.example.com:80",
+			"mqtt":     "tcp://ex-aao-amqp-svc-ing.example.com:80",
+			"openwire": "tcp://ex-aao-amqp-svc-ing.example.com:80",
+			"stomp":    "stomp://ex-aao-amqp-svc-ing.example.com:80",
+		},
+	}, amqp.LoadBalanced)
+	if assert.Len(t, amqp.Pods, 1) {
+		assert.Equal(t, "0", amqp.Pods[0].Ordinal)
+		assert.Equal(t, "ex-aao-amqp-0-svc.test.svc.cluster.local", amqp.Pods[0].Host)
+		assert.Empty(t, amqp.Pods[0].ExternalURLs)
+	}
+
+	lb := cr.Status.Acceptors[1]
+	assert.Empty(t, lb.Pods)
+	assert.Equal(t, "10.0.0.1", lb.LoadBalanced.ExternalHost)
+	assert.Equal(t, int32(5673), lb.LoadBalanced.ExternalPort)
+
+	np := cr.Status.Acceptors[2]
+	assert.Equal(t, "192.168.0.10", np.Pods[0].ExternalHost)
+	assert.Equal(t, int32(30674), np.Pods[0].ExternalPort)
+	assert.Equal(t, "tcp://192.168.0.10:30674", np.Pods[0].ExternalURLs["core"])
+
+	tls := cr.Status.Acceptors[3]
+	assert.True(t, tls.SSLEnabled)
+	assert.Equal(t, "ex-aao-tls-0-svc-tlsr.example.com", tls.Pods[0].ExternalHost)
+	assert.Equal(t, int32(8443), tls.Pods[0].ExternalPort)
+	assert.Equal(t, "tcp://ex-aao-tls-0-svc-tlsr.example.com:8443?sslEnabled=true", tls.Pods[0].ExternalURLs["core"])
+	assert.Equal(t, "amqps://ex-aao-tls-0-svc-tlsr.example.com:8443", tls.Pods[0].ExternalURLs["amqp"])
+	assert.Equal(t, "amqps://ex-aao-tls-0-svc.test.svc.cluster.local:5675", tls.Pods[0].InternalURLs["amqp"])
+
+	// an acceptor that is not exposed only has internal urls
+	internal := cr.Status.Acceptors[4]
+	assert.Nil(t, internal.LoadBalanced)
+	if assert.Len(t, internal.Pods, 1) {
+		assert.Empty(t, internal.Pods[0].ExternalPort)
+		assert.Equal(t, "tcp://ex-aao-internal-0-svc.test.svc.cluster.local:5676", internal.Pods[0].InternalURLs["core"])
+	}
+
+	cr.Spec.Acceptors[0].ServiceMode = brokerv1beta1.ServiceModeLoadBalanced
+	result = UpdateAcceptorsStatus(cr, client)
+	assert.True(t, result.IsZero())
+}
+
+func TestAcceptorProtocols(t *testing.T) {
+	assert.Equal(t, []string{"AMQP"}, acceptorProtocols(brokerv1beta1.AcceptorType{Protocols: "amqp", Port: 5672}))
+	assert.Equal(t, []string{"AMQP", "CORE"}, acceptorProtocols(brokerv1beta1.AcceptorType{Protocols: "amqp, core", Port: 5672}))
+	// the broker configuration adds core on 61616
+	assert.Equal(t, []string{"AMQP", "CORE"}, acceptorProtocols(brokerv1beta1.AcceptorType{Protocols: "AMQP", Port: 61616}))
+
+	urls := connectionURLs("broker", 61617, []string{"HORNETQ", "OPENWIRE", "STOMP"}, true)
+	assert.Equal(t, map[string]string{"openwire": "ssl://broker:61617", "stomp": "stomp+ssl://broker:61617"}, urls)
+	assert.Nil(t, connectionURLs("broker", 61617, []string{"HORNETQ"}, false))
+}
+
+func TestExposureTLSTermination(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size: common.Int32ToPtr(2),
+			},
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "ws", Port: 61614, Expose: true, Exposure: &brokerv1beta1.ExposureType{
+					Host:           "ws-$(ORDINAL).brokers.example.com",
+					Path:           "/ws",
+					TLSTermination: brokerv1beta1.TLSTerminationEdge,
+					TLSSecret:      "brokers-tls",
+					ClassName:      "nginx",
+				}},
+			},
+			Console: brokerv1beta1.ConsoleType{
+				Expose:     true,
+				SSLEnabled: true,
+				ExposeMode: brokerv1beta1.ExposeModeRoute,
+				Exposure: &brokerv1beta1.ExposureType{
+					Host:           "console$(ORDINAL).brokers.example.com",
+					TLSTermination: brokerv1beta1.TLSTerminationReencrypt,
+					TLSSecret:      "brokers-tls",
+					Annotations:    map[string]string{"haproxy.router.openshift.io/timeout": "5m"},
+				},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+
+	tlsSecret := &v1.Secret{}
+	tlsSecret.Name = "brokers-tls"
+	tlsSecret.Namespace = "test"
+	tlsSecret.Data = map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key"), "ca.crt": []byte("ca")}
+	consoleSecret := &v1.Secret{}
+	consoleSecret.Name = "ex-aao-console-secret"
+	consoleSecret.Namespace = "test"
+	consoleSecret.Data = map[string][]byte{"ca.crt": []byte("broker-ca")}
+	client := fake.NewClientBuilder().WithObjects(tlsSecret, consoleSecret).Build()
+
+	reconciler := ActiveMQArtemisReconcilerImpl{}
+	reconciler.configureAcceptorsExposure(cr, *namer, client, nil)
+	reconciler.configureConsoleExposure(cr, *namer, client, nil)
+
+	ingresses := map[string]*netv1.Ingress{}
+	routes := map[string]*routev1.Route{}
+	for _, obj := range reconciler.requestedResources {
+		switch resource := obj.(type) {
+		case *netv1.Ingress:
+			ingresses[resource.Name] = resource
+		case *routev1.Route:
+			routes[resource.Name] = resource
+		}
+	}
+
+	if ingress := ingresses["ex-aao-ws-1-svc-ing"]; assert.NotNil(t, ingress) {
+		assert.Equal(t, "ws-1.brokers.example.com", ingress.Spec.Rules[0].Host)
+		assert.Equal(t, "/ws", ingress.Spec.Rules[0].HTTP.Paths[0].Path)
+		assert.Equal(t, []netv1.IngressTLS{{Hosts: []string{"ws-1.brokers.example.com"}, SecretName: "brokers-tls"}}, ingress.Spec.TLS)
+		assert.Empty(t, ingress.Annotations)
+		assert.Equal(t, "nginx", *ingress.Spec.IngressClassName)
+	}
+
+	if route := routes["ex-aao-wconsj-0-svc-rte"]; assert.NotNil(t, route) {
+		assert.Equal(t, "console0.brokers.example.com", route.Spec.Host)
+		assert.Equal(t, routev1.TLSTerminationReencrypt, route.Spec.TLS.Termination)
+		assert.Equal(t, routev1.InsecureEdgeTerminationPolicyRedirect, route.Spec.TLS.InsecureEdgeTerminationPolicy)
+		assert.Equal(t, "crt", route.Spec.TLS.Certificate)
+		assert.Equal(t, "key", route.Spec.TLS.Key)
+		assert.Equal(t, "ca", route.Spec.TLS.CACertificate)
+		assert.Equal(t, "broker-ca", route.Spec.TLS.DestinationCACertificate)
+		assert.Equal(t, "5m", route.Annotations["haproxy.router.openshift.io/timeout"])
+	}
+
+	// the annotations removed from the exposure are removed from the route
+	deployedRoute := routes["ex-aao-wconsj-0-svc-rte"]
+	cr.Spec.Console.Exposure.Annotations = nil
+	reconciler = ActiveMQArtemisReconcilerImpl{deployed: map[reflect.Type][]rtclient.Object{reflect.TypeOf(routev1.Route{}): {deployedRoute}}}
+	reconciler.configureConsoleExposure(cr, *namer, client, nil)
+	for _, obj := range reconciler.requestedResources {
+		if route, ok := obj.(*routev1.Route); ok && route.Name == "ex-aao-wconsj-0-svc-rte" {
+			assert.Empty(t, route.Annotations)
+		}
+	}
+
+	// the reencrypt termination on an ingress asks the controller for tls to the broker
+	cr.Spec.Console.ExposeMode = brokerv1beta1.ExposeModeIngress
+	reconciler = ActiveMQArtemisReconcilerImpl{}
+	reconciler.configureConsoleExposure(cr, *namer, client, nil)
+	for _, obj := range reconciler.requestedResources {
+		if ingress, ok := obj.(*netv1.Ingress); ok && ingress.Name == "ex-aao-wconsj-0-svc-ing" {
+			assert.Equal(t, map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS"}, ingress.Annotations)
+			assert.Equal(t, "brokers-tls", ingress.Spec.TLS[0].SecretName)
+		}
+	}
+
+	// the clients connect with tls to the ingress that ends it
+	acceptorStatus, _ := newAcceptorStatus(cr, client, cr.Spec.Acceptors[0])
+	assert.False(t, acceptorStatus.SSLEnabled)
+	assert.True(t, acceptorStatus.ExternalSSLEnabled)
+
+	// the certificates from cert-manager cover the hosts of the exposure
+	assert.Contains(t, serviceDNSNames(cr, "wconsj", true, cr.Spec.Console.ExposeMode, cr.Spec.Console.Exposure), "console1.brokers.example.com")
+}
+
+func TestProcessTLSRoutes(t *testing.T) {
+	stateManager := common.GetStateManager()
+	stateManager.SetState(common.TLSRouteKind, true)
+	defer stateManager.SetState(common.TLSRouteKind, false)
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{APIVersion: brokerv1beta1.GroupVersion.String(), Kind: "ActiveMQArtemis"},
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			IngressDomain: "example.com",
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "tls", Port: 5675, Expose: true, SSLEnabled: true, ExposeMode: brokerv1beta1.ExposeModeGateway, Exposure: &brokerv1beta1.ExposureType{
+					GatewayRef: &brokerv1beta1.GatewayRefType{Name: "brokers", Namespace: "gateways", SectionName: "tls"},
+				}},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+	client := fake.NewClientBuilder().Build()
+
+	reconciler := ActiveMQArtemisReconcilerImpl{}
+	reconciler.configureAcceptorsExposure(cr, *namer, client, nil)
+	reconciler.ProcessTLSRoutes(cr, *namer, client, nil)
+	assert.Empty(t, reconciler.requestedTLSRoutes)
+
+	tlsRoute := gateways.NewTLSRoute()
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-tls-0-svc-tlsr", Namespace: "test"}, tlsRoute))
+	assert.Equal(t, "ex-aao", tlsRoute.GetOwnerReferences()[0].Name)
+
+	// a tls route is deleted with its exposure
+	cr.Spec.Acceptors[0].Expose = false
+	reconciler = ActiveMQArtemisReconcilerImpl{}
+	reconciler.configureAcceptorsExposure(cr, *namer, client, nil)
+	reconciler.ProcessTLSRoutes(cr, *namer, client, nil)
+	assert.True(t, k8serrors.IsNotFound(client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-tls-0-svc-tlsr", Namespace: "test"}, gateways.NewTLSRoute())))
+}
+
+func TestValidateExposeModes(t *testing.T) {
+	stateManager := common.GetStateManager()
+	defer stateManager.SetState(common.TLSRouteKind, false)
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			IngressDomain: "example.com",
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "tls", Port: 5675, Expose: true, SSLEnabled: true, ExposeMode: brokerv1beta1.ExposeModeGateway, Exposure: &brokerv1beta1.ExposureType{
+					GatewayRef: &brokerv1beta1.GatewayRefType{Name: "brokers", Namespace: "gateways", SectionName: "tls"},
+				}},
+			},
+		},
+	}
+	stateManager.SetState(common.TLSRouteKind, true)
+	assert.Nil(t, validateExposeModes(cr))
+
+	stateManager.SetState(common.TLSRouteKind, false)
+	condition := validateExposeModes(cr)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, brokerv1beta1.ValidConditionInvalidExposeModeReason, condition.Reason)
+		assert.Equal(t, ".Spec.Acceptors[0].ExposeMode gateway requires the TLSRoute kind of the gateway api", condition.Message)
+	}
+
+	cr = &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			IngressDomain: "example.com",
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "tls", Port: 5675, Expose: true, SSLEnabled: false, ExposeMode: brokerv1beta1.ExposeModeGateway, Exposure: &brokerv1beta1.ExposureType{
+					GatewayRef: &brokerv1beta1.GatewayRefType{Name: "brokers", Namespace: "gateways", SectionName: "tls"},
+				}},
+			},
+		},
+	}
+	condition = validateExposeModes(cr)
+	if assert.NotNil(t, condition) {
+		assert.Contains(t, condition.Message, "gateway requires sslEnabled")
+	}
+
+	cr = &brokerv1beta1.ActiveMQArtemis{
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Console: brokerv1beta1.ConsoleType{Expose: true, ExposeMode: brokerv1beta1.ExposeModeRoute},
+		},
+	}
+	condition = validateExposeModes(cr)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, ".Spec.Console.ExposeMode route requires OpenShift", condition.Message)
+	}
+
+	for _, invalid := range []struct {
+		exposeMode string
+		sslEnabled bool
+		exposure   brokerv1beta1.ExposureType
+		message    string
+	}{
+		{brokerv1beta1.ExposeModeLoadBalancer, true, brokerv1beta1.ExposureType{TLSTermination: brokerv1beta1.TLSTerminationPassthrough}, "Exposure.TLSTermination passthrough requires the ingress or route expose mode"},
+		{brokerv1beta1.ExposeModeIngress, false, brokerv1beta1.ExposureType{TLSTermination: brokerv1beta1.TLSTerminationReencrypt}, "Exposure.TLSTermination reencrypt requires sslEnabled"},
+		{brokerv1beta1.ExposeModeIngress, true, brokerv1beta1.ExposureType{TLSTermination: brokerv1beta1.TLSTerminationEdge}, "Exposure.TLSTermination edge requires the port without sslEnabled"},
+		{brokerv1beta1.ExposeModeIngress, true, brokerv1beta1.ExposureType{TLSSecret: "brokers-tls"}, "Exposure.TLSSecret requires the edge or reencrypt tls termination"},
+		{brokerv1beta1.ExposeModeIngress, true, brokerv1beta1.ExposureType{Path: "/console"}, "Exposure.Path requires an ingress or a route that does not pass the tls connections through"},
+		{brokerv1beta1.ExposeModeIngress, false, brokerv1beta1.ExposureType{Host: "console.example.com"}, "Exposure.Host requires $(ORDINAL) as more than one service is exposed"},
+	} {
+		exposure := invalid.exposure
+		cr = &brokerv1beta1.ActiveMQArtemis{
+			Spec: brokerv1beta1.ActiveMQArtemisSpec{
+				DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+					Size: common.Int32ToPtr(2),
+				},
+				Console: brokerv1beta1.ConsoleType{Expose: true, SSLEnabled: invalid.sslEnabled, ExposeMode: invalid.exposeMode, Exposure: &exposure},
+			},
+		}
+		condition = validateExposeModes(cr)
+		if assert.NotNil(t, condition, invalid.message) {
+			assert.Equal(t, ".Spec.Console."+invalid.message, condition.Message)
+		}
+	}
+
+	// a single service may have a fixed host
+	cr = &brokerv1beta1.ActiveMQArtemis{
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size: common.Int32ToPtr(2),
+			},
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "amqp", Port: 5672, Expose: true, ServiceMode: brokerv1beta1.ServiceModeLoadBalanced, Exposure: &brokerv1beta1.ExposureType{Host: "amqp.example.com"}},
+			},
+		},
+	}
+	assert.Nil(t, validateExposeModes(cr))
+}
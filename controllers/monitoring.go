@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/monitoring"
+	svc "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/services"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia_client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the label that tells the metrics service apart from the other services of the brokers
+const metricsServiceLabel = "app.kubernetes.io/component"
+
+func isMonitoringEnabled(customResource *brokerv1beta1.ActiveMQArtemis) bool {
+	return customResource.Spec.DeploymentPlan.Monitoring != nil && customResource.Spec.DeploymentPlan.Monitoring.Enabled
+}
+
+func isMonitoringAlertsEnabled(customResource *brokerv1beta1.ActiveMQArtemis) bool {
+	return isMonitoringEnabled(customResource) && customResource.Spec.DeploymentPlan.Monitoring.Alerts != nil && customResource.Spec.DeploymentPlan.Monitoring.Alerts.Enabled
+}
+
+// validateMonitoring rejects monitoring with the metrics plugin disabled, the service monitor
+// scrapes the metrics of the plugin
+func validateMonitoring(customResource *brokerv1beta1.ActiveMQArtemis) *metav1.Condition {
+	if isMonitoringEnabled(customResource) && customResource.Spec.DeploymentPlan.EnableMetricsPlugin != nil && !*customResource.Spec.DeploymentPlan.EnableMetricsPlugin {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionInvalidMonitoringReason,
+			Message: ".Spec.DeploymentPlan.Monitoring requires the metrics plugin, .Spec.DeploymentPlan.EnableMetricsPlugin must not be false",
+		}
+	}
+	return nil
+}
+
+// ProcessMonitoring creates the metrics service of the brokers and, when the prometheus operator
+// is installed, the service monitor and the prometheus rule of the monitoring section
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessMonitoring(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, client rtclient.Client, scheme *runtime.Scheme) {
+	reqLogger := ctrl.Log.WithValues("ActiveMQArtemis Name", customResource.Name)
+
+	namespacedName := types.NamespacedName{
+		Name:      customResource.Name,
+		Namespace: customResource.Namespace,
+	}
+
+	serviceLabels := metricsServiceLabels(namer)
+	if isMonitoringEnabled(customResource) {
+		// the service is removed with the monitoring section as it is no longer desired
+		metricsService := svc.NewServiceDefinitionForCR("", client, namespacedName, monitoring.MetricsPortName, monitoring.MetricsPort, namer.LabelBuilder.Labels(), serviceLabels)
+		metricsService.Spec.PublishNotReadyAddresses = false
+		reconciler.trackDesired(metricsService)
+	}
+
+	if !environments.DetectPrometheusOperator() {
+		if isMonitoringEnabled(customResource) {
+			reqLogger.V(1).Info("prometheus operator not installed, no service monitor is created")
+		}
+		return
+	}
+
+	serviceMonitorName := customResource.Name + "-" + monitoring.MetricsPortName
+	if isMonitoringEnabled(customResource) {
+		desired := monitoring.NewServiceMonitorForCR(namespacedName, serviceMonitorName, monitoringLabels(customResource, namer), serviceLabels, customResource.Spec.DeploymentPlan.Monitoring, metricsTLSConfig(customResource, namer, client))
+		if err := syncUnstructured(customResource, desired, client, scheme); err != nil {
+			reqLogger.Error(err, "unable to sync the service monitor", "name", serviceMonitorName)
+		}
+	} else if err := deleteUnstructured(monitoring.NewServiceMonitor(), customResource.Namespace, serviceMonitorName, client); err != nil {
+		reqLogger.Error(err, "unable to delete the service monitor", "name", serviceMonitorName)
+	}
+
+	prometheusRuleName := customResource.Name + "-alerts"
+	if isMonitoringAlertsEnabled(customResource) {
+		metricsServiceName := customResource.Name + "-" + monitoring.MetricsPortName + "-svc"
+		pairedLiveBrokers := int32(0)
+		if isHAPaired(customResource) {
+			pairedLiveBrokers = getDeploymentSize(customResource)
+		}
+		desired := monitoring.NewPrometheusRuleForCR(namespacedName, prometheusRuleName, monitoringLabels(customResource, namer), metricsServiceName, namer.SsNameBuilder.Name(), pairedLiveBrokers, customResource.Spec.DeploymentPlan.Monitoring.Alerts)
+		if err := syncUnstructured(customResource, desired, client, scheme); err != nil {
+			reqLogger.Error(err, "unable to sync the prometheus rule", "name", prometheusRuleName)
+		}
+	} else if err := deleteUnstructured(monitoring.NewPrometheusRule(), customResource.Namespace, prometheusRuleName, client); err != nil {
+		reqLogger.Error(err, "unable to delete the prometheus rule", "name", prometheusRuleName)
+	}
+}
+
+func metricsServiceLabels(namer Namers) map[string]string {
+	labels := map[string]string{metricsServiceLabel: monitoring.MetricsPortName}
+	for key, value := range namer.LabelBuilder.Labels() {
+		labels[key] = value
+	}
+	return labels
+}
+
+// monitoringLabels are the labels of the brokers and the labels the prometheus instance selects
+func monitoringLabels(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers) map[string]string {
+	labels := map[string]string{}
+	for key, value := range namer.LabelBuilder.Labels() {
+		labels[key] = value
+	}
+	for key, value := range customResource.Spec.DeploymentPlan.Monitoring.Labels {
+		labels[key] = value
+	}
+	return labels
+}
+
+// metricsTLSConfig verifies the console certificate with the ca the operator trusts for the
+// management api, without it the certificate is not verified
+func metricsTLSConfig(customResource *brokerv1beta1.ActiveMQArtemis, namer Namers, client rtclient.Client) map[string]interface{} {
+	console := customResource.Spec.Console
+	if !console.SSLEnabled {
+		return nil
+	}
+
+	secretName := console.TrustSecret
+	if secretName == "" {
+		secretName = console.SSLSecret
+	}
+	if secretName == "" {
+		secretName = namer.SecretsConsoleNameBuilder.Name()
+	}
+
+	secret := &corev1.Secret{}
+	err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: customResource.Namespace}, secret)
+	if err != nil || len(secret.Data[jolokia_client.TrustSecretCAKey]) == 0 {
+		return map[string]interface{}{"insecureSkipVerify": true}
+	}
+
+	tlsConfig := map[string]interface{}{
+		"ca": secretKeyRef(secretName, jolokia_client.TrustSecretCAKey),
+		// the certificate of the brokers matches any pod on the headless service
+		"serverName": namer.SsNameBuilder.Name() + "-0." + namer.SvcHeadlessNameBuilder.Name() + "." + customResource.Namespace + ".svc." + common.GetClusterDomain(),
+	}
+	if console.UseClientAuth {
+		tlsConfig["cert"] = secretKeyRef(secretName, corev1.TLSCertKey)
+		tlsConfig["keySecret"] = map[string]interface{}{"name": secretName, "key": corev1.TLSPrivateKeyKey}
+	}
+	return tlsConfig
+}
+
+func secretKeyRef(name string, key string) map[string]interface{} {
+	return map[string]interface{}{
+		"secret": map[string]interface{}{"name": name, "key": key},
+	}
+}
+
+// syncUnstructured creates or updates the labels and the spec of a resource the operator handles
+// unstructured. The cert-manager, prometheus operator and gateway apis are not dependencies of the
+// operator, the packages of their resources only build unstructured objects of their kinds
+func syncUnstructured(customResource *brokerv1beta1.ActiveMQArtemis, desired *unstructured.Unstructured, client rtclient.Client, scheme *runtime.Scheme) error {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(desired.GroupVersionKind())
+	err := client.Get(context.TODO(), types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, current)
+	if k8serrors.IsNotFound(err) {
+		return resources.Create(customResource, client, scheme, desired)
+	}
+	if err != nil {
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(current.Object["spec"], desired.Object["spec"]) && equality.Semantic.DeepEqual(current.GetLabels(), desired.GetLabels()) {
+		return nil
+	}
+	current.Object["spec"] = desired.Object["spec"]
+	current.SetLabels(desired.GetLabels())
+	return resources.Update(client, current)
+}
+
+func deleteUnstructured(obj *unstructured.Unstructured, namespace string, name string, client rtclient.Client) error {
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	if err := client.Delete(context.TODO(), obj); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
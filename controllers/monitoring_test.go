@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/monitoring"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewPrometheusRuleForCR(t *testing.T) {
+	percentage := int32(90)
+	alerts := &brokerv1beta1.MonitoringAlertsType{Enabled: true, AddressMemoryUsagePercentage: &percentage, For: &metav1.Duration{Duration: 90 * time.Second}}
+	rule := monitoring.NewPrometheusRuleForCR(types.NamespacedName{Name: "ex-aao", Namespace: "test"}, "ex-aao-alerts", nil, "ex-aao-metrics-svc", "ex-aao-ss", 0, alerts)
+
+	groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	if assert.Len(t, groups, 1) {
+		rules := groups[0].(map[string]interface{})["rules"].([]interface{})
+		expressions := map[string]string{}
+		durations := map[string]interface{}{}
+		for _, r := range rules {
+			alert := r.(map[string]interface{})
+			expressions[alert["alert"].(string)] = alert["expr"].(string)
+			durations[alert["alert"].(string)] = alert["for"]
+		}
+		assert.Equal(t, `artemis_address_memory_usage_percentage{namespace="test",service="ex-aao-metrics-svc"} > 90`, expressions["ArtemisAddressMemoryUsageHigh"])
+		assert.Equal(t, `artemis_number_of_pages{namespace="test",service="ex-aao-metrics-svc"} > 0`, expressions["ArtemisAddressPaging"])
+		assert.Equal(t, `increase(artemis_messages_added{namespace="test",service="ex-aao-metrics-svc",queue="DLQ"}[90s]) > 0`, expressions["ArtemisDeadLetterQueueGrowing"])
+		assert.Equal(t, `kube_statefulset_status_replicas_ready{namespace="test",statefulset="ex-aao-ss"} < kube_statefulset_status_replicas{namespace="test",statefulset="ex-aao-ss"}`, expressions["ArtemisBrokerPodsNotReady"])
+		assert.Equal(t, "90s", durations["ArtemisAddressPaging"])
+		assert.Nil(t, durations["ArtemisDeadLetterQueueGrowing"])
+	}
+
+	// the backups of a paired deployment are never ready, only its live brokers are expected
+	rule = monitoring.NewPrometheusRuleForCR(types.NamespacedName{Name: "ex-aao", Namespace: "test"}, "ex-aao-alerts", nil, "ex-aao-metrics-svc", "ex-aao-ss", 2, alerts)
+	groups, _, _ = unstructured.NestedSlice(rule.Object, "spec", "groups")
+	if assert.Len(t, groups, 1) {
+		rules := groups[0].(map[string]interface{})["rules"].([]interface{})
+		expressions := map[string]string{}
+		for _, r := range rules {
+			alert := r.(map[string]interface{})
+			expressions[alert["alert"].(string)] = alert["expr"].(string)
+		}
+		assert.Equal(t, `kube_statefulset_status_replicas_ready{namespace="test",statefulset="ex-aao-ss"} < 2`, expressions["ArtemisBrokerPodsNotReady"])
+	}
+}
+
+func TestProcessMonitoring(t *testing.T) {
+	stateManager := common.GetStateManager()
+	defer stateManager.SetState(common.ServiceMonitorKind, false)
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{APIVersion: brokerv1beta1.GroupVersion.String(), Kind: "ActiveMQArtemis"},
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Monitoring: &brokerv1beta1.MonitoringType{
+					Enabled:  true,
+					Interval: &metav1.Duration{Duration: time.Minute},
+					Labels:   map[string]string{"release": "prometheus"},
+					Alerts: &brokerv1beta1.MonitoringAlertsType{
+						Enabled:         true,
+						DeadLetterQueue: "orders.DLQ",
+					},
+				},
+			},
+		},
+	}
+	namer := MakeNamers(cr)
+	client := fake.NewClientBuilder().Build()
+
+	// without the prometheus operator only the service is created
+	reconciler := ActiveMQArtemisReconcilerImpl{}
+	reconciler.ProcessMonitoring(cr, *namer, client, nil)
+	if assert.Len(t, reconciler.requestedResources, 1) {
+		service := reconciler.requestedResources[0].(*v1.Service)
+		assert.Equal(t, "ex-aao-metrics-svc", service.Name)
+		assert.Equal(t, int32(8161), service.Spec.Ports[0].Port)
+		assert.Equal(t, "metrics", service.Spec.Ports[0].Name)
+		assert.Equal(t, "metrics", service.Labels[metricsServiceLabel])
+		assert.Equal(t, namer.LabelBuilder.Labels(), service.Spec.Selector)
+	}
+	assert.True(t, k8serrors.IsNotFound(client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-metrics", Namespace: "test"}, monitoring.NewServiceMonitor())))
+
+	stateManager.SetState(common.ServiceMonitorKind, true)
+	reconciler = ActiveMQArtemisReconcilerImpl{}
+	reconciler.ProcessMonitoring(cr, *namer, client, nil)
+
+	serviceMonitor := monitoring.NewServiceMonitor()
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-metrics", Namespace: "test"}, serviceMonitor))
+	assert.Equal(t, "prometheus", serviceMonitor.GetLabels()["release"])
+	matchLabels, _, _ := unstructured.NestedStringMap(serviceMonitor.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, "metrics", matchLabels[metricsServiceLabel])
+	endpoints, _, _ := unstructured.NestedSlice(serviceMonitor.Object, "spec", "endpoints")
+	if assert.Len(t, endpoints, 1) {
+		endpoint := endpoints[0].(map[string]interface{})
+		assert.Equal(t, "metrics", endpoint["port"])
+		assert.Equal(t, "1m", endpoint["interval"])
+		assert.Equal(t, "http", endpoint["scheme"])
+	}
+
+	rule := monitoring.NewPrometheusRule()
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-alerts", Namespace: "test"}, rule))
+	assert.Contains(t, rule.Object["spec"].(map[string]interface{})["groups"].([]interface{})[0].(map[string]interface{})["rules"].([]interface{})[2].(map[string]interface{})["expr"], `queue="orders.DLQ"`)
+
+	// the console certificate is verified with the ca of its secret
+	cr.Spec.Console.SSLEnabled = true
+	consoleSecret := &v1.Secret{}
+	consoleSecret.Name = "ex-aao-console-secret"
+	consoleSecret.Namespace = "test"
+	consoleSecret.Data = map[string][]byte{"ca.crt": []byte("ca")}
+	assert.NoError(t, client.Create(context.TODO(), consoleSecret))
+	reconciler.ProcessMonitoring(cr, *namer, client, nil)
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-metrics", Namespace: "test"}, serviceMonitor))
+	endpoints, _, _ = unstructured.NestedSlice(serviceMonitor.Object, "spec", "endpoints")
+	if assert.Len(t, endpoints, 1) {
+		endpoint := endpoints[0].(map[string]interface{})
+		assert.Equal(t, "https", endpoint["scheme"])
+		caSecret, _, _ := unstructured.NestedString(endpoint, "tlsConfig", "ca", "secret", "name")
+		assert.Equal(t, "ex-aao-console-secret", caSecret)
+		serverName, _, _ := unstructured.NestedString(endpoint, "tlsConfig", "serverName")
+		assert.Equal(t, "ex-aao-ss-0.ex-aao-hdls-svc.test.svc.cluster.local", serverName)
+	}
+
+	cr.Spec.DeploymentPlan.Monitoring.Alerts = nil
+	reconciler.ProcessMonitoring(cr, *namer, client, nil)
+	assert.True(t, k8serrors.IsNotFound(client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-alerts", Namespace: "test"}, monitoring.NewPrometheusRule())))
+
+	cr.Spec.DeploymentPlan.Monitoring = nil
+	reconciler = ActiveMQArtemisReconcilerImpl{}
+	reconciler.ProcessMonitoring(cr, *namer, client, nil)
+	assert.Empty(t, reconciler.requestedResources)
+	assert.True(t, k8serrors.IsNotFound(client.Get(context.TODO(), types.NamespacedName{Name: "ex-aao-metrics", Namespace: "test"}, monitoring.NewServiceMonitor())))
+}
+
+func TestValidateMonitoring(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Monitoring: &brokerv1beta1.MonitoringType{Enabled: true},
+			},
+		},
+	}
+	assert.Nil(t, validateMonitoring(cr))
+
+	enabled := true
+	cr.Spec.DeploymentPlan.EnableMetricsPlugin = &enabled
+	assert.Nil(t, validateMonitoring(cr))
+
+	disabled := false
+	cr.Spec.DeploymentPlan.EnableMetricsPlugin = &disabled
+	condition := validateMonitoring(cr)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, brokerv1beta1.ValidConditionInvalidMonitoringReason, condition.Reason)
+	}
+
+	cr.Spec.DeploymentPlan.Monitoring.Enabled = false
+	assert.Nil(t, validateMonitoring(cr))
+}
@@ -20,6 +20,8 @@ $KUBE_CLI create -f $DEPLOY_PATH/service_account.yaml
 $KUBE_CLI create -f $DEPLOY_PATH/cluster_role.yaml
 SERVICE_ACCOUNT_NS="$(kubectl get -f $DEPLOY_PATH/service_account.yaml -o jsonpath='{.metadata.namespace}')"
 sed "s/namespace:.*/namespace: ${SERVICE_ACCOUNT_NS}/" $DEPLOY_PATH/cluster_role_binding.yaml | kubectl apply -f -
+$KUBE_CLI create -f $DEPLOY_PATH/node_role.yaml
+sed "s/namespace:.*/namespace: ${SERVICE_ACCOUNT_NS}/" $DEPLOY_PATH/node_role_binding.yaml | kubectl apply -f -
 $KUBE_CLI create -f $DEPLOY_PATH/election_role.yaml
 $KUBE_CLI create -f $DEPLOY_PATH/election_role_binding.yaml
 $KUBE_CLI create -f $DEPLOY_PATH/operator_config.yaml
@@ -16,6 +16,8 @@ $KUBE_CLI delete -f $DEPLOY_PATH/role.yaml
 $KUBE_CLI delete -f $DEPLOY_PATH/role_binding.yaml
 $KUBE_CLI delete -f $DEPLOY_PATH/cluster_role.yaml
 $KUBE_CLI delete -f $DEPLOY_PATH/cluster_role_binding.yaml
+$KUBE_CLI delete -f $DEPLOY_PATH/node_role.yaml
+$KUBE_CLI delete -f $DEPLOY_PATH/node_role_binding.yaml
 $KUBE_CLI delete -f $DEPLOY_PATH/election_role.yaml
 $KUBE_CLI delete -f $DEPLOY_PATH/election_role_binding.yaml
 $KUBE_CLI delete -f $DEPLOY_PATH/operator_config.yaml
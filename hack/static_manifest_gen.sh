@@ -70,6 +70,19 @@ function writeFile() {
       fi
       ;;
 
+    ClusterRole)
+      # the cluster scoped resources of both the single namespace and the cluster wide operator
+      createFile "$destdir/node_role.yaml"
+      sed -i "s/name: ${resource_name}$/name: ${resource_name/operator/node}/" \
+        "$destdir/node_role.yaml"
+      ;;
+
+    ClusterRoleBinding)
+      createFile "$destdir/node_role_binding.yaml"
+      sed -i -e 's/-operator-role$/-node-role/' -e 's/-operator-rolebinding$/-node-rolebinding/' \
+        "$destdir/node_role_binding.yaml"
+      ;;
+
     ServiceAccount)
       createFile "$destdir/service_account.yaml"
       ;;
@@ -153,6 +153,8 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "d864aab0.amq.io",
+		// nodes are cluster scoped, only read for the zone of the broker pods
+		ClientDisableCacheFor: []client.Object{&corev1.Node{}},
 	}
 
 	isLocal, watchList := common.ResolveWatchNamespaceForManager(oprNamespace, watchNamespace)
@@ -184,6 +186,15 @@ func main() {
 			log.Error(err, "failed in detecting openshift")
 			os.Exit(1)
 		}
+		if err := autodetect.DetectPrometheusOperator(); err != nil {
+			log.Error(err, "failed in detecting the prometheus operator")
+		}
+		if err := autodetect.DetectGatewayAPI(); err != nil {
+			log.Error(err, "failed in detecting the gateway api")
+		}
+		if err := autodetect.DetectCertManager(); err != nil {
+			log.Error(err, "failed in detecting cert-manager")
+		}
 	}
 
 	common.SetManager(mgr)
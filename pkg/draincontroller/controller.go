@@ -44,7 +44,6 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
-	"encoding/json"
 	"sort"
 	"strconv"
 	"strings"
@@ -65,6 +64,7 @@ var dlog = ctrl.Log.WithName("controller_v1beta1activemqartemisscaledown")
 const controllerAgentName = "statefulset-drain-controller"
 const AnnotationStatefulSet = "statefulsets.kubernetes.io/drainer-pod-owner" // TODO: can we replace this with an OwnerReference with the StatefulSet as the owner?
 const AnnotationDrainerPodTemplate = "statefulsets.kubernetes.io/drainer-pod-template"
+const AnnotationDrainTarget = "statefulsets.kubernetes.io/drainer-pod-target"
 
 const LabelDrainPod = "drain-pod"
 const DrainServiceAccountName = "drain-pod-service-account"
@@ -82,9 +82,6 @@ const (
 	MessagePVCDeleted       = "delete Claim %s in StatefulSet %s successful"
 )
 
-// TODO: Remove this hack
-var globalPodTemplateJson string = "{\n \"metadata\": {\n    \"labels\": {\n      \"app\": \"CRNAME-amq-drainer\"\n    }\n  },\n  \"spec\": {\n \"serviceAccount\": \"SERVICE_ACCOUNT\",\n \"serviceAccountName\": \"SERVICE_ACCOUNT_NAME\",\n \"terminationGracePeriodSeconds\": 5,\n    \"containers\": [\n {\n        \"env\": [\n          {\n            \"name\": \"AMQ_EXTRA_ARGS\",\n            \"value\": \"--no-autotune\"\n },\n          {\n            \"name\": \"HEADLESS_SVC_NAME\",\n            \"value\": \"HEADLESSSVCNAMEVALUE\"\n },\n          {\n            \"name\": \"PING_SVC_NAME\",\n            \"value\": \"PINGSVCNAMEVALUE\"\n },\n          {\n            \"name\": \"AMQ_USER\",\n \"value\": \"admin\"\n          },\n          {\n            \"name\": \"AMQ_PASSWORD\",\n            \"value\": \"admin\"\n },\n          {\n            \"name\": \"AMQ_ROLE\",\n \"value\": \"admin\"\n          },\n          {\n            \"name\": \"AMQ_NAME\",\n            \"value\": \"amq-broker\"\n },\n          {\n            \"name\": \"AMQ_TRANSPORTS\",\n \"value\": \"openwire,amqp,stomp,mqtt,hornetq\"\n          },\n {\n            \"name\": \"AMQ_GLOBAL_MAX_SIZE\",\n            \"value\": \"100mb\"\n          },\n          {\n            \"name\": \"AMQ_DATA_DIR\",\n            \"value\": \"/opt/CRNAME/data\"\n          },\n          {\n \"name\": \"AMQ_DATA_DIR_LOGGING\",\n            \"value\": \"true\"\n          },\n          {\n            \"name\": \"AMQ_CLUSTERED\",\n            \"value\": \"true\"\n },\n          {\n            \"name\": \"AMQ_REPLICAS\",\n \"value\": \"1\"\n          },\n          {\n            \"name\": \"AMQ_CLUSTER_USER\",\n            \"value\": \"CLUSTERUSER\"\n },\n          {\n            \"name\": \"AMQ_CLUSTER_PASSWORD\",\n            \"value\": \"CLUSTERPASS\"\n          },\n          {\n            \"name\": \"POD_NAMESPACE\",\n            \"valueFrom\": {\n \"fieldRef\": {\n                \"fieldPath\": \"metadata.namespace\"\n              }\n            }\n },\n          {\n            \"name\": \"OPENSHIFT_DNS_PING_SERVICE_PORT\",\n            \"value\": \"7800\"\n          }\n        ],\n        \"image\": \"SSIMAGE\",\n \"name\": \"drainer-amq\",\n\n        \"command\": [\"/bin/sh\", \"-c\", \"echo \\\"Starting the drainer\\\" ; /opt/amq/bin/drain.sh ; EXIT_CODE=$?  ; echo \\\"Drain completed! Exit code $?\\\"; exit $EXIT_CODE\"],\n        \"volumeMounts\": [\n          {\n            \"name\": \"CRNAME\",\n \"mountPath\": \"/opt/CRNAME/data\"\n          }\n ]\n      }\n    ]\n }\n}"
-
 type Controller struct {
 	name string
 	// kubeclientset is a standard kubernetes clientset
@@ -352,10 +349,15 @@ func (c *Controller) processStatefulSet(sts *appsv1.StatefulSet) error {
 	// TODO: think about scale-down during a rolling upgrade
 	dlog.Info("Processing statefulset", "sts", sts.Name)
 
+	// the brokers are only drained to an external target when scaled down to zero
+	var scaleToZeroTarget *brokerv1beta1.ScaleToZeroTargetType
 	if *sts.Spec.Replicas == 0 {
-		// Ensure data is not touched in the case of complete scaledown
-		dlog.Info("Ignoring StatefulSet " + sts.Name + " because replicas set to 0.")
-		return nil
+		scaleToZeroTarget = c.getScaleToZeroTarget(sts)
+		if scaleToZeroTarget == nil {
+			// Ensure data is not touched in the case of complete scaledown
+			dlog.Info("Ignoring StatefulSet " + sts.Name + " because replicas set to 0.")
+			return nil
+		}
 	}
 
 	dlog.Info("Statefulset " + sts.Name + " Spec.Replicas set to " + strconv.Itoa(int(*sts.Spec.Replicas)))
@@ -372,6 +374,14 @@ func (c *Controller) processStatefulSet(sts *appsv1.StatefulSet) error {
 	//	return nil
 	//}
 
+	// the addresses of a scale to zero target host may change while its drains are in progress
+	var targetSyncErr error
+	if scaleToZeroTarget != nil {
+		if targetSyncErr = c.syncDrainTargetEndpoints(sts, scaleToZeroTarget); targetSyncErr != nil {
+			dlog.Error(targetSyncErr, "Error while syncing the scale to zero target endpoints", "host", scaleToZeroTarget.Host)
+		}
+	}
+
 	claimsGroupedByOrdinal, err := c.getClaims(sts)
 	if err != nil {
 		err = fmt.Errorf("error while getting list of PVCs in namespace %s: %s", sts.Namespace, err)
@@ -385,11 +395,28 @@ func (c *Controller) processStatefulSet(sts *appsv1.StatefulSet) error {
 	}
 	sort.Sort(sort.Reverse(sort.IntSlice(ordinals)))
 
+	concurrency := c.getDrainConcurrency(sts)
+	progress := map[int]brokerv1beta1.DrainOrdinalStatus{}
+	defer c.updateDrainStatus(sts, ordinals, progress, concurrency)
+
+	// the drains in progress per target, ordinals are drained from the highest down
+	// and no more than concurrency drain pods run at once
+	inProgress := 0
+	drainsPerTarget := map[string]int{}
+	finished := false
+	defer func() {
+		// the drain pods in progress still need their service account and target services
+		if finished && inProgress == 0 {
+			c.cleanupBrokerDrainTargetServices(sts)
+			c.cleanupDrainRBACResources(sts.Namespace)
+		}
+	}()
+
 	dlog.Info("Looking through all the pods...")
 	for _, ordinal := range ordinals {
 
 		dlog.Info("looking ordinal", "ordinal", ordinal)
-		if ordinal == 0 {
+		if ordinal == 0 && scaleToZeroTarget == nil {
 			// This assumes order on scale up and down is enforced, i.e. the system waits for n, n-1,... 2, 1 to scaledown before attempting 0
 			dlog.Info("Ignoring ordinal 0 as no other pod to drain to.")
 			continue
@@ -410,12 +437,19 @@ func (c *Controller) processStatefulSet(sts *appsv1.StatefulSet) error {
 		// Is it a drain pod or a regular stateful pod?
 		if isDrainPod(pod) {
 			dlog.Info("This is a drain pod", "pod name", podName)
-			err = c.cleanUpDrainPodIfNeeded(sts, pod, ordinal)
+			progress[ordinal], err = c.cleanUpDrainPodIfNeeded(sts, pod, ordinal)
 			if err != nil {
 				return err
 			}
+			switch progress[ordinal].State {
+			case brokerv1beta1.DrainStateDraining:
+				inProgress++
+				drainsPerTarget[progress[ordinal].Target]++
+			case brokerv1beta1.DrainStateCompleted, brokerv1beta1.DrainStateFailed:
+				finished = true
+			}
 
-			if sts.Spec.PodManagementPolicy == appsv1.OrderedReadyPodManagement {
+			if sts.Spec.PodManagementPolicy == appsv1.OrderedReadyPodManagement && concurrency <= 1 {
 				// don't create additional drain pods; they will be created in one of the
 				// next invocations of this method, when the current drain pod finishes
 				dlog.Info("sts has orderReadyPodManagement policy, break")
@@ -423,61 +457,53 @@ func (c *Controller) processStatefulSet(sts *appsv1.StatefulSet) error {
 			}
 		}
 
-		// TODO: scale down to zero? should what happens on such events be configurable? there may or may not be anywhere to drain to
 		if int32(ordinal) >= *sts.Spec.Replicas {
 			dlog.Info("ordinal is greater then replicas", "ordinal", ordinal, "replicas", *sts.Spec.Replicas)
 			// PVC exists, but its ordinal is higher than the current last stateful pod's ordinal;
 			// this means the PVC is an orphan and should be drained & deleted
 
+			if pod != nil && !isDrainPod(pod) {
+				progress[ordinal] = newPendingDrainStatus(ordinal, "Waiting for broker pod "+podName+" to terminate")
+			}
+
 			// If the Pod doesn't exist, we'll create it
 			if pod == nil { // TODO: what if the PVC doesn't exist here (or what if it's deleted just after we create the pod)
 				dlog.Info("Found orphaned PVC(s) for ordinal " + strconv.Itoa(ordinal) + ". Creating drain pod " + podName)
 
-				// Check to ensure we have a pod to drain to
-				ordinalZeroPodName := getPodName(sts, 0)
-				ordinalZeroPod, err := c.podLister.Pods(sts.Namespace).Get(ordinalZeroPodName)
-				if err != nil {
-					dlog.Error(err, "Error while getting ordinal zero pod "+podName+": "+err.Error())
-					return err
-				}
-
-				// Ensure that at least the ordinal zero pod is running
-				if corev1.PodRunning != ordinalZeroPod.Status.Phase {
-					//log.Info("Ordinal zero pod '%s' status phase '%s', waiting for it to be Running.", sts.Name, pod.Status.Phase)
-					dlog.Info("Ordinal zero pod " + sts.Name + " status phase not PodRunning, waiting for it to be Running.")
+				if inProgress >= int(concurrency) {
+					progress[ordinal] = newPendingDrainStatus(ordinal, fmt.Sprintf("Waiting for one of the %d drains in progress to complete", inProgress))
 					continue
 				}
 
-				// Ensure that at least the ordinal zero pod is Ready
-				podConditions := ordinalZeroPod.Status.Conditions
-
-				ordinalZeroPodReady := false
-				for _, podCondition := range podConditions {
-					//log.V(5).Info("Ordinal zero pod condition %s", podCondition)
-					if corev1.PodReady == podCondition.Type {
-						if corev1.ConditionTrue != podCondition.Status {
-							dlog.Info("Ordinal zero pod " + sts.Name + " podCondition Ready not True, waiting for it to True.")
-						}
-						if corev1.ConditionTrue == podCondition.Status {
-							dlog.Info("Ordinal zero pod " + sts.Name + " podCondition Ready True, proceeding to create drainer pod.")
-							ordinalZeroPodReady = true
-						}
-					}
+				// Check to ensure we have a pod to drain to
+				var ready bool
+				var target *drainTarget
+				if scaleToZeroTarget != nil {
+					ready, err = c.isScaleToZeroTargetReady(sts, scaleToZeroTarget, targetSyncErr, ordinal, progress)
+					target = newScaleToZeroDrainTarget(sts, scaleToZeroTarget)
+				} else if concurrency > 1 {
+					// spread the drains over the remaining brokers
+					target, err = c.getRemainingBrokerDrainTarget(sts, ordinal, drainsPerTarget, progress)
+					ready = target != nil
+				} else {
+					ready, err = c.isOrdinalZeroPodReady(sts, ordinal, progress)
 				}
-
-				if !ordinalZeroPodReady {
+				if err != nil {
+					return err
+				}
+				if !ready {
 					continue
 				}
 
 				dlog.Info("Creating new drain pod...", "sts", sts)
-				pod, err := c.newPod(sts, ordinal)
+				pod, err := c.newPod(sts, ordinal, target)
 				if err != nil {
 					dlog.Error(err, "error creating drain pod")
 					return fmt.Errorf("can't create drain Pod object: %s", err)
 				}
 				dlog.Info("Now creating the drain pod in namespace "+sts.Namespace, "pod", pod)
 				// needs a proper account for the pod to be created/start.
-				_, err = c.kubeclientset.CoreV1().Pods(sts.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				createdPod, err := c.kubeclientset.CoreV1().Pods(sts.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
 
 				// If an error occurs during Create, we'll requeue the item so we can
 				// attempt processing again later. This could have been caused by a
@@ -486,6 +512,9 @@ func (c *Controller) processStatefulSet(sts *appsv1.StatefulSet) error {
 					dlog.Error(err, "Error while creating drain Pod "+podName+": ")
 					return err
 				}
+				progress[ordinal] = getDrainPodStatus(createdPod, ordinal)
+				inProgress++
+				drainsPerTarget[progress[ordinal].Target]++
 
 				if !c.localOnly {
 					c.recorder.Event(sts, corev1.EventTypeNormal, SuccessCreate, fmt.Sprintf(MessageDrainPodCreated, podName, sts.Name))
@@ -502,6 +531,44 @@ func (c *Controller) processStatefulSet(sts *appsv1.StatefulSet) error {
 	return nil
 }
 
+// isOrdinalZeroPodReady checks that the ordinal zero pod, which the other ordinals are drained to, is ready
+func (c *Controller) isOrdinalZeroPodReady(sts *appsv1.StatefulSet, ordinal int, progress map[int]brokerv1beta1.DrainOrdinalStatus) (bool, error) {
+	podName := getPodName(sts, ordinal)
+	ordinalZeroPodName := getPodName(sts, 0)
+	progress[ordinal] = newPendingDrainStatus(ordinal, "Waiting for pod "+ordinalZeroPodName+" to be ready to receive the messages")
+	ordinalZeroPod, err := c.podLister.Pods(sts.Namespace).Get(ordinalZeroPodName)
+	if err != nil {
+		dlog.Error(err, "Error while getting ordinal zero pod "+podName+": "+err.Error())
+		return false, err
+	}
+
+	// Ensure that at least the ordinal zero pod is running
+	if corev1.PodRunning != ordinalZeroPod.Status.Phase {
+		//log.Info("Ordinal zero pod '%s' status phase '%s', waiting for it to be Running.", sts.Name, pod.Status.Phase)
+		dlog.Info("Ordinal zero pod " + sts.Name + " status phase not PodRunning, waiting for it to be Running.")
+		return false, nil
+	}
+
+	// Ensure that at least the ordinal zero pod is Ready
+	podConditions := ordinalZeroPod.Status.Conditions
+
+	ordinalZeroPodReady := false
+	for _, podCondition := range podConditions {
+		//log.V(5).Info("Ordinal zero pod condition %s", podCondition)
+		if corev1.PodReady == podCondition.Type {
+			if corev1.ConditionTrue != podCondition.Status {
+				dlog.Info("Ordinal zero pod " + sts.Name + " podCondition Ready not True, waiting for it to True.")
+			}
+			if corev1.ConditionTrue == podCondition.Status {
+				dlog.Info("Ordinal zero pod " + sts.Name + " podCondition Ready True, proceeding to create drainer pod.")
+				ordinalZeroPodReady = true
+			}
+		}
+	}
+
+	return ordinalZeroPodReady, nil
+}
+
 func (c *Controller) getClaims(sts *appsv1.StatefulSet) (claimsGroupedByOrdinal map[int][]*corev1.PersistentVolumeClaim, err error) {
 	// shouldn't use statefulset.Spec.Selector.MatchLabels, as they don't always match; sts controller looks up pvcs by name!
 	allClaims, err := c.pvcLister.PersistentVolumeClaims(sts.Namespace).List(labels.Everything())
@@ -575,28 +642,26 @@ func (c *Controller) cleanupDrainRBACResources(namespace string) {
 	}
 }
 
-func (c *Controller) cleanUpDrainPodIfNeeded(sts *appsv1.StatefulSet, pod *corev1.Pod, ordinal int) error {
+func (c *Controller) cleanUpDrainPodIfNeeded(sts *appsv1.StatefulSet, pod *corev1.Pod, ordinal int) (brokerv1beta1.DrainOrdinalStatus, error) {
 	// Drain Pod already exists. Check if it's done draining.
 	podName := getPodName(sts, ordinal)
+	status := getDrainPodStatus(pod, ordinal)
 
-	podPhase := pod.Status.Phase
-	if podPhase == corev1.PodSucceeded || podPhase == corev1.PodFailed {
-		defer c.cleanupDrainRBACResources(sts.Namespace)
-	}
-
-	switch podPhase {
-	case (corev1.PodSucceeded):
+	switch {
+	case isDrainCompleted(pod):
 		dlog.Info("Drain pod " + podName + " finished.")
 		if !c.localOnly {
 			c.recorder.Event(sts, corev1.EventTypeNormal, DrainSuccess, fmt.Sprintf(MessageDrainPodFinished, podName, sts.Name))
 		}
+		// the log is gone once the drain pod is deleted
+		status.MessagesMigrated = c.getMessagesMigrated(pod)
 
 		for _, pvcTemplate := range sts.Spec.VolumeClaimTemplates {
 			pvcName := getPVCName(sts, pvcTemplate.Name, int32(ordinal))
 			dlog.Info("Deleting PVC " + pvcName)
 			err := c.kubeclientset.CoreV1().PersistentVolumeClaims(sts.Namespace).Delete(context.TODO(), pvcName, metav1.DeleteOptions{})
 			if err != nil {
-				return err
+				return status, err
 			}
 			if !c.localOnly {
 				c.recorder.Event(sts, corev1.EventTypeNormal, PVCDeleteSuccess, fmt.Sprintf(MessagePVCDeleted, pvcName, sts.Name))
@@ -609,13 +674,13 @@ func (c *Controller) cleanUpDrainPodIfNeeded(sts *appsv1.StatefulSet, pod *corev
 		dlog.Info("Deleting drain pod " + podName)
 		err := c.kubeclientset.CoreV1().Pods(sts.Namespace).Delete(context.TODO(), podName, metav1.DeleteOptions{})
 		if err != nil {
-			return err
+			return status, err
 		}
 		if !c.localOnly {
 			c.recorder.Event(sts, corev1.EventTypeNormal, PodDeleteSuccess, fmt.Sprintf(MessageDrainPodDeleted, podName, sts.Name))
 		}
 
-	case (corev1.PodFailed):
+	case pod.Status.Phase == corev1.PodFailed:
 		dlog.Info("Drain pod " + podName + " failed.")
 
 	default:
@@ -624,7 +689,7 @@ func (c *Controller) cleanUpDrainPodIfNeeded(sts *appsv1.StatefulSet, pod *corev
 
 	}
 
-	return nil
+	return status, nil
 }
 
 func isDrainPod(pod *corev1.Pod) bool {
@@ -735,7 +800,9 @@ func (c *Controller) GetStopCh() *chan struct{} {
 	return &c.stopCh
 }
 
-func (c *Controller) getClusterCredentials(namespace string, ssNames map[string]string) (string, string) {
+// getClusterCredentials reads the cluster credentials the broker pods use from the credentials
+// secret of their CR, the drain pod has to join the same cluster
+func (c *Controller) getClusterCredentials(namespace string, ssNames map[string]string) (string, string, error) {
 
 	secretName := ssNames["AMQ_CREDENTIALS_SECRET_NAME"]
 
@@ -751,15 +818,18 @@ func (c *Controller) getClusterCredentials(namespace string, ssNames map[string]
 
 	dlog.Info("Try retrieving cluster credentials from secret", "secret", namespacedName)
 	if err := resources.Retrieve(namespacedName, c.client, secretDefinition); err != nil {
-		dlog.Info("Failed to retrieve cluster credentials from secret, using defaults", "err", err)
-		return ssNames["CLUSTERUSER"], ssNames["CLUSTERPASS"]
-	} else {
-		dlog.Info("retrieved cluster credential from existing secret")
-		return string(secretDefinition.Data["AMQ_CLUSTER_USER"]), string(secretDefinition.Data["AMQ_CLUSTER_PASSWORD"])
+		return "", "", fmt.Errorf("failed to retrieve cluster credentials from secret %v: %w", namespacedName, err)
+	}
+
+	clusterUser, clusterPassword := string(secretDefinition.Data["AMQ_CLUSTER_USER"]), string(secretDefinition.Data["AMQ_CLUSTER_PASSWORD"])
+	if clusterUser == "" || clusterPassword == "" {
+		return "", "", fmt.Errorf("no cluster credentials in secret %v", namespacedName)
 	}
+	dlog.Info("retrieved cluster credential from existing secret")
+	return clusterUser, clusterPassword, nil
 }
 
-func (c *Controller) newPod(sts *appsv1.StatefulSet, ordinal int) (*corev1.Pod, error) {
+func (c *Controller) newPod(sts *appsv1.StatefulSet, ordinal int, target *drainTarget) (*corev1.Pod, error) {
 
 	ssNamesKey := types.NamespacedName{
 		Namespace: sts.Namespace,
@@ -773,66 +843,50 @@ func (c *Controller) newPod(sts *appsv1.StatefulSet, ordinal int) (*corev1.Pod,
 	}
 
 	ssNames := c.ssNamesMap[ssNamesKey]
+	if target != nil {
+		ssNames = withDrainTarget(ssNames, target)
+	}
 
-	//podTemplateJson := sts.Annotations[AnnotationDrainerPodTemplate]
-	//TODO: Remove this blatant hack
-	podTemplateJson := globalPodTemplateJson
-	clusterUser, clusterPassword := c.getClusterCredentials(sts.Namespace, ssNames)
-	podTemplateJson = strings.Replace(podTemplateJson, "CRNAME", ssNames["CRNAME"], -1)
-	podTemplateJson = strings.Replace(podTemplateJson, "CLUSTERUSER", clusterUser, 1)
-	podTemplateJson = strings.Replace(podTemplateJson, "CLUSTERPASS", clusterPassword, 1)
-	podTemplateJson = strings.Replace(podTemplateJson, "HEADLESSSVCNAMEVALUE", ssNames["HEADLESSSVCNAMEVALUE"], 1)
-	podTemplateJson = strings.Replace(podTemplateJson, "PINGSVCNAMEVALUE", ssNames["PINGSVCNAMEVALUE"], 1)
+	if len(sts.Spec.Template.Spec.Containers) == 0 || sts.Spec.Template.Spec.Containers[0].Image == "" {
+		return nil, fmt.Errorf("No drain pod image configured for StatefulSet " + sts.Name)
+	}
 
+	var serviceAccount string
 	if c.localOnly {
-		podTemplateJson = strings.Replace(podTemplateJson, "SERVICE_ACCOUNT", os.Getenv("SERVICE_ACCOUNT"), 1)
-		podTemplateJson = strings.Replace(podTemplateJson, "SERVICE_ACCOUNT_NAME", os.Getenv("SERVICE_ACCOUNT"), 1)
+		serviceAccount = os.Getenv("SERVICE_ACCOUNT")
 	} else {
 		// the drain pod is in a different namespace, we need set up a service account with proper permission
 		// and should delete it after drain is done.
 		c.createDrainRBACResources(sts.Namespace)
 
 		dlog.Info("Setting drain pod service account", "service account name", DrainServiceAccountName)
-		podTemplateJson = strings.Replace(podTemplateJson, "SERVICE_ACCOUNT", DrainServiceAccountName, 1)
-		podTemplateJson = strings.Replace(podTemplateJson, "SERVICE_ACCOUNT_NAME", DrainServiceAccountName, 1)
-	}
-	image := sts.Spec.Template.Spec.Containers[0].Image
-	//sts.Spec.Template.Spec.Containers[0].Resources = c.resources
-	if image == "" {
-		return nil, fmt.Errorf("No drain pod image configured for StatefulSet " + sts.Name)
-	}
-	podTemplateJson = strings.Replace(podTemplateJson, "SSIMAGE", image, 1)
-	if podTemplateJson == "" {
-		return nil, fmt.Errorf("No drain pod template configured for StatefulSet " + sts.Name)
+		serviceAccount = DrainServiceAccountName
 	}
-	pod := corev1.Pod{}
-	err := json.Unmarshal([]byte(podTemplateJson), &pod)
+
+	clusterUser, clusterPassword, err := c.getClusterCredentials(sts.Namespace, ssNames)
 	if err != nil {
-		return nil, fmt.Errorf("Can't unmarshal DrainerPodTemplate JSON from annotation: " + err.Error())
+		return nil, err
+	}
+	pod := newDrainPod(sts, ssNames, clusterUser, clusterPassword, serviceAccount)
+
+	ownerCr := c.ssToCrMap[ssNamesKey]
+	pod.Spec.Containers[0].Resources = c.resources
+	if len(ownerCr.Spec.Resources.Limits) > 0 || len(ownerCr.Spec.Resources.Requests) > 0 {
+		pod.Spec.Containers[0].Resources = ownerCr.Spec.Resources
 	}
+	applyDrainPodTemplate(pod, ownerCr.Spec.DrainPodTemplate)
 
 	pod.Name = getPodName(sts, ordinal)
 	pod.Namespace = sts.Namespace
-
-	if pod.Labels == nil {
-		pod.Labels = map[string]string{}
-	}
 	pod.Labels[LabelDrainPod] = pod.Name
-	if pod.Annotations == nil {
-		pod.Annotations = map[string]string{}
-	}
 	pod.Annotations[AnnotationStatefulSet] = sts.Name
-
-	// TODO: cannot set blockOwnerDeletion if an ownerReference refers to a resource you can't set finalizers on: User "system:serviceaccount:kube-system:statefulset-drain-controller" cannot update statefulsets/finalizers.apps
-	if pod.OwnerReferences == nil {
-		pod.OwnerReferences = []metav1.OwnerReference{}
+	pod.Annotations[AnnotationDrainTarget] = ssNames["HEADLESSSVCNAMEVALUE"]
+	if target != nil {
+		pod.Annotations[AnnotationDrainTarget] = target.name
 	}
-	ownerCr := c.ssToCrMap[ssNamesKey]
-	pod.OwnerReferences = append(pod.OwnerReferences, *metav1.NewControllerRef(ownerCr, ownerCr.GroupVersionKind()))
 
-	pod.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
-	pod.Spec.Containers[0].Resources = c.resources
-	pod.Spec.Tolerations = sts.Spec.Template.Spec.Tolerations
+	// TODO: cannot set blockOwnerDeletion if an ownerReference refers to a resource you can't set finalizers on: User "system:serviceaccount:kube-system:statefulset-drain-controller" cannot update statefulsets/finalizers.apps
+	pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(ownerCr, ownerCr.GroupVersionKind())}
 
 	for _, pvcTemplate := range sts.Spec.VolumeClaimTemplates {
 		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{ // TODO: override existing volumes with the same name
@@ -845,7 +899,7 @@ func (c *Controller) newPod(sts *appsv1.StatefulSet, ordinal int) (*corev1.Pod,
 		})
 	}
 
-	return &pod, nil
+	return pod, nil
 }
 
 func getPodName(sts *appsv1.StatefulSet, ordinal int) string {
@@ -16,12 +16,23 @@ limitations under the License.
 package draincontroller
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
+	"strconv"
 	"testing"
 
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDrainController(t *testing.T) {
@@ -30,11 +41,9 @@ func TestDrainController(t *testing.T) {
 }
 
 var _ = Describe("Drain Controller Test", func() {
-	Context("Global template test", func() {
-		It("testing global template has correct openshift ping dns service port", func() {
-			pod := corev1.Pod{}
-			err := json.Unmarshal([]byte(globalPodTemplateJson), &pod)
-			Expect(err).Should(Succeed())
+	Context("Drain pod template test", func() {
+		It("testing drain pod has correct openshift ping dns service port", func() {
+			pod := newDrainPod(newBrokerStatefulSet(), map[string]string{"CRNAME": "ex-aao"}, "user", "pass", "sa")
 
 			var servicePort string
 			envVars := pod.Spec.Containers[0].Env
@@ -45,5 +54,378 @@ var _ = Describe("Drain Controller Test", func() {
 			}
 			Expect(servicePort).To(Equal("7800"))
 		})
+
+		It("testing drain pod inherits the broker pod settings", func() {
+			sts := newBrokerStatefulSet()
+			pod := newDrainPod(sts, map[string]string{"CRNAME": "ex-aao"}, "user", "pass", "sa")
+
+			Expect(pod.Labels["app"]).To(Equal("ex-aao-amq-drainer"))
+			Expect(pod.Spec.ServiceAccountName).To(Equal("sa"))
+			Expect(pod.Spec.NodeSelector).To(Equal(sts.Spec.Template.Spec.NodeSelector))
+			Expect(pod.Spec.Tolerations).To(Equal(sts.Spec.Template.Spec.Tolerations))
+			Expect(pod.Spec.SecurityContext).To(Equal(sts.Spec.Template.Spec.SecurityContext))
+			Expect(pod.Spec.ImagePullSecrets).To(Equal(sts.Spec.Template.Spec.ImagePullSecrets))
+			Expect(pod.Spec.Containers).To(HaveLen(1))
+			Expect(pod.Spec.Containers[0].Image).To(Equal("quay.io/artemiscloud/activemq-artemis-broker-kubernetes:latest"))
+			Expect(pod.Spec.Containers[0].VolumeMounts[0].MountPath).To(Equal("/opt/ex-aao/data"))
+		})
+
+		It("testing drain pod does not inherit the broker pod anti-affinity", func() {
+			sts := newBrokerStatefulSet()
+			nodeAffinity := &corev1.NodeAffinity{PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{Weight: 1}}}
+			sts.Spec.Template.Spec.Affinity = &corev1.Affinity{
+				NodeAffinity:    nodeAffinity,
+				PodAntiAffinity: &corev1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{TopologyKey: corev1.LabelHostname}}},
+			}
+			pod := newDrainPod(sts, map[string]string{"CRNAME": "ex-aao"}, "user", "pass", "sa")
+			Expect(pod.Spec.Affinity).To(Equal(&corev1.Affinity{NodeAffinity: nodeAffinity}))
+
+			sts.Spec.Template.Spec.Affinity.NodeAffinity = nil
+			pod = newDrainPod(sts, map[string]string{"CRNAME": "ex-aao"}, "user", "pass", "sa")
+			Expect(pod.Spec.Affinity).To(BeNil())
+		})
+
+		It("testing drain pod template overrides the broker pod settings", func() {
+			pod := newDrainPod(newBrokerStatefulSet(), map[string]string{"CRNAME": "ex-aao"}, "user", "pass", "sa")
+			runAsNonRoot := true
+			template := &brokerv1beta1.DrainPodTemplateType{
+				Labels:             map[string]string{"team": "messaging"},
+				NodeSelector:       map[string]string{"disk": "ssd"},
+				PodSecurityContext: &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot},
+				ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "drain-pull-secret"}},
+				Env:                []corev1.EnvVar{{Name: "AMQ_GLOBAL_MAX_SIZE", Value: "1gb"}},
+				Sidecars:           []corev1.Container{{Name: "proxy", Image: "proxy:latest"}},
+			}
+			applyDrainPodTemplate(pod, template)
+
+			Expect(pod.Labels["team"]).To(Equal("messaging"))
+			Expect(pod.Labels["app"]).To(Equal("ex-aao-amq-drainer"))
+			Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{"disk": "ssd"}))
+			Expect(pod.Spec.Tolerations).To(HaveLen(1))
+			Expect(*pod.Spec.SecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(pod.Spec.ImagePullSecrets[0].Name).To(Equal("drain-pull-secret"))
+			env := pod.Spec.Containers[0].Env
+			Expect(env[len(env)-1]).To(Equal(corev1.EnvVar{Name: "AMQ_GLOBAL_MAX_SIZE", Value: "1gb"}))
+			Expect(pod.Spec.Containers).To(HaveLen(2))
+			Expect(pod.Spec.Containers[1].Name).To(Equal("proxy"))
+		})
+	})
+
+	Context("Drain status test", func() {
+		It("testing drain pod phases map to drain states", func() {
+			pod := &corev1.Pod{}
+			pod.Name = "ex-aao-ss-2"
+			pod.Status.Phase = corev1.PodRunning
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: drainContainerName, RestartCount: 2}}
+
+			status := getDrainPodStatus(pod, 2)
+			Expect(status.State).To(Equal(brokerv1beta1.DrainStateDraining))
+			Expect(status.DrainPodName).To(Equal("ex-aao-ss-2"))
+			Expect(status.StartTime).NotTo(BeNil())
+			Expect(status.Message).To(Equal("drain container restarted 2 times"))
+
+			pod.Status.Phase = corev1.PodFailed
+			pod.Status.ContainerStatuses[0].State.Terminated = &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}
+
+			status = getDrainPodStatus(pod, 2)
+			Expect(status.State).To(Equal(brokerv1beta1.DrainStateFailed))
+			Expect(status.FinishTime).NotTo(BeNil())
+			Expect(status.Message).To(Equal("drain container terminated with exit code 1: Error"))
+		})
+
+		It("testing drain pod with a running sidecar completes with the drain container", func() {
+			pod := &corev1.Pod{}
+			pod.Name = "ex-aao-ss-2"
+			pod.Status.Phase = corev1.PodRunning
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{Name: "proxy", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				{Name: drainContainerName, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			}
+			Expect(isDrainCompleted(pod)).To(BeFalse())
+			Expect(getDrainPodStatus(pod, 2).State).To(Equal(brokerv1beta1.DrainStateDraining))
+
+			pod.Status.ContainerStatuses[1].State = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}
+			Expect(isDrainCompleted(pod)).To(BeTrue())
+			Expect(getDrainPodStatus(pod, 2).State).To(Equal(brokerv1beta1.DrainStateCompleted))
+		})
+
+		It("testing messages migrated are parsed from the drain pod log", func() {
+			Expect(parseMessagesMigrated("Starting the drainer\nINFO  [org.apache.activemq.artemis.core.server] AMQ221000: started")).To(BeNil())
+			Expect(*parseMessagesMigrated("INFO  [org.apache.activemq.artemis.core.server] Scaled down 42 messages total")).To(Equal(int64(42)))
+		})
+
+		It("testing drain status is merged with the previous status", func() {
+			messages := int64(10)
+			previous := []brokerv1beta1.DrainOrdinalStatus{
+				{Ordinal: 4, State: brokerv1beta1.DrainStateCompleted, DrainPodName: "ex-aao-ss-4", MessagesMigrated: &messages},
+				{Ordinal: 3, State: brokerv1beta1.DrainStateDraining, DrainPodName: "ex-aao-ss-3"},
+				{Ordinal: 1, State: brokerv1beta1.DrainStateCompleted, DrainPodName: "ex-aao-ss-1"},
+			}
+			progress := map[int]brokerv1beta1.DrainOrdinalStatus{
+				3: {Ordinal: 3, State: brokerv1beta1.DrainStateDraining, DrainPodName: "ex-aao-ss-3"},
+			}
+
+			merged := mergeDrainStatus(previous, 2, []int{3, 2, 1, 0}, progress)
+			Expect(merged).To(HaveLen(3))
+			Expect(merged[0].Ordinal).To(Equal(int32(2)))
+			Expect(merged[0].State).To(Equal(brokerv1beta1.DrainStatePending))
+			Expect(merged[1].Ordinal).To(Equal(int32(3)))
+			Expect(merged[1].State).To(Equal(brokerv1beta1.DrainStateDraining))
+			Expect(merged[2].Ordinal).To(Equal(int32(4)))
+			Expect(*merged[2].MessagesMigrated).To(Equal(int64(10)))
+		})
+	})
+
+	Context("Scale to zero target test", func() {
+		It("testing drain pod data points at the scale to zero target", func() {
+			sts := newBrokerStatefulSet()
+			ssNames := map[string]string{"CRNAME": "ex-aao", "HEADLESSSVCNAMEVALUE": "ex-aao-hdls-svc", "AMQ_CREDENTIALS_SECRET_NAME": "ex-aao-credentials-secret"}
+
+			target := newScaleToZeroDrainTarget(sts, &brokerv1beta1.ScaleToZeroTargetType{HeadlessServiceName: "other-hdls-svc", CredentialsSecretName: "other-credentials-secret"})
+			targetNames := withDrainTarget(ssNames, target)
+			Expect(target.name).To(Equal("other-hdls-svc"))
+			Expect(targetNames["HEADLESSSVCNAMEVALUE"]).To(Equal("other-hdls-svc"))
+			Expect(targetNames["AMQ_CREDENTIALS_SECRET_NAME"]).To(Equal("other-credentials-secret"))
+			Expect(ssNames["HEADLESSSVCNAMEVALUE"]).To(Equal("ex-aao-hdls-svc"))
+
+			target = newScaleToZeroDrainTarget(sts, &brokerv1beta1.ScaleToZeroTargetType{Host: "broker.example.com"})
+			targetNames = withDrainTarget(ssNames, target)
+			Expect(target.name).To(Equal("broker.example.com"))
+			Expect(targetNames["HEADLESSSVCNAMEVALUE"]).To(Equal("ex-aao-ss-drain-target"))
+			Expect(targetNames["AMQ_CREDENTIALS_SECRET_NAME"]).To(Equal("ex-aao-credentials-secret"))
+		})
+
+		It("testing drain pod uses the cluster credentials of its CR", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ex-aao-credentials-secret", Namespace: "test"},
+				Data: map[string][]byte{
+					"AMQ_CLUSTER_USER":     []byte("ex-aao-user"),
+					"AMQ_CLUSTER_PASSWORD": []byte("ex-aao-pass"),
+				},
+			}
+			c := &Controller{client: fakeclient.NewClientBuilder().WithObjects(secret).Build()}
+
+			user, password, err := c.getClusterCredentials("test", map[string]string{"AMQ_CREDENTIALS_SECRET_NAME": "ex-aao-credentials-secret"})
+			Expect(err).Should(Succeed())
+			Expect(user).To(Equal("ex-aao-user"))
+			Expect(password).To(Equal("ex-aao-pass"))
+
+			_, _, err = c.getClusterCredentials("test", map[string]string{"AMQ_CREDENTIALS_SECRET_NAME": "other-credentials-secret"})
+			Expect(err).ShouldNot(Succeed())
+		})
+
+		It("testing scale to zero target host is resolved to endpoints", func() {
+			defer func(lookup func(string) ([]string, error)) { lookupHost = lookup }(lookupHost)
+			lookupHost = func(host string) ([]string, error) {
+				return []string{"10.0.0.2", "10.0.0.1"}, nil
+			}
+
+			sts := newBrokerStatefulSet()
+			c := &Controller{
+				kubeclientset: fake.NewSimpleClientset(),
+				ssToCrMap:     map[types.NamespacedName]*brokerv1beta1.ActiveMQArtemisScaledown{},
+			}
+			target := &brokerv1beta1.ScaleToZeroTargetType{Host: "broker.example.com"}
+			progress := map[int]brokerv1beta1.DrainOrdinalStatus{}
+
+			ready, err := c.isScaleToZeroTargetReady(sts, target, c.syncDrainTargetEndpoints(sts, target), 0, progress)
+			Expect(err).Should(Succeed())
+			Expect(ready).To(BeTrue())
+			Expect(progress[0].State).To(Equal(brokerv1beta1.DrainStatePending))
+
+			endpoints, err := c.kubeclientset.CoreV1().Endpoints(sts.Namespace).Get(context.TODO(), "ex-aao-ss-drain-target", metav1.GetOptions{})
+			Expect(err).Should(Succeed())
+			Expect(endpoints.Subsets[0].Addresses).To(Equal([]corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}}))
+			Expect(endpoints.Subsets[0].Ports[0].Port).To(Equal(int32(61616)))
+
+			service, err := c.kubeclientset.CoreV1().Services(sts.Namespace).Get(context.TODO(), "ex-aao-ss-drain-target", metav1.GetOptions{})
+			Expect(err).Should(Succeed())
+			Expect(service.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+
+			// the endpoints follow the addresses and the port of the target
+			lookupHost = func(host string) ([]string, error) {
+				return []string{"10.0.0.3"}, nil
+			}
+			target.Port = 61617
+			Expect(c.syncDrainTargetEndpoints(sts, target)).Should(Succeed())
+			endpoints, err = c.kubeclientset.CoreV1().Endpoints(sts.Namespace).Get(context.TODO(), "ex-aao-ss-drain-target", metav1.GetOptions{})
+			Expect(err).Should(Succeed())
+			Expect(endpoints.Subsets[0].Addresses).To(Equal([]corev1.EndpointAddress{{IP: "10.0.0.3"}}))
+			Expect(endpoints.Subsets[0].Ports[0].Port).To(Equal(int32(61617)))
+			service, err = c.kubeclientset.CoreV1().Services(sts.Namespace).Get(context.TODO(), "ex-aao-ss-drain-target", metav1.GetOptions{})
+			Expect(err).Should(Succeed())
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(61617)))
+		})
+
+		It("testing scale to zero target host that does not resolve is not ready", func() {
+			sts := newBrokerStatefulSet()
+			c := &Controller{kubeclientset: fake.NewSimpleClientset()}
+			target := &brokerv1beta1.ScaleToZeroTargetType{Host: "broker.example.com"}
+			progress := map[int]brokerv1beta1.DrainOrdinalStatus{}
+
+			ready, err := c.isScaleToZeroTargetReady(sts, target, fmt.Errorf("no such host"), 0, progress)
+			Expect(err).Should(Succeed())
+			Expect(ready).To(BeFalse())
+			Expect(progress[0].Message).To(Equal("Waiting for scale to zero target broker.example.com to resolve: no such host"))
+		})
+
+		It("testing scale to zero target without endpoints is not ready", func() {
+			sts := newBrokerStatefulSet()
+			c := &Controller{kubeclientset: fake.NewSimpleClientset(&corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-hdls-svc", Namespace: sts.Namespace},
+			})}
+			target := &brokerv1beta1.ScaleToZeroTargetType{HeadlessServiceName: "other-hdls-svc"}
+			progress := map[int]brokerv1beta1.DrainOrdinalStatus{}
+
+			ready, err := c.isScaleToZeroTargetReady(sts, target, nil, 1, progress)
+			Expect(err).Should(Succeed())
+			Expect(ready).To(BeFalse())
+			Expect(progress[1].Message).To(ContainSubstring("other-hdls-svc"))
+
+			target.HeadlessServiceName = "missing-hdls-svc"
+			ready, err = c.isScaleToZeroTargetReady(sts, target, nil, 1, progress)
+			Expect(err).Should(Succeed())
+			Expect(ready).To(BeFalse())
+		})
+
+		It("testing ordinal 0 is reported when scaled down to zero", func() {
+			merged := mergeDrainStatus(nil, 0, []int{1, 0}, map[int]brokerv1beta1.DrainOrdinalStatus{})
+			Expect(merged).To(HaveLen(2))
+			Expect(merged[0].Ordinal).To(Equal(int32(0)))
+		})
+	})
+
+	Context("Drain concurrency test", func() {
+		It("testing drain concurrency defaults to 1", func() {
+			sts := newBrokerStatefulSet()
+			scaledown := &brokerv1beta1.ActiveMQArtemisScaledown{}
+			c := &Controller{ssToCrMap: map[types.NamespacedName]*brokerv1beta1.ActiveMQArtemisScaledown{
+				{Namespace: sts.Namespace, Name: sts.Name}: scaledown,
+			}}
+			Expect(c.getDrainConcurrency(sts)).To(Equal(int32(1)))
+
+			concurrency := int32(3)
+			scaledown.Spec.DrainConcurrency = &concurrency
+			Expect(c.getDrainConcurrency(sts)).To(Equal(int32(3)))
+		})
+
+		It("testing drain target services of the remaining brokers are cleaned up", func() {
+			sts := newBrokerStatefulSet()
+			service := func(name string, podName string) *corev1.Service {
+				service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: sts.Namespace}}
+				if podName != "" {
+					service.Spec.Selector = map[string]string{appsv1.StatefulSetPodNameLabel: podName}
+				}
+				return service
+			}
+			c := &Controller{kubeclientset: fake.NewSimpleClientset(
+				service("ex-aao-ss-0-drain-target", "ex-aao-ss-0"),
+				service("ex-aao-ss-1-drain-target", "ex-aao-ss-1"),
+				service("ex-aao-ss-drain-target", ""),
+				service("other-ss-0-drain-target", "other-ss-0"),
+				service("ex-aao-ss-0-svc", "ex-aao-ss-0"),
+			)}
+
+			c.cleanupBrokerDrainTargetServices(sts)
+
+			services, err := c.kubeclientset.CoreV1().Services(sts.Namespace).List(context.TODO(), metav1.ListOptions{})
+			Expect(err).Should(Succeed())
+			var names []string
+			for _, service := range services.Items {
+				names = append(names, service.Name)
+			}
+			Expect(names).To(ConsistOf("ex-aao-ss-drain-target", "other-ss-0-drain-target", "ex-aao-ss-0-svc"))
+		})
+
+		It("testing parallel drains are limited and spread over the remaining brokers", func() {
+			replicas := int32(3)
+			concurrency := int32(2)
+			sts := newBrokerStatefulSet()
+			sts.Spec.Replicas = &replicas
+			sts.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "ex-aao"}}}
+
+			scaledown := &brokerv1beta1.ActiveMQArtemisScaledown{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "broker.amq.io/v1beta1", Kind: "ActiveMQArtemisScaledown"},
+				ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: sts.Namespace},
+				Spec:       brokerv1beta1.ActiveMQArtemisScaledownSpec{LocalOnly: true, DrainConcurrency: &concurrency},
+			}
+
+			pods := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			pvcs := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			objects := []runtime.Object{}
+			for ordinal := 0; ordinal < 6; ordinal++ {
+				pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: getPVCName(sts, "ex-aao", int32(ordinal)), Namespace: sts.Namespace}}
+				Expect(pvcs.Add(pvc)).Should(Succeed())
+				if int32(ordinal) < replicas {
+					pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: getPodName(sts, ordinal), Namespace: sts.Namespace}}
+					pod.Status.Phase = corev1.PodRunning
+					pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+					Expect(pods.Add(pod)).Should(Succeed())
+					objects = append(objects, &corev1.Endpoints{
+						ObjectMeta: metav1.ObjectMeta{Name: getBrokerDrainTargetServiceName(pod.Name), Namespace: sts.Namespace},
+						Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0." + strconv.Itoa(ordinal)}}}},
+					})
+				}
+			}
+
+			scheme := runtime.NewScheme()
+			Expect(brokerv1beta1.AddToScheme(scheme)).Should(Succeed())
+			Expect(corev1.AddToScheme(scheme)).Should(Succeed())
+			key := types.NamespacedName{Namespace: sts.Namespace, Name: sts.Name}
+			credentials := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ex-aao-credentials-secret", Namespace: sts.Namespace},
+				Data:       map[string][]byte{"AMQ_CLUSTER_USER": []byte("user"), "AMQ_CLUSTER_PASSWORD": []byte("pass")},
+			}
+			c := &Controller{
+				kubeclientset: fake.NewSimpleClientset(objects...),
+				podLister:     corelisters.NewPodLister(pods),
+				pvcLister:     corelisters.NewPersistentVolumeClaimLister(pvcs),
+				localOnly:     true,
+				ssNamesMap:    map[types.NamespacedName]map[string]string{key: {"CRNAME": "ex-aao", "HEADLESSSVCNAMEVALUE": "ex-aao-hdls-svc", "AMQ_CREDENTIALS_SECRET_NAME": credentials.Name}},
+				ssToCrMap:     map[types.NamespacedName]*brokerv1beta1.ActiveMQArtemisScaledown{key: scaledown},
+				client:        fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(scaledown, credentials).Build(),
+			}
+
+			Expect(c.processStatefulSet(sts)).Should(Succeed())
+
+			drainPods, err := c.kubeclientset.CoreV1().Pods(sts.Namespace).List(context.TODO(), metav1.ListOptions{})
+			Expect(err).Should(Succeed())
+			Expect(drainPods.Items).To(HaveLen(2))
+			targets := map[string]string{}
+			for _, pod := range drainPods.Items {
+				targets[pod.Name] = pod.Annotations[AnnotationDrainTarget]
+			}
+			Expect(targets).To(Equal(map[string]string{"ex-aao-ss-5": "ex-aao-ss-0", "ex-aao-ss-4": "ex-aao-ss-1"}))
+
+			status := &brokerv1beta1.ActiveMQArtemisScaledown{}
+			Expect(c.client.Get(context.TODO(), types.NamespacedName{Namespace: sts.Namespace, Name: "ex-aao"}, status)).Should(Succeed())
+			Expect(status.Status.Concurrency).To(Equal(int32(2)))
+			Expect(status.Status.Ordinals).To(HaveLen(3))
+			Expect(status.Status.Ordinals[0].Ordinal).To(Equal(int32(3)))
+			Expect(status.Status.Ordinals[0].State).To(Equal(brokerv1beta1.DrainStatePending))
+			Expect(status.Status.Ordinals[0].Message).To(Equal("Waiting for one of the 2 drains in progress to complete"))
+			Expect(status.Status.Ordinals[2].State).To(Equal(brokerv1beta1.DrainStateDraining))
+			Expect(status.Status.Ordinals[2].Target).To(Equal("ex-aao-ss-0"))
+		})
 	})
 })
+
+func newBrokerStatefulSet() *appsv1.StatefulSet {
+	var fsGroup int64 = 185
+	sts := &appsv1.StatefulSet{}
+	sts.Name = "ex-aao-ss"
+	sts.Namespace = "test"
+	sts.Spec.Template.Spec = corev1.PodSpec{
+		NodeSelector:     map[string]string{"zone": "a"},
+		Tolerations:      []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "brokers", Effect: corev1.TaintEffectNoSchedule}},
+		SecurityContext:  &corev1.PodSecurityContext{FSGroup: &fsGroup},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "broker-pull-secret"}},
+		Containers: []corev1.Container{
+			{
+				Name:  "ex-aao-container",
+				Image: "quay.io/artemiscloud/activemq-artemis-broker-kubernetes:latest",
+			},
+		},
+	}
+	return sts
+}
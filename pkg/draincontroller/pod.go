@@ -0,0 +1,165 @@
+package draincontroller
+
+import (
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const drainContainerName = "drainer-amq"
+
+var drainCommand = []string{"/bin/sh", "-c", "echo \"Starting the drainer\" ; /opt/amq/bin/drain.sh ; EXIT_CODE=$?  ; echo \"Drain completed! Exit code $?\"; exit $EXIT_CODE"}
+
+// newDrainPod builds the drain pod of a broker StatefulSet, it runs the broker image and
+// inherits the scheduling and security settings of the broker pods so that it can be
+// placed where the broker claims can be mounted. The anti-affinity of the broker pods is
+// not inherited, it would keep the drain pod away from the remaining brokers
+func newDrainPod(sts *appsv1.StatefulSet, ssNames map[string]string, clusterUser string, clusterPassword string, serviceAccount string) *corev1.Pod {
+	crName := ssNames["CRNAME"]
+	dataDir := "/opt/" + crName + "/data"
+	brokerPodSpec := &sts.Spec.Template.Spec
+	brokerContainer := &brokerPodSpec.Containers[0]
+	var terminationGracePeriodSeconds int64 = 5
+
+	pod := &corev1.Pod{}
+	pod.Labels = map[string]string{
+		"app": crName + "-amq-drainer",
+	}
+	pod.Annotations = map[string]string{}
+	pod.Spec = corev1.PodSpec{
+		ServiceAccountName:            serviceAccount,
+		DeprecatedServiceAccount:      serviceAccount,
+		TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+		RestartPolicy:                 corev1.RestartPolicyOnFailure,
+		NodeSelector:                  brokerPodSpec.NodeSelector,
+		Affinity:                      drainPodAffinity(brokerPodSpec.Affinity),
+		Tolerations:                   brokerPodSpec.Tolerations,
+		SecurityContext:               brokerPodSpec.SecurityContext,
+		ImagePullSecrets:              brokerPodSpec.ImagePullSecrets,
+		PriorityClassName:             brokerPodSpec.PriorityClassName,
+		Containers: []corev1.Container{
+			{
+				Name:            drainContainerName,
+				Image:           brokerContainer.Image,
+				ImagePullPolicy: brokerContainer.ImagePullPolicy,
+				SecurityContext: brokerContainer.SecurityContext,
+				Command:         drainCommand,
+				Env:             drainPodEnv(ssNames, dataDir, clusterUser, clusterPassword),
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      crName,
+						MountPath: dataDir,
+					},
+				},
+			},
+		},
+	}
+	return pod
+}
+
+func drainPodAffinity(affinity *corev1.Affinity) *corev1.Affinity {
+	if affinity == nil || affinity.NodeAffinity == nil && affinity.PodAffinity == nil {
+		return nil
+	}
+	return &corev1.Affinity{
+		NodeAffinity: affinity.NodeAffinity,
+		PodAffinity:  affinity.PodAffinity,
+	}
+}
+
+func drainPodEnv(ssNames map[string]string, dataDir string, clusterUser string, clusterPassword string) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "AMQ_EXTRA_ARGS", Value: "--no-autotune"},
+		{Name: "HEADLESS_SVC_NAME", Value: ssNames["HEADLESSSVCNAMEVALUE"]},
+		{Name: "PING_SVC_NAME", Value: ssNames["PINGSVCNAMEVALUE"]},
+		{Name: "AMQ_USER", Value: "admin"},
+		{Name: "AMQ_PASSWORD", Value: "admin"},
+		{Name: "AMQ_ROLE", Value: "admin"},
+		{Name: "AMQ_NAME", Value: "amq-broker"},
+		{Name: "AMQ_TRANSPORTS", Value: "openwire,amqp,stomp,mqtt,hornetq"},
+		{Name: "AMQ_GLOBAL_MAX_SIZE", Value: "100mb"},
+		{Name: "AMQ_DATA_DIR", Value: dataDir},
+		{Name: "AMQ_DATA_DIR_LOGGING", Value: "true"},
+		{Name: "AMQ_CLUSTERED", Value: "true"},
+		{Name: "AMQ_REPLICAS", Value: "1"},
+		{Name: "AMQ_CLUSTER_USER", Value: clusterUser},
+		{Name: "AMQ_CLUSTER_PASSWORD", Value: clusterPassword},
+		{
+			Name: "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.namespace",
+				},
+			},
+		},
+		{Name: "OPENSHIFT_DNS_PING_SERVICE_PORT", Value: "7800"},
+	}
+}
+
+// applyDrainPodTemplate overrides what the drain pod inherited from the broker pods
+// with what is set in the drain pod template
+func applyDrainPodTemplate(pod *corev1.Pod, template *brokerv1beta1.DrainPodTemplateType) {
+	if template == nil {
+		return
+	}
+
+	for k, v := range template.Labels {
+		pod.Labels[k] = v
+	}
+	for k, v := range template.Annotations {
+		pod.Annotations[k] = v
+	}
+	if len(template.NodeSelector) > 0 {
+		pod.Spec.NodeSelector = template.NodeSelector
+	}
+	if len(template.Tolerations) > 0 {
+		pod.Spec.Tolerations = template.Tolerations
+	}
+	if template.Affinity != nil {
+		pod.Spec.Affinity = &corev1.Affinity{
+			NodeAffinity:    template.Affinity.NodeAffinity,
+			PodAffinity:     template.Affinity.PodAffinity,
+			PodAntiAffinity: template.Affinity.PodAntiAffinity,
+		}
+	}
+	if template.PodSecurityContext != nil {
+		pod.Spec.SecurityContext = template.PodSecurityContext
+	}
+	if len(template.ImagePullSecrets) > 0 {
+		pod.Spec.ImagePullSecrets = template.ImagePullSecrets
+	}
+	if template.PriorityClassName != "" {
+		pod.Spec.PriorityClassName = template.PriorityClassName
+	}
+
+	drainContainer := &pod.Spec.Containers[0]
+	if template.ContainerSecurityContext != nil {
+		drainContainer.SecurityContext = template.ContainerSecurityContext
+	}
+	// appended so that they take precedence over the default ones
+	drainContainer.Env = append(drainContainer.Env, template.Env...)
+
+	// the drain is completed once the drain container exits, the sidecars may keep running
+	pod.Spec.Containers = append(pod.Spec.Containers, template.Sidecars...)
+}
+
+// drainContainerStatus returns the status of the drain container, the sidecars of the drain
+// pod template report theirs too
+func drainContainerStatus(pod *corev1.Pod) *corev1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == drainContainerName {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
+
+// isDrainCompleted tells whether the drain container of a drain pod exited successfully,
+// the pod does not succeed while a sidecar is still running
+func isDrainCompleted(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded {
+		return true
+	}
+	containerStatus := drainContainerStatus(pod)
+	return containerStatus != nil && containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode == 0
+}
@@ -0,0 +1,172 @@
+package draincontroller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// the broker logs the number of messages it moved once the scale down is done
+var scaledDownMessagesPattern = regexp.MustCompile(`Scaled down (\d+) messages`)
+
+func newPendingDrainStatus(ordinal int, message string) brokerv1beta1.DrainOrdinalStatus {
+	return brokerv1beta1.DrainOrdinalStatus{
+		Ordinal: int32(ordinal),
+		State:   brokerv1beta1.DrainStatePending,
+		Message: message,
+	}
+}
+
+// getDrainPodStatus derives the drain state of an ordinal from its drain pod
+func getDrainPodStatus(pod *corev1.Pod, ordinal int) brokerv1beta1.DrainOrdinalStatus {
+	startTime := pod.CreationTimestamp
+	status := brokerv1beta1.DrainOrdinalStatus{
+		Ordinal:      int32(ordinal),
+		State:        brokerv1beta1.DrainStateDraining,
+		DrainPodName: pod.Name,
+		Target:       pod.Annotations[AnnotationDrainTarget],
+		StartTime:    &startTime,
+	}
+
+	containerStatus := drainContainerStatus(pod)
+
+	switch {
+	case isDrainCompleted(pod):
+		status.State = brokerv1beta1.DrainStateCompleted
+		status.FinishTime = getDrainPodFinishTime(containerStatus)
+	case pod.Status.Phase == corev1.PodFailed:
+		status.State = brokerv1beta1.DrainStateFailed
+		status.FinishTime = getDrainPodFinishTime(containerStatus)
+		status.Message = pod.Status.Message
+		if containerStatus != nil && containerStatus.State.Terminated != nil {
+			terminated := containerStatus.State.Terminated
+			status.Message = fmt.Sprintf("drain container terminated with exit code %d: %s", terminated.ExitCode, terminated.Reason)
+		}
+	default:
+		if containerStatus != nil {
+			if containerStatus.State.Waiting != nil && containerStatus.State.Waiting.Reason != "" {
+				status.Message = "drain container waiting: " + containerStatus.State.Waiting.Reason
+			} else if containerStatus.RestartCount > 0 {
+				status.Message = fmt.Sprintf("drain container restarted %d times", containerStatus.RestartCount)
+			}
+		}
+	}
+	return status
+}
+
+func getDrainPodFinishTime(containerStatus *corev1.ContainerStatus) *metav1.Time {
+	if containerStatus != nil && containerStatus.State.Terminated != nil {
+		finishedAt := containerStatus.State.Terminated.FinishedAt
+		return &finishedAt
+	}
+	now := metav1.Now().Rfc3339Copy()
+	return &now
+}
+
+// getMessagesMigrated reads the number of messages drained from the drain pod log
+func (c *Controller) getMessagesMigrated(pod *corev1.Pod) *int64 {
+	logs, err := c.kubeclientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: drainContainerName}).DoRaw(context.TODO())
+	if err != nil {
+		dlog.Info("Unable to read drain pod log", "pod", pod.Name, "error", err)
+		return nil
+	}
+	return parseMessagesMigrated(string(logs))
+}
+
+func parseMessagesMigrated(logs string) *int64 {
+	matches := scaledDownMessagesPattern.FindAllStringSubmatch(logs, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	count, err := strconv.ParseInt(matches[len(matches)-1][1], 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &count
+}
+
+// updateDrainStatus records the drain state of the scaled down ordinals of a StatefulSet
+// on the ActiveMQArtemisScaledown that owns its drain pods
+func (c *Controller) updateDrainStatus(sts *appsv1.StatefulSet, ordinals []int, progress map[int]brokerv1beta1.DrainOrdinalStatus, concurrency int32) {
+	ssNamesKey := types.NamespacedName{
+		Namespace: sts.Namespace,
+		Name:      sts.Name,
+	}
+	ownerCr, found := c.ssToCrMap[ssNamesKey]
+	if !found || ownerCr == nil {
+		return
+	}
+
+	current := &brokerv1beta1.ActiveMQArtemisScaledown{}
+	crKey := types.NamespacedName{Namespace: ownerCr.Namespace, Name: ownerCr.Name}
+	if err := c.client.Get(context.TODO(), crKey, current); err != nil {
+		dlog.Error(err, "unable to retrieve scaledown to update its status", "scaledown", crKey)
+		return
+	}
+
+	ordinalsStatus := mergeDrainStatus(current.Status.Ordinals, *sts.Spec.Replicas, ordinals, progress)
+	if reflect.DeepEqual(current.Status.Ordinals, ordinalsStatus) && current.Status.Concurrency == concurrency {
+		return
+	}
+
+	current.Status.Ordinals = ordinalsStatus
+	current.Status.Concurrency = concurrency
+	if err := c.client.Status().Update(context.TODO(), current); err != nil {
+		dlog.Error(err, "failed to update scaledown status", "scaledown", crKey)
+	}
+}
+
+// mergeDrainStatus combines what was observed during the last pass with the previous status.
+// Ordinals that were not visited keep their previous state and finished ordinals, whose claims
+// are gone, are kept until the StatefulSet is scaled up over them again
+func mergeDrainStatus(previous []brokerv1beta1.DrainOrdinalStatus, replicas int32, ordinals []int, progress map[int]brokerv1beta1.DrainOrdinalStatus) []brokerv1beta1.DrainOrdinalStatus {
+	previousByOrdinal := map[int32]brokerv1beta1.DrainOrdinalStatus{}
+	for _, status := range previous {
+		previousByOrdinal[status.Ordinal] = status
+	}
+
+	var merged []brokerv1beta1.DrainOrdinalStatus
+	seen := map[int32]bool{}
+	for _, ordinal := range ordinals {
+		// ordinal 0 is only drained when scaled down to zero
+		if int32(ordinal) < replicas {
+			continue
+		}
+		status, visited := progress[ordinal]
+		previousStatus, hasPrevious := previousByOrdinal[int32(ordinal)]
+		if !visited {
+			if hasPrevious {
+				status = previousStatus
+			} else {
+				status = newPendingDrainStatus(ordinal, "")
+			}
+		} else if hasPrevious && previousStatus.DrainPodName == status.DrainPodName && status.MessagesMigrated == nil {
+			status.MessagesMigrated = previousStatus.MessagesMigrated
+		}
+		merged = append(merged, status)
+		seen[int32(ordinal)] = true
+	}
+
+	for _, status := range previous {
+		if seen[status.Ordinal] || status.Ordinal < replicas {
+			continue
+		}
+		if status.State == brokerv1beta1.DrainStateCompleted || status.State == brokerv1beta1.DrainStateFailed {
+			merged = append(merged, status)
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Ordinal < merged[j].Ordinal
+	})
+	return merged
+}
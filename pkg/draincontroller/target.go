@@ -0,0 +1,327 @@
+package draincontroller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var lookupHost = net.LookupHost
+
+func (c *Controller) getScaleToZeroTarget(sts *appsv1.StatefulSet) *brokerv1beta1.ScaleToZeroTargetType {
+	ownerCr, found := c.ssToCrMap[types.NamespacedName{Namespace: sts.Namespace, Name: sts.Name}]
+	if !found || ownerCr == nil {
+		return nil
+	}
+	return ownerCr.Spec.ScaleToZeroTarget
+}
+
+// getDrainTargetServiceName is the headless service whose endpoints the drain pods connect to when
+// the brokers are drained to a host, the drain pods only discover the brokers through endpoints
+func getDrainTargetServiceName(sts *appsv1.StatefulSet) string {
+	return sts.Name + "-drain-target"
+}
+
+func getScaleToZeroTargetServiceName(sts *appsv1.StatefulSet, target *brokerv1beta1.ScaleToZeroTargetType) string {
+	if target.HeadlessServiceName != "" {
+		return target.HeadlessServiceName
+	}
+	return getDrainTargetServiceName(sts)
+}
+
+// drainTarget is where a drain pod moves the messages to
+type drainTarget struct {
+	// reported in the drain status
+	name string
+	// the headless service whose endpoints the drain pod connects to
+	serviceName string
+	// the secret with the cluster credentials of the target, when not the scaled down broker one
+	credentialsSecretName string
+}
+
+func newScaleToZeroDrainTarget(sts *appsv1.StatefulSet, target *brokerv1beta1.ScaleToZeroTargetType) *drainTarget {
+	serviceName := getScaleToZeroTargetServiceName(sts, target)
+	name := serviceName
+	if target.HeadlessServiceName == "" {
+		name = target.Host
+	}
+	return &drainTarget{
+		name:                  name,
+		serviceName:           serviceName,
+		credentialsSecretName: target.CredentialsSecretName,
+	}
+}
+
+// withDrainTarget points the drain pod data of a StatefulSet at a drain target
+func withDrainTarget(ssNames map[string]string, target *drainTarget) map[string]string {
+	targetNames := make(map[string]string, len(ssNames))
+	for k, v := range ssNames {
+		targetNames[k] = v
+	}
+	targetNames["HEADLESSSVCNAMEVALUE"] = target.serviceName
+	if target.credentialsSecretName != "" {
+		targetNames["AMQ_CREDENTIALS_SECRET_NAME"] = target.credentialsSecretName
+	}
+	return targetNames
+}
+
+// isScaleToZeroTargetReady checks that there is a broker to drain to, a drain pod finding none
+// would complete without moving the messages and the claims would be removed
+// the endpoints of a host are synced beforehand, syncErr is the failure to resolve it
+func (c *Controller) isScaleToZeroTargetReady(sts *appsv1.StatefulSet, target *brokerv1beta1.ScaleToZeroTargetType, syncErr error, ordinal int, progress map[int]brokerv1beta1.DrainOrdinalStatus) (bool, error) {
+	serviceName := getScaleToZeroTargetServiceName(sts, target)
+	progress[ordinal] = newPendingDrainStatus(ordinal, "Waiting for scale to zero target "+serviceName+" to be ready to receive the messages")
+
+	if syncErr != nil {
+		progress[ordinal] = newPendingDrainStatus(ordinal, "Waiting for scale to zero target "+target.Host+" to resolve: "+syncErr.Error())
+		return false, nil
+	}
+
+	endpoints, err := c.kubeclientset.CoreV1().Endpoints(sts.Namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			dlog.Info("Scale to zero target endpoints not found, waiting for them", "service", serviceName)
+			return false, nil
+		}
+		return false, err
+	}
+	return hasReadyAddresses(endpoints), nil
+}
+
+func getScaleToZeroTargetPort(target *brokerv1beta1.ScaleToZeroTargetType) int32 {
+	if target.Port > 0 {
+		return target.Port
+	}
+	return brokerv1beta1.DefaultDrainTargetPort
+}
+
+func hasReadyAddresses(endpoints *corev1.Endpoints) bool {
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// syncDrainTargetEndpoints maintains a headless service without selector whose endpoints are the
+// addresses of the scale to zero target host. It is called on every sync so that the endpoints
+// follow the addresses of the host, a drain pod reads them again when it restarts
+func (c *Controller) syncDrainTargetEndpoints(sts *appsv1.StatefulSet, target *brokerv1beta1.ScaleToZeroTargetType) error {
+	if target.HeadlessServiceName != "" {
+		return nil
+	}
+	if target.Host == "" {
+		return fmt.Errorf("no host to drain StatefulSet %s to", sts.Name)
+	}
+	addresses, err := getDrainTargetAddresses(target.Host)
+	if err != nil {
+		return err
+	}
+
+	name := getDrainTargetServiceName(sts)
+	objectMeta := c.newDrainTargetObjectMeta(sts, name)
+	port := getScaleToZeroTargetPort(target)
+
+	servicePorts := []corev1.ServicePort{{Name: "core", Port: port, Protocol: corev1.ProtocolTCP}}
+	services := c.kubeclientset.CoreV1().Services(sts.Namespace)
+	service, err := services.Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		service = &corev1.Service{
+			ObjectMeta: objectMeta,
+			Spec: corev1.ServiceSpec{
+				ClusterIP: corev1.ClusterIPNone,
+				Ports:     servicePorts,
+			},
+		}
+		if _, err = services.Create(context.TODO(), service, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else if !reflect.DeepEqual(service.Spec.Ports, servicePorts) {
+		service.Spec.Ports = servicePorts
+		if _, err = services.Update(context.TODO(), service, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	subsets := []corev1.EndpointSubset{
+		{
+			Addresses: addresses,
+			Ports:     []corev1.EndpointPort{{Name: "core", Port: port, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+	endpointsClient := c.kubeclientset.CoreV1().Endpoints(sts.Namespace)
+	endpoints, err := endpointsClient.Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = endpointsClient.Create(context.TODO(), &corev1.Endpoints{ObjectMeta: objectMeta, Subsets: subsets}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(endpoints.Subsets, subsets) {
+		endpoints.Subsets = subsets
+		_, err = endpointsClient.Update(context.TODO(), endpoints, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+func getDrainTargetAddresses(host string) ([]corev1.EndpointAddress, error) {
+	ips := []string{host}
+	if net.ParseIP(host) == nil {
+		var err error
+		if ips, err = lookupHost(host); err != nil {
+			return nil, err
+		}
+	}
+	// keep the endpoints stable whatever the order of the resolved addresses
+	sort.Strings(ips)
+	addresses := make([]corev1.EndpointAddress, 0, len(ips))
+	for _, ip := range ips {
+		addresses = append(addresses, corev1.EndpointAddress{IP: ip})
+	}
+	return addresses, nil
+}
+
+func (c *Controller) getDrainConcurrency(sts *appsv1.StatefulSet) int32 {
+	ownerCr, found := c.ssToCrMap[types.NamespacedName{Namespace: sts.Namespace, Name: sts.Name}]
+	if !found || ownerCr == nil || ownerCr.Spec.DrainConcurrency == nil || *ownerCr.Spec.DrainConcurrency < 1 {
+		return 1
+	}
+	return *ownerCr.Spec.DrainConcurrency
+}
+
+// getBrokerDrainTargetServiceName is the headless service selecting a single remaining broker pod,
+// the drain pods only discover the brokers through endpoints
+func getBrokerDrainTargetServiceName(podName string) string {
+	return podName + "-drain-target"
+}
+
+// getRemainingBrokerDrainTarget picks the ready remaining broker with the fewest drains in progress,
+// nil is returned when there is none to drain to yet
+func (c *Controller) getRemainingBrokerDrainTarget(sts *appsv1.StatefulSet, ordinal int, drainsPerTarget map[string]int, progress map[int]brokerv1beta1.DrainOrdinalStatus) (*drainTarget, error) {
+	progress[ordinal] = newPendingDrainStatus(ordinal, "Waiting for a remaining broker pod to be ready to receive the messages")
+
+	var targetPodName string
+	for remaining := 0; remaining < int(*sts.Spec.Replicas); remaining++ {
+		podName := getPodName(sts, remaining)
+		pod, err := c.podLister.Pods(sts.Namespace).Get(podName)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !isPodReady(pod) {
+			continue
+		}
+		if targetPodName == "" || drainsPerTarget[podName] < drainsPerTarget[targetPodName] {
+			targetPodName = podName
+		}
+	}
+	if targetPodName == "" {
+		return nil, nil
+	}
+
+	serviceName := getBrokerDrainTargetServiceName(targetPodName)
+	progress[ordinal] = newPendingDrainStatus(ordinal, "Waiting for drain target "+serviceName+" to be ready to receive the messages")
+	if err := c.syncBrokerDrainTargetService(sts, targetPodName); err != nil {
+		return nil, err
+	}
+	endpoints, err := c.kubeclientset.CoreV1().Endpoints(sts.Namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !hasReadyAddresses(endpoints) {
+		return nil, nil
+	}
+
+	return &drainTarget{
+		name:        targetPodName,
+		serviceName: serviceName,
+	}, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// syncBrokerDrainTargetService creates the headless service whose endpoints are the single broker pod to drain to
+func (c *Controller) syncBrokerDrainTargetService(sts *appsv1.StatefulSet, podName string) error {
+	name := getBrokerDrainTargetServiceName(podName)
+	services := c.kubeclientset.CoreV1().Services(sts.Namespace)
+	_, err := services.Get(context.TODO(), name, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		return err
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: c.newDrainTargetObjectMeta(sts, name),
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector: map[string]string{
+				appsv1.StatefulSetPodNameLabel: podName,
+			},
+			Ports: []corev1.ServicePort{{Name: "core", Port: brokerv1beta1.DefaultDrainTargetPort, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+	_, err = services.Create(context.TODO(), service, metav1.CreateOptions{})
+	return err
+}
+
+// cleanupBrokerDrainTargetServices deletes the services selecting the remaining broker pods of a
+// StatefulSet once none of its drains is in progress
+func (c *Controller) cleanupBrokerDrainTargetServices(sts *appsv1.StatefulSet) {
+	services := c.kubeclientset.CoreV1().Services(sts.Namespace)
+	list, err := services.List(context.TODO(), metav1.ListOptions{LabelSelector: labels.SelectorFromSet(c.ssLabels).String()})
+	if err != nil {
+		dlog.Error(err, "Error while listing the drain target services", "sts", sts.Name)
+		return
+	}
+	for _, service := range list.Items {
+		podName := service.Spec.Selector[appsv1.StatefulSetPodNameLabel]
+		if !strings.HasPrefix(podName, sts.Name+"-") || service.Name != getBrokerDrainTargetServiceName(podName) {
+			continue
+		}
+		dlog.Info("Deleting drain target service " + service.Name)
+		if err := services.Delete(context.TODO(), service.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			dlog.Error(err, "Error while deleting the drain target service", "service", service.Name)
+		}
+	}
+}
+
+func (c *Controller) newDrainTargetObjectMeta(sts *appsv1.StatefulSet, name string) metav1.ObjectMeta {
+	objectMeta := metav1.ObjectMeta{
+		Name:      name,
+		Namespace: sts.Namespace,
+		Labels:    c.ssLabels,
+	}
+	ownerCr := c.ssToCrMap[types.NamespacedName{Namespace: sts.Namespace, Name: sts.Name}]
+	if ownerCr != nil {
+		objectMeta.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(ownerCr, ownerCr.GroupVersionKind())}
+	}
+	return objectMeta
+}
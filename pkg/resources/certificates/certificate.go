@@ -0,0 +1,114 @@
+package certificates
+
+import (
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var CertificateGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+const (
+	DefaultIssuerKind  = "Issuer"
+	DefaultIssuerGroup = "cert-manager.io"
+
+	// keys of the secret cert-manager issues
+	CAKey               = "ca.crt"
+	PKCS12KeyStoreKey   = "keystore.p12"
+	PKCS12TrustStoreKey = "truststore.p12"
+
+	// key of the keystore password in the secret referenced by a certificate
+	KeyStorePasswordKey = "password"
+)
+
+func NewCertificate() *unstructured.Unstructured {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	return certificate
+}
+
+func NewCertificateList() *unstructured.UnstructuredList {
+	certificates := &unstructured.UnstructuredList{}
+	certificates.SetGroupVersionKind(CertificateGVK.GroupVersion().WithKind(CertificateGVK.Kind + "List"))
+	return certificates
+}
+
+// NewCertificateForCR returns a Certificate issuing the secret with the given dns names, with a
+// keyStorePasswordSecret cert-manager adds pkcs12 key and trust stores to the secret
+func NewCertificateForCR(namespacedName types.NamespacedName, labels map[string]string, secretName string, certManager *brokerv1beta1.CertManagerType, dnsNames []string, keyStorePasswordSecret string) *unstructured.Unstructured {
+
+	certificate := NewCertificate()
+	certificate.SetName(secretName)
+	certificate.SetNamespace(namespacedName.Namespace)
+	certificate.SetLabels(labels)
+
+	issuerRef := map[string]interface{}{
+		"name":  certManager.IssuerRef.Name,
+		"kind":  DefaultIssuerKind,
+		"group": DefaultIssuerGroup,
+	}
+	if certManager.IssuerRef.Kind != "" {
+		issuerRef["kind"] = certManager.IssuerRef.Kind
+	}
+	if certManager.IssuerRef.Group != "" {
+		issuerRef["group"] = certManager.IssuerRef.Group
+	}
+
+	names := make([]interface{}, 0, len(dnsNames))
+	for _, name := range dnsNames {
+		names = append(names, name)
+	}
+
+	spec := map[string]interface{}{
+		"secretName": secretName,
+		"issuerRef":  issuerRef,
+		"dnsNames":   names,
+		"usages":     []interface{}{"server auth", "client auth"},
+	}
+	if certManager.Duration != nil {
+		spec["duration"] = certManager.Duration.Duration.String()
+	}
+	if certManager.RenewBefore != nil {
+		spec["renewBefore"] = certManager.RenewBefore.Duration.String()
+	}
+	if keyStorePasswordSecret != "" {
+		spec["keystores"] = map[string]interface{}{
+			"pkcs12": map[string]interface{}{
+				"create": true,
+				"passwordSecretRef": map[string]interface{}{
+					"name": keyStorePasswordSecret,
+					"key":  KeyStorePasswordKey,
+				},
+			},
+		}
+	}
+	certificate.Object["spec"] = spec
+
+	return certificate
+}
+
+// IsReady returns whether the Ready condition of the certificate is true, and its message
+func IsReady(certificate *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		message, _ := condition["message"].(string)
+		return condition["status"] == "True", message
+	}
+	return false, "not issued yet"
+}
+
+// Revision returns the revision of the issued certificate, cert-manager increments it on each
+// issuance from 1 for the first one
+func Revision(certificate *unstructured.Unstructured) int64 {
+	revision, _, _ := unstructured.NestedInt64(certificate.Object, "status", "revision")
+	return revision
+}
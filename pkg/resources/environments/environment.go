@@ -15,15 +15,9 @@ import (
 
 var log = logf.Log.WithName("package environments")
 
-//TODO: Remove this blatant hack
-var GLOBAL_AMQ_CLUSTER_USER string = ""
-var GLOBAL_AMQ_CLUSTER_PASSWORD string = ""
-
 type defaults struct {
-	AMQ_USER             string
-	AMQ_PASSWORD         string
-	AMQ_CLUSTER_USER     string
-	AMQ_CLUSTER_PASSWORD string
+	AMQ_USER     string
+	AMQ_PASSWORD string
 }
 
 var Defaults defaults
@@ -35,16 +29,6 @@ func init() {
 	if "" == Defaults.AMQ_PASSWORD {
 		Defaults.AMQ_PASSWORD = random.GenerateRandomString(8)
 	}
-	if "" == Defaults.AMQ_CLUSTER_USER {
-		Defaults.AMQ_CLUSTER_USER = random.GenerateRandomString(8)
-		// TODO: remove this hack
-		GLOBAL_AMQ_CLUSTER_USER = Defaults.AMQ_CLUSTER_USER
-	}
-	if "" == Defaults.AMQ_CLUSTER_PASSWORD {
-		Defaults.AMQ_CLUSTER_PASSWORD = random.GenerateRandomString(8)
-		// TODO: remove this hack
-		GLOBAL_AMQ_CLUSTER_PASSWORD = Defaults.AMQ_CLUSTER_PASSWORD
-	}
 }
 
 func DetectOpenshift() (bool, error) {
@@ -71,6 +55,24 @@ func DetectOpenshift() (bool, error) {
 	return false, errors.New("environment not yet determined")
 }
 
+// DetectPrometheusOperator returns whether the prometheus operator was found when the operator started
+func DetectPrometheusOperator() bool {
+	exists, _ := common.GetStateManager().GetState(common.ServiceMonitorKind).(bool)
+	return exists
+}
+
+// DetectGatewayAPI returns whether the tls routes of the gateway api were found when the operator started
+func DetectGatewayAPI() bool {
+	exists, _ := common.GetStateManager().GetState(common.TLSRouteKind).(bool)
+	return exists
+}
+
+// DetectCertManager returns whether the certificates of cert-manager were found when the operator started
+func DetectCertManager() bool {
+	exists, _ := common.GetStateManager().GetState(common.CertificateKind).(bool)
+	return exists
+}
+
 func AddEnvVarForBasic(requireLogin string, journalType string, svcPingName string) []corev1.EnvVar {
 
 	envVarArray := []corev1.EnvVar{
@@ -0,0 +1,107 @@
+package gateways
+
+import (
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var TLSRouteGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1alpha2",
+	Kind:    "TLSRoute",
+}
+
+var GatewayGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1alpha2",
+	Kind:    "Gateway",
+}
+
+func NewGateway() *unstructured.Unstructured {
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(GatewayGVK)
+	return gateway
+}
+
+func NewTLSRoute() *unstructured.Unstructured {
+	tlsRoute := &unstructured.Unstructured{}
+	tlsRoute.SetGroupVersionKind(TLSRouteGVK)
+	return tlsRoute
+}
+
+func NewTLSRouteList() *unstructured.UnstructuredList {
+	tlsRoutes := &unstructured.UnstructuredList{}
+	tlsRoutes.SetGroupVersionKind(TLSRouteGVK.GroupVersion().WithKind(TLSRouteGVK.Kind + "List"))
+	return tlsRoutes
+}
+
+// NewTLSRouteForCR routes the tls connections of the gateway listener to the port of a service,
+// the gateway matches the server name of the connections with the host of the exposure, resolved
+// by the caller, or with a domain with the host of the service
+func NewTLSRouteForCR(namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, port int32, exposure *brokerv1beta1.ExposureType, domain string) *unstructured.Unstructured {
+
+	tlsRoute := NewTLSRoute()
+	tlsRoute.SetName(targetServiceName + "-tlsr")
+	tlsRoute.SetNamespace(namespacedName.Namespace)
+	tlsRoute.SetLabels(labels)
+	if len(exposure.Annotations) > 0 {
+		tlsRoute.SetAnnotations(exposure.Annotations)
+	}
+
+	parentRef := map[string]interface{}{
+		"name": exposure.GatewayRef.Name,
+	}
+	if exposure.GatewayRef.Namespace != "" {
+		parentRef["namespace"] = exposure.GatewayRef.Namespace
+	}
+	if exposure.GatewayRef.SectionName != "" {
+		parentRef["sectionName"] = exposure.GatewayRef.SectionName
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": targetServiceName,
+						"port": int64(port),
+					},
+				},
+			},
+		},
+	}
+	if exposure.Host != "" {
+		spec["hostnames"] = []interface{}{exposure.Host}
+	} else if domain != "" {
+		spec["hostnames"] = []interface{}{HostForService(targetServiceName, domain)}
+	}
+	tlsRoute.Object["spec"] = spec
+
+	return tlsRoute
+}
+
+// HostForService returns the host of the tls route of a service
+func HostForService(targetServiceName string, domain string) string {
+	return targetServiceName + "-tlsr." + domain
+}
+
+// ListenerPort returns the port of the gateway listener a tls route is attached to, the first
+// listener when the route does not name a section
+func ListenerPort(gateway *unstructured.Unstructured, sectionName string) int32 {
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	for _, item := range listeners {
+		listener, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _, _ := unstructured.NestedString(listener, "name"); sectionName != "" && name != sectionName {
+			continue
+		}
+		port, _, _ := unstructured.NestedInt64(listener, "port")
+		return int32(port)
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ package ingresses
 import (
 	//"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/selectors"
 
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -11,7 +12,24 @@ import (
 
 const defaultIngressDomain string = "apps.artemiscloud.io"
 
-func NewIngressForCRWithSSL(existing *netv1.Ingress, namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, targetPortName string, sslEnabled bool, domain string) *netv1.Ingress {
+// the ingress-nginx annotations of the passthrough and reencrypt tls termination
+const (
+	sslPassthroughAnnotation  = "nginx.ingress.kubernetes.io/ssl-passthrough"
+	backendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+)
+
+// NewIngressForCRWithSSL routes the host of the exposure to a port of a service, the tls connections
+// end where the tls termination requires. The host of the exposure is resolved by the caller
+func NewIngressForCRWithSSL(existing *netv1.Ingress, namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, targetPortName string, tlsTermination string, domain string, exposure *brokerv1beta1.ExposureType) *netv1.Ingress {
+
+	if exposure == nil {
+		exposure = &brokerv1beta1.ExposureType{}
+	}
+
+	path := "/"
+	if exposure.Path != "" {
+		path = exposure.Path
+	}
 
 	pathType := netv1.PathTypePrefix
 
@@ -39,7 +57,7 @@ func NewIngressForCRWithSSL(existing *netv1.Ingress, namespacedName types.Namesp
 				HTTP: &netv1.HTTPIngressRuleValue{
 					Paths: []netv1.HTTPIngressPath{
 						{
-							Path:     "/",
+							Path:     path,
 							PathType: &pathType,
 							Backend: netv1.IngressBackend{
 								Service: &netv1.IngressServiceBackend{
@@ -70,15 +88,44 @@ func NewIngressForCRWithSSL(existing *netv1.Ingress, namespacedName types.Namesp
 		}
 	}
 
-	if domain == "" {
-		domain = defaultIngressDomain
+	host := exposure.Host
+	if host == "" {
+		host = HostForService(targetServiceName, domain)
 	}
-
-	host := desired.GetObjectMeta().GetName() + "." + domain
 	desired.Spec.Rules[0].Host = host
-	if sslEnabled {
-		desired.Annotations = map[string]string{"nginx.ingress.kubernetes.io/ssl-passthrough": "true"}
+
+	annotations := map[string]string{}
+	desired.Spec.TLS = nil
+	switch tlsTermination {
+	case brokerv1beta1.TLSTerminationPassthrough:
+		annotations[sslPassthroughAnnotation] = "true"
 		desired.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{host}}}
+	case brokerv1beta1.TLSTerminationEdge:
+		desired.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{host}, SecretName: exposure.TLSSecret}}
+	case brokerv1beta1.TLSTerminationReencrypt:
+		annotations[backendProtocolAnnotation] = "HTTPS"
+		desired.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{host}, SecretName: exposure.TLSSecret}}
+	}
+	for key, value := range exposure.Annotations {
+		annotations[key] = value
+	}
+	desired.Annotations = nil
+	if len(annotations) > 0 {
+		desired.Annotations = annotations
+	}
+
+	desired.Spec.IngressClassName = nil
+	if exposure.ClassName != "" {
+		className := exposure.ClassName
+		desired.Spec.IngressClassName = &className
 	}
 	return desired
 }
+
+// HostForService returns the host of the ingress of a service
+func HostForService(targetServiceName string, domain string) string {
+	if domain == "" {
+		domain = defaultIngressDomain
+	}
+	return targetServiceName + "-ing." + domain
+}
@@ -0,0 +1,181 @@
+package monitoring
+
+import (
+	"fmt"
+	"time"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var ServiceMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "ServiceMonitor",
+}
+
+var PrometheusRuleGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "PrometheusRule",
+}
+
+const (
+	// the metrics plugin serves the metrics on the console port
+	MetricsPortName = "metrics"
+	MetricsPort     = 8161
+	MetricsPath     = "/metrics"
+
+	DefaultInterval                     = 30 * time.Second
+	DefaultAddressMemoryUsagePercentage = 80
+	DefaultDeadLetterQueue              = "DLQ"
+	DefaultFor                          = 5 * time.Minute
+)
+
+func NewServiceMonitor() *unstructured.Unstructured {
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetGroupVersionKind(ServiceMonitorGVK)
+	return serviceMonitor
+}
+
+func NewPrometheusRule() *unstructured.Unstructured {
+	prometheusRule := &unstructured.Unstructured{}
+	prometheusRule.SetGroupVersionKind(PrometheusRuleGVK)
+	return prometheusRule
+}
+
+// NewServiceMonitorForCR scrapes the metrics port of the services with the selector labels, over
+// https with the tlsConfig when it is not nil
+func NewServiceMonitorForCR(namespacedName types.NamespacedName, name string, labels map[string]string, selectorLabels map[string]string, monitoring *brokerv1beta1.MonitoringType, tlsConfig map[string]interface{}) *unstructured.Unstructured {
+
+	serviceMonitor := NewServiceMonitor()
+	serviceMonitor.SetName(name)
+	serviceMonitor.SetNamespace(namespacedName.Namespace)
+	serviceMonitor.SetLabels(labels)
+
+	interval := DefaultInterval
+	if monitoring.Interval != nil {
+		interval = monitoring.Interval.Duration
+	}
+
+	endpoint := map[string]interface{}{
+		"port":     MetricsPortName,
+		"path":     MetricsPath,
+		"interval": promDuration(interval),
+		"scheme":   "http",
+	}
+	if tlsConfig != nil {
+		endpoint["scheme"] = "https"
+		endpoint["tlsConfig"] = tlsConfig
+	}
+
+	matchLabels := map[string]interface{}{}
+	for key, value := range selectorLabels {
+		matchLabels[key] = value
+	}
+
+	serviceMonitor.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": matchLabels,
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{namespacedName.Namespace},
+		},
+		"endpoints": []interface{}{endpoint},
+	}
+
+	return serviceMonitor
+}
+
+// NewPrometheusRuleForCR alerts on the metrics scraped from the metrics service and on the
+// readiness of the statefulset reported by kube-state-metrics. The backups of paired deployments
+// are never ready, pairedLiveBrokers is the number of live brokers expected ready when paired, zero otherwise
+func NewPrometheusRuleForCR(namespacedName types.NamespacedName, name string, labels map[string]string, metricsServiceName string, statefulSetName string, pairedLiveBrokers int32, alerts *brokerv1beta1.MonitoringAlertsType) *unstructured.Unstructured {
+
+	prometheusRule := NewPrometheusRule()
+	prometheusRule.SetName(name)
+	prometheusRule.SetNamespace(namespacedName.Namespace)
+	prometheusRule.SetLabels(labels)
+
+	memoryUsagePercentage := int32(DefaultAddressMemoryUsagePercentage)
+	if alerts.AddressMemoryUsagePercentage != nil {
+		memoryUsagePercentage = *alerts.AddressMemoryUsagePercentage
+	}
+	deadLetterQueue := DefaultDeadLetterQueue
+	if alerts.DeadLetterQueue != "" {
+		deadLetterQueue = alerts.DeadLetterQueue
+	}
+	forDuration := DefaultFor
+	if alerts.For != nil {
+		forDuration = alerts.For.Duration
+	}
+
+	brokerSelector := fmt.Sprintf(`namespace="%s",service="%s"`, namespacedName.Namespace, metricsServiceName)
+	statefulSetSelector := fmt.Sprintf(`namespace="%s",statefulset="%s"`, namespacedName.Namespace, statefulSetName)
+	expectedReady := fmt.Sprintf("kube_statefulset_status_replicas{%s}", statefulSetSelector)
+	if pairedLiveBrokers > 0 {
+		expectedReady = fmt.Sprintf("%d", pairedLiveBrokers)
+	}
+
+	rules := []interface{}{
+		alertingRule("ArtemisAddressMemoryUsageHigh",
+			fmt.Sprintf("artemis_address_memory_usage_percentage{%s} > %d", brokerSelector, memoryUsagePercentage),
+			forDuration, "warning",
+			"Broker address memory usage is high",
+			fmt.Sprintf("The addresses of broker pod {{ $labels.pod }} of %s use {{ $value }}%% of the global max size.", namespacedName.Name)),
+		alertingRule("ArtemisAddressPaging",
+			fmt.Sprintf("artemis_number_of_pages{%s} > 0", brokerSelector),
+			forDuration, "warning",
+			"Broker address is paging",
+			fmt.Sprintf("Address {{ $labels.address }} of broker pod {{ $labels.pod }} of %s pages messages to disk.", namespacedName.Name)),
+		alertingRule("ArtemisDeadLetterQueueGrowing",
+			fmt.Sprintf(`increase(artemis_messages_added{%s,queue="%s"}[%s]) > 0`, brokerSelector, deadLetterQueue, promDuration(forDuration)),
+			0, "warning",
+			"Broker dead letter queue is growing",
+			fmt.Sprintf("{{ $value }} messages were added to %s of broker pod {{ $labels.pod }} of %s.", deadLetterQueue, namespacedName.Name)),
+		alertingRule("ArtemisBrokerPodsNotReady",
+			fmt.Sprintf("kube_statefulset_status_replicas_ready{%s} < %s", statefulSetSelector, expectedReady),
+			forDuration, "critical",
+			"Broker pods are not ready",
+			fmt.Sprintf("Only {{ $value }} broker pods of %s are ready.", namespacedName.Name)),
+	}
+
+	prometheusRule.Object["spec"] = map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name":  namespacedName.Name + ".rules",
+				"rules": rules,
+			},
+		},
+	}
+
+	return prometheusRule
+}
+
+func alertingRule(alert string, expr string, forDuration time.Duration, severity string, summary string, description string) map[string]interface{} {
+	rule := map[string]interface{}{
+		"alert": alert,
+		"expr":  expr,
+		"labels": map[string]interface{}{
+			"severity": severity,
+		},
+		"annotations": map[string]interface{}{
+			"summary":     summary,
+			"description": description,
+		},
+	}
+	if forDuration > 0 {
+		rule["for"] = promDuration(forDuration)
+	}
+	return rule
+}
+
+// promDuration formats a duration the way prometheus parses it, without fractions
+func promDuration(duration time.Duration) string {
+	if duration%time.Minute == 0 {
+		return fmt.Sprintf("%dm", duration/time.Minute)
+	}
+	return fmt.Sprintf("%ds", duration/time.Second)
+}
@@ -1,13 +1,21 @@
 package routes
 
 import (
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
 	routev1 "github.com/openshift/api/route/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-func NewRouteDefinitionForCR(existing *routev1.Route, namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, targetPortName string, passthroughTLS bool, domain string) *routev1.Route {
+// NewRouteDefinitionForCR routes the host of the exposure to a port of a service, the tls connections
+// end where the tls termination requires. The host of the exposure is resolved by the caller and
+// the certificates of the edge and reencrypt termination are added by the caller
+func NewRouteDefinitionForCR(existing *routev1.Route, namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, targetPortName string, tlsTermination string, domain string, exposure *brokerv1beta1.ExposureType) *routev1.Route {
+
+	if exposure == nil {
+		exposure = &brokerv1beta1.ExposureType{}
+	}
 
 	var desired *routev1.Route = nil
 	if existing == nil {
@@ -39,7 +47,9 @@ func NewRouteDefinitionForCR(existing *routev1.Route, namespacedName types.Names
 		}
 	}
 
-	if domain != "" {
+	if exposure.Host != "" {
+		desired.Spec.Host = exposure.Host
+	} else if domain != "" {
 		desired.Spec.Host = desired.GetObjectMeta().GetName() + "." + domain
 	}
 
@@ -52,14 +62,37 @@ func NewRouteDefinitionForCR(existing *routev1.Route, namespacedName types.Names
 		Name: targetServiceName,
 	}
 
-	if passthroughTLS {
+	desired.Spec.Path = exposure.Path
+	switch tlsTermination {
+	case brokerv1beta1.TLSTerminationPassthrough:
+		// the router can not route the connections it does not decrypt by path
+		desired.Spec.Path = ""
 		desired.Spec.TLS = &routev1.TLSConfig{
 			Termination:                   routev1.TLSTerminationPassthrough,
 			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyNone,
 		}
-	} else {
+	case brokerv1beta1.TLSTerminationEdge:
+		desired.Spec.TLS = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationEdge,
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+	case brokerv1beta1.TLSTerminationReencrypt:
+		desired.Spec.TLS = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationReencrypt,
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+	default:
 		desired.Spec.TLS = nil
 	}
 
+	desired.Annotations = nil
+	if len(exposure.Annotations) > 0 {
+		annotations := map[string]string{}
+		for key, value := range exposure.Annotations {
+			annotations[key] = value
+		}
+		desired.Annotations = annotations
+	}
+
 	return desired
 }
@@ -1,7 +1,13 @@
 package artemis
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/jolokia"
@@ -13,15 +19,15 @@ const (
 	UNKNOWN_ERROR          = "AMQ_UNKNOWN"
 )
 
+// GetCreationError returns the message code of a creation that failed because the resource exists,
+// jolokia.KindOf classifies any failed request
 func GetCreationError(jdata *jolokia.ResponseData) string {
 	if jdata == nil {
 		return UNKNOWN_ERROR
 	}
-	if strings.Contains(jdata.Error, QUEUE_ALREADY_EXISTS) {
-		return QUEUE_ALREADY_EXISTS
-	}
-	if strings.Contains(jdata.Error, ADDRESS_ALREADY_EXISTS) {
-		return ADDRESS_ALREADY_EXISTS
+	switch code := jolokia.NewManagementError(jdata).Code; code {
+	case QUEUE_ALREADY_EXISTS, ADDRESS_ALREADY_EXISTS:
+		return code
 	}
 	return UNKNOWN_ERROR
 }
@@ -51,12 +57,16 @@ func NewArtemis(_ip string, _jolokiaPort string, _name string, _user string, _pa
 }
 
 func GetArtemis(_ip string, _jolokiaPort string, _name string, _user string, _password string, _protocol string) *Artemis {
+	return GetArtemisWithTLSConfig(_ip, _jolokiaPort, _name, _user, _password, _protocol, nil)
+}
+
+func GetArtemisWithTLSConfig(_ip string, _jolokiaPort string, _name string, _user string, _password string, _protocol string, _tlsConfig *tls.Config) *Artemis {
 
 	artemis := Artemis{
 		ip:          _ip,
 		jolokiaPort: _jolokiaPort,
 		name:        _name,
-		jolokia:     jolokia.GetJolokia(_ip, _jolokiaPort, "/console/jolokia", _user, _password, _protocol),
+		jolokia:     jolokia.GetJolokiaWithTLSConfig(_ip, _jolokiaPort, "/console/jolokia", _user, _password, _protocol, _tlsConfig),
 	}
 
 	return &artemis
@@ -160,3 +170,429 @@ func (artemis *Artemis) DeleteAddress(addressName string) (*jolokia.ResponseData
 
 	return data, err
 }
+
+type TopologyMember struct {
+	NodeID string `json:"nodeID"`
+	Live   string `json:"live"`
+	Backup string `json:"backup"`
+}
+
+type BrokerInfo struct {
+	Uptime            string   `json:"Uptime"`
+	UptimeMillis      int64    `json:"UptimeMillis"`
+	Backup            bool     `json:"Backup"`
+	Active            bool     `json:"Active"`
+	NodeID            string   `json:"NodeID"`
+	AddressNames      []string `json:"AddressNames"`
+	QueueNames        []string `json:"QueueNames"`
+	DivertNames       []string `json:"DivertNames"`
+	TotalMessageCount int64    `json:"TotalMessageCount"`
+	DiskStoreUsage    float64  `json:"DiskStoreUsage"`
+
+	Topology        []TopologyMember `json:"-"`
+	PagingAddresses []string         `json:"-"`
+}
+
+var brokerInfoAttributes = []string{
+	"Uptime",
+	"UptimeMillis",
+	"Backup",
+	"Active",
+	"NodeID",
+	"AddressNames",
+	"QueueNames",
+	"DivertNames",
+	"TotalMessageCount",
+	"DiskStoreUsage",
+}
+
+// GetBrokerInfo gathers the runtime state of the broker, its view of the
+// cluster topology and the addresses that are currently paging in one round trip
+func (artemis *Artemis) GetBrokerInfo(ctx context.Context) (*BrokerInfo, error) {
+	mbean := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\""
+	responses, err := artemis.jolokia.Bulk(ctx, []jolokia.Request{
+		jolokia.NewReadRequest(mbean, brokerInfoAttributes...),
+		jolokia.NewExecRequest(mbean, "listNetworkTopology()"),
+		jolokia.NewReadRequest(mbean+",component=addresses,address=*", "Paging"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, topology, paging := responses[0], responses[1], responses[2]
+	if err = checkBulkResponse(attributes); err != nil {
+		return nil, err
+	}
+
+	info := &BrokerInfo{}
+	if err = json.Unmarshal([]byte(attributes.Value), info); err != nil {
+		return nil, err
+	}
+
+	if err = checkBulkResponse(topology); err != nil {
+		return nil, err
+	}
+	if info.Topology, err = parseNetworkTopology(topology.Value); err != nil {
+		return nil, err
+	}
+
+	if paging.Status != 404 {
+		// a 404 means no address mbean matches the pattern
+		if err = checkBulkResponse(paging); err != nil {
+			return nil, err
+		}
+		if info.PagingAddresses, err = parsePagingAddresses(paging.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
+
+func checkBulkResponse(resp *jolokia.ResponseData) error {
+	if resp.Status >= 200 && resp.Status <= 299 {
+		return nil
+	}
+	return jolokia.NewManagementError(resp)
+}
+
+func (artemis *Artemis) ListNetworkTopology() ([]TopologyMember, error) {
+	url := "org.apache.activemq.artemis:broker=\\\"" + artemis.name + "\\\""
+	jsonStr := `{ "type":"EXEC","mbean":"` + url + `","operation":"listNetworkTopology()","arguments":[] }`
+	resp, err := artemis.jolokia.Exec(url, jsonStr)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
+	}
+	return parseNetworkTopology(resp.Value)
+}
+
+func parseNetworkTopology(value string) ([]TopologyMember, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	members := []TopologyMember{}
+	if err := json.Unmarshal([]byte(value), &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (artemis *Artemis) ListPagingAddresses() ([]string, error) {
+	url := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\",component=addresses,address=*/Paging"
+	resp, err := artemis.jolokia.Read(url)
+	if resp != nil && resp.Status == 404 {
+		// no address mbean matches the pattern
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
+	}
+	return parsePagingAddresses(resp.Value)
+}
+
+func parsePagingAddresses(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	pagingByMBean := map[string]map[string]bool{}
+	if err := json.Unmarshal([]byte(value), &pagingByMBean); err != nil {
+		return nil, err
+	}
+
+	var paging []string
+	for mbean, attributes := range pagingByMBean {
+		if attributes["Paging"] {
+			paging = append(paging, addressFromMBeanName(mbean))
+		}
+	}
+	sort.Strings(paging)
+	return paging, nil
+}
+
+func addressFromMBeanName(mbean string) string {
+	properties := mbean
+	if domainEnd := strings.Index(mbean, ":"); domainEnd >= 0 {
+		properties = mbean[domainEnd+1:]
+	}
+	for _, property := range strings.Split(properties, ",") {
+		if strings.HasPrefix(property, "address=") {
+			return strings.Trim(strings.TrimPrefix(property, "address="), "\"")
+		}
+	}
+	return mbean
+}
+
+type QueueInfo struct {
+	Address                 string `json:"Address"`
+	RoutingType             string `json:"RoutingType"`
+	Filter                  string `json:"Filter"`
+	Durable                 bool   `json:"Durable"`
+	MaxConsumers            int32  `json:"MaxConsumers"`
+	Exclusive               bool   `json:"Exclusive"`
+	PurgeOnNoConsumers      bool   `json:"PurgeOnNoConsumers"`
+	GroupRebalance          bool   `json:"GroupRebalance"`
+	GroupBuckets            int32  `json:"GroupBuckets"`
+	ConsumersBeforeDispatch int32  `json:"ConsumersBeforeDispatch"`
+	DelayBeforeDispatch     int64  `json:"DelayBeforeDispatch"`
+}
+
+var queueInfoAttributes = []string{
+	"Address",
+	"RoutingType",
+	"Filter",
+	"Durable",
+	"MaxConsumers",
+	"Exclusive",
+	"PurgeOnNoConsumers",
+	"GroupRebalance",
+	"GroupBuckets",
+	"ConsumersBeforeDispatch",
+	"DelayBeforeDispatch",
+}
+
+// AddressExists checks whether the address is deployed on the broker
+func (artemis *Artemis) AddressExists(addressName string) (bool, error) {
+	url := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\",component=addresses,address=\"" + addressName + "\"/RoutingTypes"
+	resp, err := artemis.jolokia.Read(url)
+	if resp != nil && resp.Status == 404 {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetQueueInfo reads the configuration of a deployed queue, a nil QueueInfo
+// is returned when the queue does not exist
+func (artemis *Artemis) GetQueueInfo(addressName string, queueName string, routingType string) (*QueueInfo, error) {
+	url := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\",component=addresses,address=\"" + addressName +
+		"\",subcomponent=queues,routing-type=\"" + strings.ToLower(routingType) + "\",queue=\"" + queueName + "\"/" + strings.Join(queueInfoAttributes, ",")
+	resp, err := artemis.jolokia.Read(url)
+	if resp != nil && resp.Status == 404 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("unable to retrieve queue info, no response")
+	}
+
+	info := &QueueInfo{}
+	if err = json.Unmarshal([]byte(resp.Value), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// IsQueueBound checks the output of ListBindingsForAddress for a binding of the queue
+func IsQueueBound(bindings string, queueName string) bool {
+	return regexp.MustCompile(`[\[\s,]name=` + regexp.QuoteMeta(queueName) + `[,\]]`).MatchString(bindings)
+}
+
+func (artemis *Artemis) brokerMBean() string {
+	return "org.apache.activemq.artemis:broker=\"" + artemis.name + "\""
+}
+
+func (artemis *Artemis) queueMBean(addressName string, queueName string, routingType string) string {
+	return artemis.brokerMBean() + ",component=addresses,address=\"" + addressName +
+		"\",subcomponent=queues,routing-type=\"" + strings.ToLower(routingType) + "\",queue=\"" + queueName + "\""
+}
+
+// execOperation invokes the operation with its arguments encoded as json, so names
+// that need escaping are passed through unchanged
+func (artemis *Artemis) execOperation(mbean string, operation string, arguments ...interface{}) (*jolokia.ResponseData, error) {
+	body, err := json.Marshal(jolokia.NewExecRequest(mbean, operation, arguments...))
+	if err != nil {
+		return nil, err
+	}
+	return artemis.jolokia.Exec(mbean, string(body))
+}
+
+// execForCount invokes an operation that returns the number of messages it affected
+func (artemis *Artemis) execForCount(mbean string, operation string, arguments ...interface{}) (int64, error) {
+	resp, err := artemis.execOperation(mbean, operation, arguments...)
+	if err != nil {
+		return 0, err
+	}
+	if resp == nil {
+		return 0, fmt.Errorf("unable to invoke %s, no response", operation)
+	}
+	return strconv.ParseInt(resp.Value, 10, 64)
+}
+
+// execForJSON invokes an operation that returns a json document and decodes it into result
+func (artemis *Artemis) execForJSON(mbean string, operation string, result interface{}, arguments ...interface{}) error {
+	resp, err := artemis.execOperation(mbean, operation, arguments...)
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return fmt.Errorf("unable to invoke %s, no response", operation)
+	}
+	if resp.Value == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(resp.Value), result)
+}
+
+// PauseQueue stops the delivery of the messages of a queue to its consumers
+func (artemis *Artemis) PauseQueue(addressName string, queueName string, routingType string) error {
+	_, err := artemis.execOperation(artemis.queueMBean(addressName, queueName, routingType), "pause()")
+	return err
+}
+
+// ResumeQueue resumes the delivery of the messages of a paused queue
+func (artemis *Artemis) ResumeQueue(addressName string, queueName string, routingType string) error {
+	_, err := artemis.execOperation(artemis.queueMBean(addressName, queueName, routingType), "resume()")
+	return err
+}
+
+// PurgeQueue removes all the messages of a queue and returns the number of removed messages
+func (artemis *Artemis) PurgeQueue(addressName string, queueName string, routingType string) (int64, error) {
+	return artemis.execForCount(artemis.queueMBean(addressName, queueName, routingType), "removeAllMessages()")
+}
+
+// MoveMessages moves all the messages of a queue to another queue and returns the number of moved messages
+func (artemis *Artemis) MoveMessages(addressName string, queueName string, routingType string, otherQueueName string) (int64, error) {
+	return artemis.MoveFilteredMessages(addressName, queueName, routingType, "", otherQueueName)
+}
+
+// MoveFilteredMessages moves the messages of a queue that match the filter to another queue,
+// an empty filter matches all the messages
+func (artemis *Artemis) MoveFilteredMessages(addressName string, queueName string, routingType string, filter string, otherQueueName string) (int64, error) {
+	var filterArgument interface{}
+	if filter != "" {
+		filterArgument = filter
+	}
+	return artemis.execForCount(artemis.queueMBean(addressName, queueName, routingType), "moveMessages(java.lang.String,java.lang.String)", filterArgument, otherQueueName)
+}
+
+type ConsumerInfo struct {
+	ConsumerID      int64  `json:"consumerID"`
+	ConnectionID    string `json:"connectionID"`
+	SessionID       string `json:"sessionID"`
+	QueueName       string `json:"queueName"`
+	BrowseOnly      bool   `json:"browseOnly"`
+	CreationTime    int64  `json:"creationTime"`
+	DeliveringCount int32  `json:"deliveringCount"`
+}
+
+// ListConsumers lists the consumers of all the queues of the broker
+func (artemis *Artemis) ListConsumers() ([]ConsumerInfo, error) {
+	consumers := []ConsumerInfo{}
+	if err := artemis.execForJSON(artemis.brokerMBean(), "listAllConsumersAsJSON()", &consumers); err != nil {
+		return nil, err
+	}
+	return consumers, nil
+}
+
+type ConnectionInfo struct {
+	ConnectionID   string `json:"connectionID"`
+	ClientAddress  string `json:"clientAddress"`
+	CreationTime   int64  `json:"creationTime"`
+	Implementation string `json:"implementation"`
+	SessionCount   int32  `json:"sessionCount"`
+}
+
+// ListConnections lists the client connections of the broker
+func (artemis *Artemis) ListConnections() ([]ConnectionInfo, error) {
+	connections := []ConnectionInfo{}
+	if err := artemis.execForJSON(artemis.brokerMBean(), "listConnectionsAsJSON()", &connections); err != nil {
+		return nil, err
+	}
+	return connections, nil
+}
+
+type SessionInfo struct {
+	SessionID     string `json:"sessionID"`
+	CreationTime  int64  `json:"creationTime"`
+	ConsumerCount int32  `json:"consumerCount"`
+	Principal     string `json:"principal"`
+}
+
+// ListSessions lists the sessions of a client connection
+func (artemis *Artemis) ListSessions(connectionID string) ([]SessionInfo, error) {
+	sessions := []SessionInfo{}
+	if err := artemis.execForJSON(artemis.brokerMBean(), "listSessionsAsJSON(java.lang.String)", &sessions, connectionID); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// CloseConnectionsForUser closes the client connections authenticated as the user,
+// it returns whether any connection was closed
+func (artemis *Artemis) CloseConnectionsForUser(userName string) (bool, error) {
+	resp, err := artemis.execOperation(artemis.brokerMBean(), "closeConnectionsForUser(java.lang.String)", userName)
+	if err != nil {
+		return false, err
+	}
+	if resp == nil {
+		return false, fmt.Errorf("unable to close the connections of %s, no response", userName)
+	}
+	return strconv.ParseBool(resp.Value)
+}
+
+// ReloadAcceptor re-creates an acceptor with its configuration, an ssl acceptor reloads its key
+// and trust stores, the established connections are kept
+func (artemis *Artemis) ReloadAcceptor(acceptorName string) error {
+	_, err := artemis.execOperation(artemis.brokerMBean()+",component=acceptors,name=\""+acceptorName+"\"", "reload()")
+	return err
+}
+
+type QueueMetrics struct {
+	MessageCount         int64 `json:"MessageCount"`
+	DurableMessageCount  int64 `json:"DurableMessageCount"`
+	ScheduledCount       int64 `json:"ScheduledCount"`
+	DeliveringCount      int32 `json:"DeliveringCount"`
+	ConsumerCount        int32 `json:"ConsumerCount"`
+	MessagesAdded        int64 `json:"MessagesAdded"`
+	MessagesAcknowledged int64 `json:"MessagesAcknowledged"`
+	MessagesExpired      int64 `json:"MessagesExpired"`
+	MessagesKilled       int64 `json:"MessagesKilled"`
+	Paused               bool  `json:"Paused"`
+}
+
+var queueMetricsAttributes = []string{
+	"MessageCount",
+	"DurableMessageCount",
+	"ScheduledCount",
+	"DeliveringCount",
+	"ConsumerCount",
+	"MessagesAdded",
+	"MessagesAcknowledged",
+	"MessagesExpired",
+	"MessagesKilled",
+	"Paused",
+}
+
+// GetQueueMetrics reads the message counters of a deployed queue, a nil QueueMetrics
+// is returned when the queue does not exist
+func (artemis *Artemis) GetQueueMetrics(addressName string, queueName string, routingType string) (*QueueMetrics, error) {
+	url := artemis.queueMBean(addressName, queueName, routingType) + "/" + strings.Join(queueMetricsAttributes, ",")
+	resp, err := artemis.jolokia.Read(url)
+	if resp != nil && resp.Status == 404 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("unable to retrieve queue metrics, no response")
+	}
+
+	metrics := &QueueMetrics{}
+	if err = json.Unmarshal([]byte(resp.Value), metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
@@ -1,6 +1,8 @@
 package artemis
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -109,6 +111,190 @@ func TestGetStatusWithNilStatus(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestGetBrokerInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Bulk(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, requests []jolokia.Request) ([]*jolokia.ResponseData, error) {
+			assert.Equal(t, 3, len(requests))
+			assert.Equal(t, "read", requests[0].Type)
+			assert.Equal(t, "org.apache.activemq.artemis:broker=\"someBroker\"", requests[0].MBean)
+			assert.Contains(t, requests[0].Attribute, "DiskStoreUsage")
+			assert.Equal(t, "exec", requests[1].Type)
+			assert.Equal(t, "listNetworkTopology()", requests[1].Operation)
+			assert.Equal(t, "org.apache.activemq.artemis:broker=\"someBroker\",component=addresses,address=*", requests[2].MBean)
+			return []*jolokia.ResponseData{
+				{
+					Status: 200,
+					Value:  `{"Uptime":"1 hour","UptimeMillis":3600000,"Backup":false,"Active":true,"NodeID":"abc","AddressNames":["DLQ","ExpiryQueue","orders"],"QueueNames":["DLQ","ExpiryQueue"],"DivertNames":["orders-audit"],"TotalMessageCount":1234567,"DiskStoreUsage":0.25}`,
+				},
+				{
+					Status: 200,
+					Value:  `[{"nodeID":"abc","live":"10.0.0.1:61616"},{"nodeID":"def","live":"10.0.0.2:61616"}]`,
+				},
+				{
+					Status: 200,
+					Value:  `{"org.apache.activemq.artemis:address=\"orders\",broker=\"someBroker\",component=addresses":{"Paging":true},"org.apache.activemq.artemis:address=\"DLQ\",broker=\"someBroker\",component=addresses":{"Paging":false}}`,
+				},
+			}, nil
+		}).
+		Times(1)
+
+	info, err := artemis.GetBrokerInfo(context.TODO())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1 hour", info.Uptime)
+	assert.False(t, info.Backup)
+	assert.Equal(t, 3, len(info.AddressNames))
+	assert.Equal(t, []string{"orders-audit"}, info.DivertNames)
+	assert.Equal(t, int64(1234567), info.TotalMessageCount)
+	assert.Equal(t, 0.25, info.DiskStoreUsage)
+	assert.Equal(t, 2, len(info.Topology))
+	assert.Equal(t, "10.0.0.2:61616", info.Topology[1].Live)
+	assert.Equal(t, []string{"orders"}, info.PagingAddresses)
+}
+
+func TestGetBrokerInfoWithNoAddressesAndFailedAttributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	attributes := &jolokia.ResponseData{
+		Status: 200,
+		Value:  `{"Uptime":"1 hour","Active":true}`,
+	}
+	j.
+		EXPECT().
+		Bulk(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ []jolokia.Request) ([]*jolokia.ResponseData, error) {
+			return []*jolokia.ResponseData{
+				attributes,
+				{Status: 200, Value: `[]`},
+				{
+					Status:    404,
+					ErrorType: "javax.management.InstanceNotFoundException",
+					Error:     "javax.management.InstanceNotFoundException : No MBean found for pattern",
+				},
+			}, nil
+		}).
+		Times(2)
+
+	info, err := artemis.GetBrokerInfo(context.TODO())
+
+	assert.Nil(t, err)
+	assert.True(t, info.Active)
+	assert.Empty(t, info.PagingAddresses)
+
+	attributes.Status = 403
+	attributes.Error = "java.lang.SecurityException : Forbidden"
+
+	info, err = artemis.GetBrokerInfo(context.TODO())
+
+	assert.Nil(t, info)
+	assert.NotNil(t, err)
+}
+
+func TestListPagingAddressesWithNoAddresses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Read(gomock.Any()).
+		DoAndReturn(func(_ string) (*jolokia.ResponseData, error) {
+			return &jolokia.ResponseData{
+				Status:    404,
+				ErrorType: "javax.management.InstanceNotFoundException",
+				Error:     "javax.management.InstanceNotFoundException : No MBean found for pattern",
+			}, fmt.Errorf("javax.management.InstanceNotFoundException")
+		}).
+		AnyTimes()
+
+	paging, err := artemis.ListPagingAddresses()
+
+	assert.Nil(t, err)
+	assert.Empty(t, paging)
+}
+
+func TestGetQueueInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Read(gomock.Any()).
+		DoAndReturn(func(path string) (*jolokia.ResponseData, error) {
+			assert.Contains(t, path, `address="orders",subcomponent=queues,routing-type="anycast",queue="orders"`)
+			return &jolokia.ResponseData{
+				Status: 200,
+				Value:  `{"Address":"orders","RoutingType":"ANYCAST","Filter":null,"Durable":true,"MaxConsumers":-1,"Exclusive":false,"PurgeOnNoConsumers":false,"GroupRebalance":false,"GroupBuckets":-1,"ConsumersBeforeDispatch":0,"DelayBeforeDispatch":-1}`,
+			}, nil
+		}).
+		Times(1)
+
+	info, err := artemis.GetQueueInfo("orders", "orders", "ANYCAST")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ANYCAST", info.RoutingType)
+	assert.Equal(t, "", info.Filter)
+	assert.True(t, info.Durable)
+	assert.Equal(t, int32(-1), info.MaxConsumers)
+}
+
+func TestGetQueueInfoWithMissingQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Read(gomock.Any()).
+		DoAndReturn(func(_ string) (*jolokia.ResponseData, error) {
+			return &jolokia.ResponseData{
+				Status:    404,
+				ErrorType: "javax.management.InstanceNotFoundException",
+			}, fmt.Errorf("javax.management.InstanceNotFoundException")
+		}).
+		Times(2)
+
+	info, err := artemis.GetQueueInfo("orders", "orders", "ANYCAST")
+	assert.Nil(t, err)
+	assert.Nil(t, info)
+
+	exists, err := artemis.AddressExists("orders")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+func TestIsQueueBound(t *testing.T) {
+	bindings := "LocalQueueBinding [address=orders, queue=QueueImpl[name=orders.eu, postOffice=PostOfficeImpl], filter=null, name=orders.eu, clusterName=orders.eu0c3f]"
+
+	assert.True(t, IsQueueBound(bindings, "orders.eu"))
+	assert.False(t, IsQueueBound(bindings, "orders"))
+	assert.False(t, IsQueueBound("", "orders"))
+}
+
 func createMockArtemis(j jolokia.IJolokia) Artemis {
 	return Artemis{
 		ip:          "0.0.0.0",
@@ -117,3 +303,157 @@ func createMockArtemis(j jolokia.IJolokia) Artemis {
 		jolokia:     j,
 	}
 }
+
+func expectExec(t *testing.T, j *jolokia.MockIJolokia, operation string, arguments []interface{}, value string) {
+	j.
+		EXPECT().
+		Exec(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, body string) (*jolokia.ResponseData, error) {
+			request := jolokia.Request{}
+			assert.Nil(t, json.Unmarshal([]byte(body), &request))
+			assert.Equal(t, "exec", request.Type)
+			assert.Equal(t, operation, request.Operation)
+			assert.Equal(t, arguments, request.Arguments)
+			return &jolokia.ResponseData{
+				Status: 200,
+				Value:  value,
+			}, nil
+		}).
+		Times(1)
+}
+
+func TestPauseAndResumeQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	expectExec(t, j, "pause()", nil, "")
+	expectExec(t, j, "resume()", nil, "")
+
+	assert.Nil(t, artemis.PauseQueue("orders", "orders", "ANYCAST"))
+	assert.Nil(t, artemis.ResumeQueue("orders", "orders", "ANYCAST"))
+}
+
+func TestPurgeAndMoveMessages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	expectExec(t, j, "removeAllMessages()", nil, "12")
+	expectExec(t, j, "moveMessages(java.lang.String,java.lang.String)", []interface{}{nil, "orders-v2"}, "7")
+	expectExec(t, j, "moveMessages(java.lang.String,java.lang.String)", []interface{}{"priority > 4", "urgent \"orders\""}, "3")
+
+	purged, err := artemis.PurgeQueue("orders", "orders", "ANYCAST")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12), purged)
+
+	moved, err := artemis.MoveMessages("orders", "orders", "ANYCAST", "orders-v2")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), moved)
+
+	moved, err = artemis.MoveFilteredMessages("orders", "orders", "ANYCAST", "priority > 4", "urgent \"orders\"")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), moved)
+}
+
+func TestListConsumersConnectionsAndSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	expectExec(t, j, "listAllConsumersAsJSON()", nil, `[{"consumerID":0,"connectionID":"c1","sessionID":"s1","queueName":"orders","browseOnly":false,"creationTime":1660000000000,"deliveringCount":2}]`)
+	expectExec(t, j, "listConnectionsAsJSON()", nil, `[{"connectionID":"c1","clientAddress":"/10.0.0.5:41234","creationTime":1660000000000,"implementation":"RemotingConnectionImpl","sessionCount":1}]`)
+	expectExec(t, j, "listSessionsAsJSON(java.lang.String)", []interface{}{"c1"}, `[{"sessionID":"s1","creationTime":1660000000000,"consumerCount":1,"principal":"app"}]`)
+
+	consumers, err := artemis.ListConsumers()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(consumers))
+	assert.Equal(t, "orders", consumers[0].QueueName)
+	assert.Equal(t, int32(2), consumers[0].DeliveringCount)
+
+	connections, err := artemis.ListConnections()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(connections))
+	assert.Equal(t, "/10.0.0.5:41234", connections[0].ClientAddress)
+
+	sessions, err := artemis.ListSessions("c1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sessions))
+	assert.Equal(t, "app", sessions[0].Principal)
+}
+
+func TestCloseConnectionsForUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	expectExec(t, j, "closeConnectionsForUser(java.lang.String)", []interface{}{"app"}, "true")
+
+	closed, err := artemis.CloseConnectionsForUser("app")
+	assert.Nil(t, err)
+	assert.True(t, closed)
+}
+
+func TestReloadAcceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Exec(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(mbean string, body string) (*jolokia.ResponseData, error) {
+			assert.Equal(t, "org.apache.activemq.artemis:broker=\"someBroker\",component=acceptors,name=\"amqps\"", mbean)
+			request := jolokia.Request{}
+			assert.Nil(t, json.Unmarshal([]byte(body), &request))
+			assert.Equal(t, "reload()", request.Operation)
+			return &jolokia.ResponseData{Status: 200}, nil
+		}).
+		Times(1)
+
+	assert.Nil(t, artemis.ReloadAcceptor("amqps"))
+}
+
+func TestGetQueueMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Read(gomock.Any()).
+		DoAndReturn(func(path string) (*jolokia.ResponseData, error) {
+			assert.Contains(t, path, `address="orders",subcomponent=queues,routing-type="anycast",queue="orders"/MessageCount,`)
+			return &jolokia.ResponseData{
+				Status: 200,
+				Value:  `{"MessageCount":42,"DurableMessageCount":40,"ScheduledCount":0,"DeliveringCount":3,"ConsumerCount":2,"MessagesAdded":100,"MessagesAcknowledged":55,"MessagesExpired":1,"MessagesKilled":2,"Paused":true}`,
+			}, nil
+		}).
+		Times(1)
+
+	metrics, err := artemis.GetQueueMetrics("orders", "orders", "ANYCAST")
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), metrics.MessageCount)
+	assert.Equal(t, int32(2), metrics.ConsumerCount)
+	assert.Equal(t, int64(55), metrics.MessagesAcknowledged)
+	assert.True(t, metrics.Paused)
+}
@@ -53,6 +53,36 @@ func (b *AutoDetector) DetectOpenshift() error {
 	return nil
 }
 
+// DetectPrometheusOperator records whether the ServiceMonitor kind of the prometheus operator is installed
+func (b *AutoDetector) DetectPrometheusOperator() error {
+	exists, err := ResourceExists(b.dc, "monitoring.coreos.com/v1", ServiceMonitorKind)
+	if err != nil {
+		return err
+	}
+	GetStateManager().SetState(ServiceMonitorKind, exists)
+	return nil
+}
+
+// DetectGatewayAPI records whether the TLSRoute kind of the gateway api is installed
+func (b *AutoDetector) DetectGatewayAPI() error {
+	exists, err := ResourceExists(b.dc, "gateway.networking.k8s.io/v1alpha2", TLSRouteKind)
+	if err != nil {
+		return err
+	}
+	GetStateManager().SetState(TLSRouteKind, exists)
+	return nil
+}
+
+// DetectCertManager records whether the Certificate kind of cert-manager is installed
+func (b *AutoDetector) DetectCertManager() error {
+	exists, err := ResourceExists(b.dc, "cert-manager.io/v1", CertificateKind)
+	if err != nil {
+		return err
+	}
+	GetStateManager().SetState(CertificateKind, exists)
+	return nil
+}
+
 func ResourceExists(dc discovery.DiscoveryInterface, apiGroupVersion, kind string) (bool, error) {
 	_, apiLists, err := dc.ServerGroupsAndResources()
 	if err != nil {
@@ -17,7 +17,14 @@ import (
 const (
 	RouteKind              = "Route"
 	OpenShiftAPIServerKind = "OpenShiftAPIServer"
+	ServiceMonitorKind     = "ServiceMonitor"
+	TLSRouteKind           = "TLSRoute"
+	CertificateKind        = "Certificate"
 	DEFAULT_RESYNC_PERIOD  = 30 * time.Second
+	DEFAULT_CLUSTER_DOMAIN = "cluster.local"
+	// jolokia requests are expected to be quick, an unresponsive broker must not hold up a reconcile
+	DEFAULT_JOLOKIA_REQUEST_TIMEOUT = 2 * time.Second
+	DEFAULT_JOLOKIA_REQUEST_RETRIES = 1
 	// comments push this over the edge a little when dealing with white space
 	// as en env var it can be disabled by setting to "" or can be improved!
 	JaasConfigSyntaxMatchRegExDefault = `^(?:(\s*|(?://.*)|(?s:/\*.*\*/))*\S+\s*{(?:(\s*|(?://.*)|(?s:/\*.*\*/))*\S+\s+(?i:required|optional|sufficient|requisite)+(?:\s*\S+=\S+\s*)*\s*;)+(\s*|(?://.*)|(?s:/\*.*\*/))*}\s*;)+\s*\z`
@@ -29,6 +36,12 @@ var resyncPeriod time.Duration = DEFAULT_RESYNC_PERIOD
 
 var jaasConfigSyntaxMatchRegEx = JaasConfigSyntaxMatchRegExDefault
 
+var clusterDomain = DEFAULT_CLUSTER_DOMAIN
+
+var jolokiaRequestTimeout = DEFAULT_JOLOKIA_REQUEST_TIMEOUT
+
+var jolokiaRequestRetries = DEFAULT_JOLOKIA_REQUEST_RETRIES
+
 func init() {
 	if period, defined := os.LookupEnv("RECONCILE_RESYNC_PERIOD"); defined {
 		var err error
@@ -44,6 +57,24 @@ func init() {
 	} else {
 		jaasConfigSyntaxMatchRegEx = JaasConfigSyntaxMatchRegExDefault
 	}
+
+	if domain, defined := os.LookupEnv("CLUSTER_DOMAIN"); defined && domain != "" {
+		clusterDomain = domain
+	}
+
+	if timeout, defined := os.LookupEnv("JOLOKIA_REQUEST_TIMEOUT"); defined {
+		var err error
+		if jolokiaRequestTimeout, err = time.ParseDuration(timeout); err != nil {
+			jolokiaRequestTimeout = DEFAULT_JOLOKIA_REQUEST_TIMEOUT
+		}
+	}
+
+	if retries, defined := os.LookupEnv("JOLOKIA_REQUEST_RETRIES"); defined {
+		var err error
+		if jolokiaRequestRetries, err = strconv.Atoi(retries); err != nil || jolokiaRequestRetries < 0 {
+			jolokiaRequestRetries = DEFAULT_JOLOKIA_REQUEST_RETRIES
+		}
+	}
 }
 
 func GetJaasConfigSyntaxMatchRegEx() string {
@@ -54,6 +85,20 @@ func GetReconcileResyncPeriod() time.Duration {
 	return resyncPeriod
 }
 
+func GetJolokiaRequestTimeout() time.Duration {
+	return jolokiaRequestTimeout
+}
+
+// GetJolokiaRequestRetries is the number of times a jolokia read is retried when the broker cannot be reached
+func GetJolokiaRequestRetries() int {
+	return jolokiaRequestRetries
+}
+
+// GetClusterDomain is the dns domain of the services of the cluster
+func GetClusterDomain() string {
+	return clusterDomain
+}
+
 type ActiveMQArtemisConfigHandler interface {
 	IsApplicableFor(brokerNamespacedName types.NamespacedName) bool
 	Config(initContainers []corev1.Container, outputDirRoot string, yacfgProfileVersion string, yacfgProfileName string) (value []string)
@@ -0,0 +1,188 @@
+package jolokia
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrorKind classifies the failure of a jolokia request so that callers can decide
+// between retrying, reporting a permanent failure and carrying on
+type ErrorKind string
+
+const (
+	ErrorKindUnknown         ErrorKind = "Unknown"
+	ErrorKindAuthFailed      ErrorKind = "AuthFailed"
+	ErrorKindNotFound        ErrorKind = "NotFound"
+	ErrorKindAlreadyExists   ErrorKind = "AlreadyExists"
+	ErrorKindInvalidArgument ErrorKind = "InvalidArgument"
+	ErrorKindUnavailable     ErrorKind = "Unavailable"
+)
+
+// the exceptions the broker and the jolokia agent report, matched against the
+// error type and the causes of the stacktrace
+var errorKindsByException = []struct {
+	exception string
+	kind      ErrorKind
+}{
+	{"SecurityException", ErrorKindAuthFailed},
+	{"ActiveMQSecurityException", ErrorKindAuthFailed},
+	{"InstanceNotFoundException", ErrorKindNotFound},
+	{"AttributeNotFoundException", ErrorKindNotFound},
+	{"ActiveMQNonExistentQueueException", ErrorKindNotFound},
+	{"ActiveMQAddressDoesNotExistException", ErrorKindNotFound},
+	{"ActiveMQQueueExistsException", ErrorKindAlreadyExists},
+	{"ActiveMQAddressExistsException", ErrorKindAlreadyExists},
+	{"IllegalArgumentException", ErrorKindInvalidArgument},
+	{"ActiveMQInvalidQueueConfiguration", ErrorKindInvalidArgument},
+	{"ActiveMQInvalidTransientQueueUseException", ErrorKindInvalidArgument},
+	{"NumberFormatException", ErrorKindInvalidArgument},
+	{"ActiveMQAddressFullException", ErrorKindUnavailable},
+	{"ActiveMQNotConnectedException", ErrorKindUnavailable},
+	{"ActiveMQDisconnectedException", ErrorKindUnavailable},
+}
+
+// the message codes of the broker that identify the failure without the exception
+var errorKindsByCode = map[string]ErrorKind{
+	"AMQ229017": ErrorKindNotFound,
+	"AMQ229019": ErrorKindAlreadyExists,
+	"AMQ229031": ErrorKindAuthFailed,
+	"AMQ229032": ErrorKindAuthFailed,
+	"AMQ229203": ErrorKindNotFound,
+	"AMQ229204": ErrorKindAlreadyExists,
+}
+
+var messageCodeRegEx = regexp.MustCompile(`AMQ\d{6}`)
+
+// ManagementError is a failed request reported by the jolokia agent of a broker
+type ManagementError struct {
+	Kind      ErrorKind
+	Status    int
+	ErrorType string
+	// Code is the broker message code, like AMQ229019, when the broker reported one
+	Code    string
+	Message string
+	Value   string
+}
+
+// NewManagementError classifies the failure of a jolokia response from its status,
+// error type, message code and the causes in its stacktrace
+func NewManagementError(jdata *ResponseData) *ManagementError {
+	err := &ManagementError{
+		Kind:      ErrorKindUnknown,
+		Status:    jdata.Status,
+		ErrorType: jdata.ErrorType,
+		Code:      messageCodeRegEx.FindString(jdata.Error),
+		Message:   jdata.Error,
+		Value:     jdata.Value,
+	}
+
+	if kind, found := errorKindsByCode[err.Code]; found {
+		err.Kind = kind
+		return err
+	}
+
+	// the outer exception is often a wrapper like javax.management.MBeanException
+	// so the causes from the stacktrace are classified as well
+	for _, candidate := range append([]string{jdata.ErrorType, jdata.Error}, stacktraceCauses(jdata.Stacktrace)...) {
+		if kind, found := errorKindForException(candidate); found {
+			err.Kind = kind
+			return err
+		}
+	}
+
+	// the agent answers 500 for any exception of the operation, that status alone tells nothing
+	if jdata.Status != http.StatusInternalServerError {
+		err.Kind = errorKindForStatus(jdata.Status)
+	}
+	return err
+}
+
+func (e *ManagementError) Error() string {
+	return fmt.Sprintf("Error response code %v, type %v, message %v and data %v", e.Status, e.ErrorType, e.Message, e.Value)
+}
+
+func stacktraceCauses(stacktrace string) []string {
+	var causes []string
+	for _, line := range strings.Split(stacktrace, "\n") {
+		if cause := strings.TrimPrefix(strings.TrimSpace(line), "Caused by: "); cause != strings.TrimSpace(line) {
+			causes = append(causes, cause)
+		}
+	}
+	return causes
+}
+
+func errorKindForException(exception string) (ErrorKind, bool) {
+	for _, candidate := range errorKindsByException {
+		if strings.Contains(exception, "."+candidate.exception) || strings.HasPrefix(exception, candidate.exception) {
+			return candidate.kind, true
+		}
+	}
+	return ErrorKindUnknown, false
+}
+
+func errorKindForStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrorKindAuthFailed
+	case status == http.StatusNotFound:
+		return ErrorKindNotFound
+	case status == http.StatusBadRequest:
+		return ErrorKindInvalidArgument
+	case status >= http.StatusInternalServerError && status != http.StatusNotImplemented:
+		return ErrorKindUnavailable
+	}
+	return ErrorKindUnknown
+}
+
+// KindOf classifies the error returned by a jolokia request, errors that did not
+// come from a request are of the unknown kind
+func KindOf(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	var managementError *ManagementError
+	if errors.As(err, &managementError) {
+		return managementError.Kind
+	}
+	var jolokiaError *JolokiaError
+	if errors.As(err, &jolokiaError) {
+		return errorKindForStatus(jolokiaError.HttpCode)
+	}
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		// the broker could not be reached
+		return ErrorKindUnavailable
+	}
+	return ErrorKindUnknown
+}
+
+func IsAuthFailed(err error) bool {
+	return KindOf(err) == ErrorKindAuthFailed
+}
+
+func IsNotFound(err error) bool {
+	return KindOf(err) == ErrorKindNotFound
+}
+
+func IsAlreadyExists(err error) bool {
+	return KindOf(err) == ErrorKindAlreadyExists
+}
+
+func IsInvalidArgument(err error) bool {
+	return KindOf(err) == ErrorKindInvalidArgument
+}
+
+func IsUnavailable(err error) bool {
+	return KindOf(err) == ErrorKindUnavailable
+}
+
+// IsPermanent tells whether retrying the same request cannot succeed until the
+// credentials or the request change
+func IsPermanent(err error) bool {
+	kind := KindOf(err)
+	return kind == ErrorKindAuthFailed || kind == ErrorKindInvalidArgument
+}
@@ -0,0 +1,68 @@
+package jolokia
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewManagementError(t *testing.T) {
+	queueExists := NewManagementError(&ResponseData{
+		Status:    500,
+		ErrorType: "javax.management.MBeanException",
+		Error:     "javax.management.MBeanException : AMQ229019: Queue orders already exists on address orders",
+	})
+	assert.Equal(t, ErrorKindAlreadyExists, queueExists.Kind)
+	assert.Equal(t, "AMQ229019", queueExists.Code)
+
+	denied := NewManagementError(&ResponseData{
+		Status:     500,
+		ErrorType:  "javax.management.MBeanException",
+		Error:      "javax.management.MBeanException : null",
+		Stacktrace: "javax.management.MBeanException\n\tat com.sun.jmx.Invoker.invoke\nCaused by: org.apache.activemq.artemis.api.core.ActiveMQSecurityException: user does not have permission\n\t... 12 more",
+	})
+	assert.Equal(t, ErrorKindAuthFailed, denied.Kind)
+
+	notFound := NewManagementError(&ResponseData{
+		Status:    404,
+		ErrorType: "javax.management.InstanceNotFoundException",
+		Error:     "javax.management.InstanceNotFoundException : No MBean found for pattern",
+	})
+	assert.Equal(t, ErrorKindNotFound, notFound.Kind)
+
+	invalid := NewManagementError(&ResponseData{
+		Status:    500,
+		ErrorType: "java.lang.IllegalArgumentException",
+		Error:     "java.lang.IllegalArgumentException : No enum constant RoutingType.BROADCAST",
+	})
+	assert.Equal(t, ErrorKindInvalidArgument, invalid.Kind)
+
+	full := NewManagementError(&ResponseData{
+		Status:    500,
+		ErrorType: "org.apache.activemq.artemis.api.core.ActiveMQAddressFullException",
+		Error:     "AMQ229102: Address \"orders\" is full.",
+	})
+	assert.Equal(t, ErrorKindUnavailable, full.Kind)
+
+	unknown := NewManagementError(&ResponseData{
+		Status:    500,
+		ErrorType: "java.lang.NullPointerException",
+	})
+	assert.Equal(t, ErrorKindUnknown, unknown.Kind)
+}
+
+func TestKindOf(t *testing.T) {
+	assert.Equal(t, ErrorKind(""), KindOf(nil))
+	assert.Equal(t, ErrorKindUnknown, KindOf(errors.New("boom")))
+	assert.Equal(t, ErrorKindAuthFailed, KindOf(&JolokiaError{HttpCode: http.StatusUnauthorized}))
+	assert.Equal(t, ErrorKindUnavailable, KindOf(&JolokiaError{HttpCode: http.StatusServiceUnavailable}))
+	assert.Equal(t, ErrorKindAlreadyExists, KindOf(fmt.Errorf("wrapped %w", &ManagementError{Kind: ErrorKindAlreadyExists})))
+
+	assert.True(t, IsPermanent(&JolokiaError{HttpCode: http.StatusForbidden}))
+	assert.True(t, IsPermanent(&ManagementError{Kind: ErrorKindInvalidArgument}))
+	assert.False(t, IsPermanent(&ManagementError{Kind: ErrorKindNotFound}))
+	assert.False(t, IsPermanent(errors.New("boom")))
+}
@@ -2,12 +2,17 @@ package jolokia
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"net/url"
+	"strings"
 	"time"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
 )
 
 type IData interface {
@@ -15,10 +20,11 @@ type IData interface {
 }
 
 type ResponseData struct {
-	Status    int
-	Value     string
-	ErrorType string
-	Error     string
+	Status     int
+	Value      string
+	ErrorType  string
+	Error      string
+	Stacktrace string
 }
 
 type ReadRequest struct {
@@ -36,9 +42,28 @@ func (j *JolokiaError) Error() string {
 	return fmt.Sprintf("HTTP STATUS %v. Message: %v", j.HttpCode, j.Message)
 }
 
+// Request is one read or exec operation of a bulk request
+type Request struct {
+	Type      string        `json:"type"`
+	MBean     string        `json:"mbean"`
+	Attribute []string      `json:"attribute,omitempty"`
+	Operation string        `json:"operation,omitempty"`
+	Arguments []interface{} `json:"arguments,omitempty"`
+}
+
+func NewReadRequest(mbean string, attributes ...string) Request {
+	return Request{Type: "read", MBean: mbean, Attribute: attributes}
+}
+
+func NewExecRequest(mbean string, operation string, arguments ...interface{}) Request {
+	return Request{Type: "exec", MBean: mbean, Operation: operation, Arguments: arguments}
+}
+
 type IJolokia interface {
 	Read(path string) (*ResponseData, error)
 	Exec(path, postJsonString string) (*ResponseData, error)
+	// Bulk sends all the requests in one round trip, the responses are in the order of the requests
+	Bulk(ctx context.Context, requests []Request) ([]*ResponseData, error)
 }
 
 type Jolokia struct {
@@ -48,6 +73,18 @@ type Jolokia struct {
 	user       string
 	password   string
 	protocol   string
+	tlsConfig  *tls.Config
+	client     *http.Client
+}
+
+// the transport pools the connections to the brokers, a transport is only created for a tls config
+var plainTransport = newTransport(nil)
+
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 2
+	transport.TLSClientConfig = tlsConfig
+	return transport
 }
 
 func NewJolokia(_ip string, _port string, _path string, _user string, _password string) *Jolokia {
@@ -55,6 +92,12 @@ func NewJolokia(_ip string, _port string, _path string, _user string, _password
 }
 
 func GetJolokia(_ip string, _port string, _path string, _user string, _password string, _protocol string) *Jolokia {
+	return GetJolokiaWithTLSConfig(_ip, _port, _path, _user, _password, _protocol, nil)
+}
+
+// GetJolokiaWithTLSConfig verifies the broker with the given tls config over https, without
+// one the broker certificate is not verified
+func GetJolokiaWithTLSConfig(_ip string, _port string, _path string, _user string, _password string, _protocol string, _tlsConfig *tls.Config) *Jolokia {
 
 	j := Jolokia{
 		ip:         _ip,
@@ -63,125 +106,205 @@ func GetJolokia(_ip string, _port string, _path string, _user string, _password
 		user:       _user,
 		password:   _password,
 		protocol:   _protocol,
+		tlsConfig:  _tlsConfig,
 	}
-	if j.user == "" {
-		j.user = "admin"
-	}
-	if j.password == "" {
-		j.password = "admin"
-	} else {
-		//encode password in case it has special chars
-		j.password = url.QueryEscape(j.password)
-	}
+	j.client = j.newClient()
 
 	return &j
 }
 
-func (j *Jolokia) getClient() *http.Client {
+func (j *Jolokia) newClient() *http.Client {
+	transport := plainTransport
 	if j.protocol == "https" {
-		return &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-			},
-			Timeout: time.Second * 2, //Maximum of 2 seconds
+		tlsConfig := j.tlsConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{
+				InsecureSkipVerify: true,
+			}
 		}
+		transport = newTransport(tlsConfig)
 	}
 	return &http.Client{
-		Timeout: time.Second * 2, // Maximum of 2 seconds
+		Transport: transport,
+		Timeout:   common.GetJolokiaRequestTimeout(),
 	}
 }
 
 func (j *Jolokia) Read(_path string) (*ResponseData, error) {
+	return j.ReadWithContext(context.Background(), _path)
+}
 
-	url := j.protocol + "://" + j.user + ":" + j.password + "@" + j.jolokiaURL + "/read/" + _path
-
-	jolokiaClient := j.getClient()
-
-	var respError error = nil
-	var jdata *ResponseData = nil
-
-	for {
-		req, err := http.NewRequest(http.MethodGet, url, nil)
+// ReadWithContext reads with a GET request, retried when the broker cannot be reached
+func (j *Jolokia) ReadWithContext(ctx context.Context, _path string) (*ResponseData, error) {
+	var jdata *ResponseData
+	err := j.withRetries(ctx, func() error {
+		req, err := j.newRequest(ctx, http.MethodGet, "/read/"+_path, nil)
 		if err != nil {
-			break
+			return err
 		}
-		req.Header.Set("User-Agent", "activemq-artemis-management")
-
-		res, err := jolokiaClient.Do(req)
 
+		res, err := j.client.Do(req)
 		if err != nil {
-			respError = err
-			break
+			return &unreachableError{err}
 		}
 		defer res.Body.Close()
 
+		if err := checkNonJSONResponse(res); err != nil {
+			return err
+		}
+
 		//decoding
 		result, _, err := decodeResponseData(res)
 		if err != nil {
-			respError = err
-			return result, err
+			return err
 		}
-
 		jdata = result
 
 		//before decoding the body, we need to check the http code
-		err = CheckResponse(res, result)
+		return CheckResponse(res, result)
+	})
 
-		if err != nil {
-			respError = err
-		}
+	return jdata, err
+}
 
-		break
+func (j *Jolokia) Exec(_path string, _postJsonString string) (*ResponseData, error) {
+	return j.ExecWithContext(context.Background(), _path, _postJsonString)
+}
+
+// ExecWithContext posts the operation once, operations like queue creation are not idempotent
+func (j *Jolokia) ExecWithContext(ctx context.Context, _path string, _postJsonString string) (*ResponseData, error) {
+	req, err := j.newRequest(ctx, http.MethodPost, "/exec/"+_path, bytes.NewBuffer([]byte(_postJsonString)))
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	return jdata, respError
-}
+	res, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
 
-func (j *Jolokia) Exec(_path string, _postJsonString string) (*ResponseData, error) {
+	if err := checkNonJSONResponse(res); err != nil {
+		return nil, err
+	}
+
+	//decoding
+	result, _, err := decodeResponseData(res)
+	if err != nil {
+		return result, err
+	}
 
-	url := j.protocol + "://" + j.user + ":" + j.password + "@" + j.jolokiaURL + "/exec/" + _path
+	return result, CheckResponse(res, result)
+}
 
-	jolokiaClient := j.getClient()
+// Bulk posts all the requests in one round trip, the error of each request is in its response.
+// The requests are retried when the broker cannot be reached and they are all reads
+func (j *Jolokia) Bulk(ctx context.Context, requests []Request) ([]*ResponseData, error) {
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, err
+	}
 
-	var jdata *ResponseData
-	var execErr error = nil
-	for {
-		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer([]byte(_postJsonString)))
+	var responses []*ResponseData
+	send := func() error {
+		req, err := j.newRequest(ctx, http.MethodPost, "", bytes.NewBuffer(body))
 		if err != nil {
-			execErr = err
-			break
+			return err
 		}
-
-		req.Header.Set("User-Agent", "activemq-artemis-management")
 		req.Header.Set("Content-Type", "application/json")
-		res, err := jolokiaClient.Do(req)
 
+		res, err := j.client.Do(req)
 		if err != nil {
-			execErr = err
-			break
+			return &unreachableError{err}
 		}
-
 		defer res.Body.Close()
 
-		//decoding
-		result, _, err := decodeResponseData(res)
-		if err != nil {
-			return result, err
+		if !isResponseSuccessful(res.StatusCode) {
+			return &JolokiaError{
+				HttpCode: res.StatusCode,
+				Message:  " Error: " + res.Status,
+			}
 		}
 
-		jdata = result
+		rawResponses := []map[string]interface{}{}
+		if err := json.NewDecoder(res.Body).Decode(&rawResponses); err != nil {
+			return err
+		}
+		if len(rawResponses) != len(requests) {
+			return fmt.Errorf("expected %d responses to the bulk request, got %d", len(requests), len(rawResponses))
+		}
 
-		err = CheckResponse(res, result)
-		if err != nil {
-			execErr = err
+		responses = make([]*ResponseData, 0, len(rawResponses))
+		for _, rawData := range rawResponses {
+			responses = append(responses, newResponseData(rawData))
+		}
+		return nil
+	}
+
+	for _, request := range requests {
+		if !strings.EqualFold(request.Type, "read") {
+			return responses, send()
 		}
+	}
+	return responses, j.withRetries(ctx, send)
+}
+
+func (j *Jolokia) newRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, j.protocol+"://"+j.jolokiaURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(j.user, j.password)
+	req.Header.Set("User-Agent", "activemq-artemis-management")
+	return req, nil
+}
+
+type unreachableError struct {
+	cause error
+}
+
+func (e *unreachableError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *unreachableError) Unwrap() error {
+	return e.cause
+}
 
-		break
+// withRetries retries the call when the broker cannot be reached or is unavailable
+func (j *Jolokia) withRetries(ctx context.Context, call func() error) error {
+	err := call()
+	for attempt := 1; attempt <= common.GetJolokiaRequestRetries() && isRetriable(err); attempt++ {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * retryBackoff):
+		}
+		err = call()
+	}
+	var unreachable *unreachableError
+	if errors.As(err, &unreachable) {
+		return unreachable.cause
 	}
+	return err
+}
+
+var retryBackoff = 200 * time.Millisecond
 
-	return jdata, execErr
+func isRetriable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var unreachable *unreachableError
+	if errors.As(err, &unreachable) {
+		return true
+	}
+	var jolokiaError *JolokiaError
+	if errors.As(err, &jolokiaError) {
+		return jolokiaError.HttpCode == http.StatusBadGateway || jolokiaError.HttpCode == http.StatusServiceUnavailable || jolokiaError.HttpCode == http.StatusGatewayTimeout
+	}
+	return false
 }
 
 func CheckResponse(resp *http.Response, jdata *ResponseData) error {
@@ -191,12 +314,7 @@ func CheckResponse(resp *http.Response, jdata *ResponseData) error {
 		if isResponseSuccessful(jdata.Status) {
 			return nil
 		}
-		errCode := jdata.Status
-		errType := jdata.ErrorType
-		errMsg := jdata.Error
-		errData := jdata.Value
-		internalErr := fmt.Errorf("Error response code %v, type %v, message %v and data %v", errCode, errType, errMsg, errData)
-		return internalErr
+		return NewManagementError(jdata)
 	}
 	return &JolokiaError{
 		HttpCode: resp.StatusCode,
@@ -204,16 +322,32 @@ func CheckResponse(resp *http.Response, jdata *ResponseData) error {
 	}
 }
 
+// checkNonJSONResponse fails the responses that the console, a gateway or an
+// unavailable server do not answer with json
+func checkNonJSONResponse(res *http.Response) error {
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden || res.StatusCode >= http.StatusInternalServerError {
+		return &JolokiaError{
+			HttpCode: res.StatusCode,
+			Message:  " Error: " + res.Status,
+		}
+	}
+	return nil
+}
+
 func isResponseSuccessful(httpCode int) bool {
 	return httpCode >= 200 && httpCode <= 299
 }
 
 func decodeResponseData(resp *http.Response) (*ResponseData, map[string]interface{}, error) {
-	result := &ResponseData{}
 	rawData := make(map[string]interface{})
 	if err := json.NewDecoder(resp.Body).Decode(&rawData); err != nil {
 		return nil, rawData, err
 	}
+	return newResponseData(rawData), rawData, nil
+}
+
+func newResponseData(rawData map[string]interface{}) *ResponseData {
+	result := &ResponseData{}
 
 	//fill in response data
 	if v, ok := rawData["error"]; ok {
@@ -226,6 +360,11 @@ func decodeResponseData(resp *http.Response) (*ResponseData, map[string]interfac
 			result.ErrorType = fmt.Sprintf("%v", v)
 		}
 	}
+	if v, ok := rawData["stacktrace"]; ok {
+		if v != nil {
+			result.Stacktrace = fmt.Sprintf("%v", v)
+		}
+	}
 	if v, ok := rawData["status"]; ok {
 		if v != nil {
 			result.Status = int(v.(float64))
@@ -233,10 +372,16 @@ func decodeResponseData(resp *http.Response) (*ResponseData, map[string]interfac
 	}
 	if v, ok := rawData["value"]; ok {
 		if v != nil {
-			result.Value = fmt.Sprintf("%v", v)
+			if s, isString := v.(string); isString {
+				result.Value = s
+			} else if encoded, err := json.Marshal(v); err == nil {
+				// keep numbers, arrays and composite values parsable
+				result.Value = string(encoded)
+			} else {
+				result.Value = fmt.Sprintf("%v", v)
+			}
 		}
 	}
 
-	return result, rawData, nil
-
+	return result
 }
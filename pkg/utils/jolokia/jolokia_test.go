@@ -0,0 +1,120 @@
+package jolokia
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestJolokia(t *testing.T, handler http.HandlerFunc) *Jolokia {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	assert.Nil(t, err)
+	return GetJolokia(host, port, "/console/jolokia", "some-user", "p@ss&word", "http")
+}
+
+func TestBulk(t *testing.T) {
+	j := newTestJolokia(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/console/jolokia", r.URL.Path)
+
+		user, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "some-user", user)
+		assert.Equal(t, "p@ss&word", password)
+		assert.Empty(t, r.URL.RawQuery)
+
+		requests := []Request{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&requests))
+		assert.Equal(t, 2, len(requests))
+		assert.Equal(t, []string{"Version", "Uptime"}, requests[0].Attribute)
+		assert.Equal(t, "listNetworkTopology()", requests[1].Operation)
+
+		w.Write([]byte(`[{"status":200,"value":{"Version":"2.28.0","Uptime":"1 hour"}},{"status":404,"error_type":"javax.management.InstanceNotFoundException","error":"not found"}]`))
+	})
+
+	responses, err := j.Bulk(context.TODO(), []Request{
+		NewReadRequest(`org.apache.activemq.artemis:broker="amq-broker"`, "Version", "Uptime"),
+		NewExecRequest(`org.apache.activemq.artemis:broker="amq-broker"`, "listNetworkTopology()"),
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(responses))
+	assert.Equal(t, 200, responses[0].Status)
+	assert.JSONEq(t, `{"Version":"2.28.0","Uptime":"1 hour"}`, responses[0].Value)
+	assert.Equal(t, 404, responses[1].Status)
+	assert.Equal(t, "javax.management.InstanceNotFoundException", responses[1].ErrorType)
+}
+
+func TestBulkWithMissingResponses(t *testing.T) {
+	j := newTestJolokia(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"status":200,"value":"2.28.0"}]`))
+	})
+
+	_, err := j.Bulk(context.TODO(), []Request{
+		NewReadRequest(`org.apache.activemq.artemis:broker="amq-broker"`, "Version"),
+		NewReadRequest(`org.apache.activemq.artemis:broker="amq-broker"`, "Uptime"),
+	})
+
+	assert.NotNil(t, err)
+}
+
+func TestReadRetriesWhenUnavailable(t *testing.T) {
+	calls := 0
+	j := newTestJolokia(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":200,"value":"2.28.0"}`))
+	})
+
+	resp, err := j.Read(`org.apache.activemq.artemis:broker="amq-broker"/Version`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "2.28.0", resp.Value)
+}
+
+func TestExecIsNotRetried(t *testing.T) {
+	calls := 0
+	j := newTestJolokia(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, err := j.Exec(`org.apache.activemq.artemis:broker="amq-broker"`, `{"type":"EXEC","mbean":"org.apache.activemq.artemis:broker=\"amq-broker\"","operation":"listNetworkTopology()","arguments":[]}`)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestReadWithRejectedCredentials(t *testing.T) {
+	j := newTestJolokia(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("<html>Unauthorized</html>"))
+	})
+
+	_, err := j.Read(`org.apache.activemq.artemis:broker="amq-broker"/Version`)
+
+	assert.True(t, IsAuthFailed(err))
+}
+
+func TestUnreachableBroker(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	server.Close()
+
+	_, err := GetJolokia(host, port, "/console/jolokia", "", "", "http").Exec(`org.apache.activemq.artemis:broker="amq-broker"`, `{}`)
+
+	assert.True(t, IsUnavailable(err))
+}
@@ -5,6 +5,7 @@
 package jolokia
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -68,6 +69,21 @@ func (m *MockIJolokia) EXPECT() *MockIJolokiaMockRecorder {
 	return m.recorder
 }
 
+// Bulk mocks base method.
+func (m *MockIJolokia) Bulk(ctx context.Context, requests []Request) ([]*ResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bulk", ctx, requests)
+	ret0, _ := ret[0].([]*ResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Bulk indicates an expected call of Bulk.
+func (mr *MockIJolokiaMockRecorder) Bulk(ctx, requests interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bulk", reflect.TypeOf((*MockIJolokia)(nil).Bulk), ctx, requests)
+}
+
 // Exec mocks base method.
 func (m *MockIJolokia) Exec(path, postJsonString string) (*ResponseData, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,73 @@
+package jolokia_client
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mgmt "github.com/artemiscloud/activemq-artemis-operator/pkg/utils/artemis"
+)
+
+var _ = Describe("Jolokia client cache", func() {
+
+	podNamespacedName := types.NamespacedName{Name: "ex-aao-ss-0", Namespace: "some-ns"}
+
+	newPod := func(uid string, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: podNamespacedName.Name, Namespace: podNamespacedName.Namespace, UID: types.UID(uid)},
+			Status:     corev1.PodStatus{PodIP: ip},
+		}
+	}
+
+	It("should reuse the client of an unchanged pod", func() {
+		statefulset := &appsv1.StatefulSet{ObjectMeta: v1.ObjectMeta{Generation: 1}}
+		artemis := mgmt.GetArtemis("10.0.0.1", "8161", "amq-broker", "admin", "admin", "http")
+		cacheArtemis(podNamespacedName, newPod("a", "10.0.0.1"), statefulset, artemis)
+
+		Expect(getCachedArtemis(podNamespacedName, newPod("a", "10.0.0.1"), statefulset)).To(BeIdenticalTo(artemis))
+	})
+
+	It("should drop the client of a replaced pod", func() {
+		statefulset := &appsv1.StatefulSet{ObjectMeta: v1.ObjectMeta{Generation: 1}}
+		artemis := mgmt.GetArtemis("10.0.0.1", "8161", "amq-broker", "admin", "admin", "http")
+
+		cacheArtemis(podNamespacedName, newPod("a", "10.0.0.1"), statefulset, artemis)
+		Expect(getCachedArtemis(podNamespacedName, newPod("b", "10.0.0.1"), statefulset)).To(BeNil())
+
+		cacheArtemis(podNamespacedName, newPod("a", "10.0.0.1"), statefulset, artemis)
+		Expect(getCachedArtemis(podNamespacedName, newPod("a", "10.0.0.2"), statefulset)).To(BeNil())
+	})
+
+	It("should drop the client when the statefulset spec changes", func() {
+		artemis := mgmt.GetArtemis("10.0.0.1", "8161", "amq-broker", "admin", "admin", "http")
+		cacheArtemis(podNamespacedName, newPod("a", "10.0.0.1"), &appsv1.StatefulSet{ObjectMeta: v1.ObjectMeta{Generation: 1}}, artemis)
+
+		Expect(getCachedArtemis(podNamespacedName, newPod("a", "10.0.0.1"), &appsv1.StatefulSet{ObjectMeta: v1.ObjectMeta{Generation: 2}})).To(BeNil())
+	})
+
+	It("should evict the clients of the pods that are gone", func() {
+		statefulset := &appsv1.StatefulSet{ObjectMeta: v1.ObjectMeta{Generation: 1}}
+		artemis := mgmt.GetArtemis("10.0.0.1", "8161", "amq-broker", "admin", "admin", "http")
+		keys := []types.NamespacedName{
+			podNamespacedName,
+			{Name: "ex-aao-ss-1", Namespace: "some-ns"},
+			{Name: "ex-aao-ss-1", Namespace: "other-ns"},
+			{Name: "ex-aao-ss-other-0", Namespace: "some-ns"},
+		}
+		for _, key := range keys {
+			cacheArtemis(key, newPod("a", "10.0.0.1"), statefulset, artemis)
+		}
+
+		EvictBrokers(types.NamespacedName{Name: "ex-aao-ss", Namespace: "some-ns"}, map[string]bool{"ex-aao-ss-0": true})
+		Expect(artemisCache).To(HaveKey(keys[0]))
+		Expect(artemisCache).NotTo(HaveKey(keys[1]))
+		Expect(artemisCache).To(HaveKey(keys[2]))
+		Expect(artemisCache).To(HaveKey(keys[3]))
+
+		EvictBrokers(types.NamespacedName{Name: "ex-aao-ss", Namespace: "some-ns"}, nil)
+		Expect(artemisCache).NotTo(HaveKey(keys[0]))
+	})
+})
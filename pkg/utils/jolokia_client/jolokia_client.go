@@ -18,25 +18,103 @@ package jolokia_client
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
 	ss "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/statefulsets"
 	mgmt "github.com/artemiscloud/activemq-artemis-operator/pkg/utils/artemis"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/namer"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// TrustSecretCAKey is the key of the PEM CA bundle the operator trusts in a console secret
+const TrustSecretCAKey = "ca.crt"
+
+// the default keys of a management credentials secret
+const (
+	ManagementCredentialsUserKey     = "username"
+	ManagementCredentialsPasswordKey = "password"
+)
+
 type JkInfo struct {
 	Artemis *mgmt.Artemis
 	IP      string
 	Ordinal string
+	PodName string
+}
+
+// cachedArtemis is a management client of a pod, with its pooled connections, that is reused
+// until the pod is replaced, the statefulset spec changes or the reconcile resync period elapses
+type cachedArtemis struct {
+	artemis    *mgmt.Artemis
+	podUID     types.UID
+	podIP      string
+	generation int64
+	created    time.Time
+}
+
+var artemisCache = map[types.NamespacedName]*cachedArtemis{}
+var artemisCacheLock sync.Mutex
+
+func getCachedArtemis(podNamespacedName types.NamespacedName, pod *corev1.Pod, statefulset *appsv1.StatefulSet) *mgmt.Artemis {
+	artemisCacheLock.Lock()
+	defer artemisCacheLock.Unlock()
+
+	cached, found := artemisCache[podNamespacedName]
+	if !found {
+		return nil
+	}
+	if cached.podUID != pod.UID || cached.podIP != pod.Status.PodIP || cached.generation != statefulset.Generation ||
+		time.Since(cached.created) > common.GetReconcileResyncPeriod() {
+		delete(artemisCache, podNamespacedName)
+		return nil
+	}
+	return cached.artemis
+}
+
+func cacheArtemis(podNamespacedName types.NamespacedName, pod *corev1.Pod, statefulset *appsv1.StatefulSet, artemis *mgmt.Artemis) {
+	artemisCacheLock.Lock()
+	defer artemisCacheLock.Unlock()
+
+	artemisCache[podNamespacedName] = &cachedArtemis{
+		artemis:    artemis,
+		podUID:     pod.UID,
+		podIP:      pod.Status.PodIP,
+		generation: statefulset.Generation,
+		created:    time.Now(),
+	}
+}
+
+// EvictBrokers drops the cached clients of the pods of a statefulset but the kept ones, the
+// clients of the pods that are gone would otherwise stay cached as they are not looked up again
+func EvictBrokers(statefulset types.NamespacedName, kept map[string]bool) {
+	artemisCacheLock.Lock()
+	defer artemisCacheLock.Unlock()
+
+	for podNamespacedName := range artemisCache {
+		if podNamespacedName.Namespace != statefulset.Namespace || kept[podNamespacedName.Name] {
+			continue
+		}
+		ordinal := strings.TrimPrefix(podNamespacedName.Name, statefulset.Name+"-")
+		if _, err := strconv.Atoi(ordinal); err != nil || ordinal == podNamespacedName.Name {
+			continue
+		}
+		delete(artemisCache, podNamespacedName)
+	}
 }
 
 func GetBrokers(resource types.NamespacedName, ssInfos []ss.StatefulSetInfo, client rtclient.Client) []*JkInfo {
@@ -50,6 +128,9 @@ func GetBrokers(resource types.NamespacedName, ssInfos []ss.StatefulSetInfo, cli
 		if nil != err {
 			reqLogger.Error(err, "error retriving ss")
 			reqLogger.Info("Statefulset: " + info.NamespacedName.Name + " not found")
+			if errors.IsNotFound(err) {
+				EvictBrokers(info.NamespacedName, nil)
+			}
 		} else {
 			reqLogger.Info("Statefulset: " + info.NamespacedName.Name + " found")
 			pod := &corev1.Pod{}
@@ -58,6 +139,8 @@ func GetBrokers(resource types.NamespacedName, ssInfos []ss.StatefulSetInfo, cli
 				Namespace: resource.Namespace,
 			}
 
+			found := map[string]bool{}
+
 			// For each of the replicas
 			var i int = 0
 			var replicas int = int(*statefulset.Spec.Replicas)
@@ -74,21 +157,38 @@ func GetBrokers(resource types.NamespacedName, ssInfos []ss.StatefulSetInfo, cli
 					}
 				} else {
 					reqLogger.Info("Pod found", "Namespace", resource.Namespace, "Name", resource.Name)
-					containers := pod.Spec.Containers //get env from this
-					jolokiaSecretName := resource.Name + "-jolokia-secret"
+					found[s] = true
+					artemis := getCachedArtemis(podNamespacedName, pod, statefulset)
+					if artemis == nil {
+						jolokiaUser, jolokiaPassword, jolokiaProtocol, err := resolveJolokiaRequestParams(client, pod, podNamespacedName, statefulset, info.Labels)
+						if err != nil {
+							reqLogger.Error(err, "unable to resolve the management credentials, skipping pod", "Pod", s)
+							continue
+						}
 
-					jolokiaUser, jolokiaPassword, jolokiaProtocol := resolveJolokiaRequestParams(resource.Namespace, client, client.Scheme(), jolokiaSecretName, &containers, podNamespacedName, statefulset, info.Labels)
+						var tlsConfig *tls.Config
+						if jolokiaProtocol == "https" {
+							if tlsConfig, err = resolveJolokiaTLSConfig(client, statefulset, s); err != nil {
+								reqLogger.Error(err, "unable to configure the verification of the broker certificate, skipping pod", "Pod", s)
+								continue
+							}
+						}
 
-					reqLogger.Info("New Jolokia with ", "User: ", jolokiaUser, "Protocol: ", jolokiaProtocol, "broker ip", pod.Status.PodIP)
-					artemis := mgmt.GetArtemis(pod.Status.PodIP, "8161", "amq-broker", jolokiaUser, jolokiaPassword, jolokiaProtocol)
+						reqLogger.Info("New Jolokia with ", "User: ", jolokiaUser, "Protocol: ", jolokiaProtocol, "broker ip", pod.Status.PodIP, "verified", tlsConfig != nil)
+						artemis = mgmt.GetArtemisWithTLSConfig(pod.Status.PodIP, "8161", "amq-broker", jolokiaUser, jolokiaPassword, jolokiaProtocol, tlsConfig)
+						cacheArtemis(podNamespacedName, pod, statefulset, artemis)
+					}
 					jkInfo := JkInfo{
 						Artemis: artemis,
 						IP:      pod.Status.PodIP,
 						Ordinal: strconv.Itoa(i),
+						PodName: s,
 					}
 					artemisArray = append(artemisArray, &jkInfo)
 				}
 			}
+			// the clients of the pods that are scaled down or not found are dropped
+			EvictBrokers(types.NamespacedName{Name: statefulset.Name, Namespace: resource.Namespace}, found)
 		}
 	}
 
@@ -96,33 +196,94 @@ func GetBrokers(resource types.NamespacedName, ssInfos []ss.StatefulSetInfo, cli
 	return artemisArray
 }
 
-func resolveJolokiaRequestParams(namespace string,
-	client rtclient.Client,
-	scheme *runtime.Scheme,
-	jolokiaSecretName string,
-	containers *[]corev1.Container,
+// GetManagementCredentialsRef returns the secret and keys of the management credentials the CR
+// specifies, found is false when the CR leaves them to be resolved from the broker pods
+func GetManagementCredentialsRef(cr *brokerv1beta1.ActiveMQArtemis) (secretName string, userKey string, passwordKey string, found bool) {
+	credentials := cr.Spec.ManagementCredentials
+	if credentials == nil {
+		return "", "", "", false
+	}
+
+	secretName, userKey, passwordKey = credentials.SecretName, credentials.UserKey, credentials.PasswordKey
+	defaultUserKey, defaultPasswordKey := ManagementCredentialsUserKey, ManagementCredentialsPasswordKey
+	if secretName == "" {
+		// the admin credentials the operator generates for the brokers
+		secretName = namer.CrToCredentialsSecret(cr.Name)
+		defaultUserKey, defaultPasswordKey = "AMQ_USER", "AMQ_PASSWORD"
+	}
+	if userKey == "" {
+		userKey = defaultUserKey
+	}
+	if passwordKey == "" {
+		passwordKey = defaultPasswordKey
+	}
+	return secretName, userKey, passwordKey, true
+}
+
+// ResolveManagementCredentials reads the management user and password the CR specifies
+func ResolveManagementCredentials(client rtclient.Client, cr *brokerv1beta1.ActiveMQArtemis) (string, string, error) {
+	secretName, userKey, passwordKey, found := GetManagementCredentialsRef(cr)
+	if !found {
+		return "", "", fmt.Errorf("no management credentials specified for %s", cr.Name)
+	}
+
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: cr.Namespace}, secret); err != nil {
+		return "", "", err
+	}
+
+	user, password := string(secret.Data[userKey]), string(secret.Data[passwordKey])
+	if user == "" || password == "" {
+		return "", "", fmt.Errorf("management credentials secret %s has no %s or %s", secretName, userKey, passwordKey)
+	}
+	return user, password, nil
+}
+
+// resolveJolokiaRequestParams resolves the management credentials from the CR of the statefulset,
+// or failing that from its <cr>-jolokia-secret or the admin user of the broker pod
+func resolveJolokiaRequestParams(client rtclient.Client,
+	pod *corev1.Pod,
 	podNamespacedName types.NamespacedName,
 	statefulset *appsv1.StatefulSet,
-	labels map[string]string) (string, string, string) {
+	labels map[string]string) (string, string, string, error) {
 
 	var jolokiaUser string
 	var jolokiaPassword string
 	var jolokiaProtocol string
 
+	crName := namer.SSToCr(statefulset.Name)
 	userDefined := false
-	jolokiaUserFromSecret := secrets.GetValueFromSecret(namespace, jolokiaSecretName, "jolokiaUser", labels, client, scheme, nil)
-	if jolokiaUserFromSecret != nil {
-		userDefined = true
-		jolokiaUser = *jolokiaUserFromSecret
-	}
-	if userDefined {
-		jolokiaPasswordFromSecret := secrets.GetValueFromSecret(namespace, jolokiaSecretName, "jolokiaPassword", labels, client, scheme, nil)
-		if jolokiaPasswordFromSecret != nil {
-			jolokiaPassword = *jolokiaPasswordFromSecret
+	// the generated admin credentials are the ones the operator rotates
+	generated := true
+
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: crName, Namespace: statefulset.Namespace}, cr); err == nil {
+		if secretName, _, _, found := GetManagementCredentialsRef(cr); found {
+			if jolokiaUser, jolokiaPassword, err = ResolveManagementCredentials(client, cr); err != nil {
+				return "", "", "", err
+			}
+			userDefined = true
+			generated = secretName == namer.CrToCredentialsSecret(crName)
 		}
 	}
-	if len(*containers) == 1 {
-		envVars := (*containers)[0].Env
+
+	if !userDefined {
+		jolokiaSecretName := crName + "-jolokia-secret"
+		jolokiaUserFromSecret := secrets.GetValueFromSecret(statefulset.Namespace, jolokiaSecretName, "jolokiaUser", labels, client, client.Scheme(), nil)
+		if jolokiaUserFromSecret != nil {
+			userDefined = true
+			jolokiaUser = *jolokiaUserFromSecret
+		}
+		if userDefined {
+			generated = false
+			jolokiaPasswordFromSecret := secrets.GetValueFromSecret(statefulset.Namespace, jolokiaSecretName, "jolokiaPassword", labels, client, client.Scheme(), nil)
+			if jolokiaPasswordFromSecret != nil {
+				jolokiaPassword = *jolokiaPasswordFromSecret
+			}
+		}
+	}
+	if len(pod.Spec.Containers) == 1 {
+		envVars := pod.Spec.Containers[0].Env
 		for _, oneVar := range envVars {
 			if !userDefined && oneVar.Name == "AMQ_USER" {
 				jolokiaUser = getEnvVarValue(&oneVar, &podNamespacedName, statefulset, client, labels)
@@ -145,7 +306,100 @@ func resolveJolokiaRequestParams(namespace string,
 		jolokiaProtocol = "https"
 	}
 
-	return jolokiaUser, jolokiaPassword, jolokiaProtocol
+	if generated {
+		if previousUser, previousPassword, found := resolvePreviousCredentials(client, pod, statefulset.Namespace, crName); found {
+			jolokiaUser, jolokiaPassword = previousUser, previousPassword
+		}
+	}
+
+	if jolokiaUser == "" || jolokiaPassword == "" {
+		return "", "", "", fmt.Errorf("no management credentials found for pod %s, set .Spec.ManagementCredentials of %s", podNamespacedName.Name, crName)
+	}
+
+	return jolokiaUser, jolokiaPassword, jolokiaProtocol, nil
+}
+
+// resolvePreviousCredentials returns the admin credentials the operator rotated away from when the
+// broker of the pod started before the rotation, it keeps them until it is restarted by the roll
+func resolvePreviousCredentials(client rtclient.Client, pod *corev1.Pod, namespace string, crName string) (string, string, bool) {
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: namer.CrToPreviousCredentialsSecret(crName), Namespace: namespace}, secret); err != nil {
+		return "", "", false
+	}
+
+	// the env of a container is resolved when it starts, restarts included
+	startedAt := pod.CreationTimestamp
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil && status.State.Running.StartedAt.After(startedAt.Time) {
+			startedAt = status.State.Running.StartedAt
+		}
+	}
+	if startedAt.After(secret.CreationTimestamp.Time) {
+		return "", "", false
+	}
+
+	user, password := string(secret.Data["AMQ_USER"]), string(secret.Data["AMQ_PASSWORD"])
+	return user, password, user != "" && password != ""
+}
+
+// resolveJolokiaTLSConfig verifies the console certificate of the pod against the ca.crt of the
+// console trust secret, or of its ssl secret, and the dns name of the pod on the headless service.
+// Without a ca.crt the certificate is not verified, as with keystore only secrets
+func resolveJolokiaTLSConfig(client rtclient.Client, statefulset *appsv1.StatefulSet, podName string) (*tls.Config, error) {
+	reqLogger := ctrl.Log.WithValues("Namespace", statefulset.Namespace, "StatefulSet", statefulset.Name)
+
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	crNamespacedName := types.NamespacedName{Name: namer.SSToCr(statefulset.Name), Namespace: statefulset.Namespace}
+	if err := client.Get(context.TODO(), crNamespacedName, cr); err != nil {
+		reqLogger.V(1).Info("unable to retrieve the broker console configuration, the certificate is not verified", "error", err)
+		return nil, nil
+	}
+
+	secretName := cr.Spec.Console.TrustSecret
+	if secretName == "" {
+		secretName = cr.Spec.Console.SSLSecret
+	}
+	if secretName == "" {
+		secretName = cr.Name + "-console-secret"
+	}
+
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: statefulset.Namespace}, secret); err != nil {
+		if errors.IsNotFound(err) && cr.Spec.Console.TrustSecret == "" {
+			reqLogger.V(1).Info("console secret not found, the certificate is not verified", "Secret", secretName)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	caBundle, found := secret.Data[TrustSecretCAKey]
+	if !found {
+		if cr.Spec.Console.TrustSecret != "" {
+			return nil, fmt.Errorf("trust secret %s has no %s", secretName, TrustSecretCAKey)
+		}
+		reqLogger.V(1).Info("console secret has no CA bundle, the certificate is not verified", "Secret", secretName)
+		return nil, nil
+	}
+
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caBundle) {
+		return nil, fmt.Errorf("no PEM certificate found in %s of secret %s", TrustSecretCAKey, secretName)
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs:    rootCAs,
+		ServerName: fmt.Sprintf("%s.%s.%s.svc.%s", podName, statefulset.Spec.ServiceName, statefulset.Namespace, common.GetClusterDomain()),
+	}
+
+	if cr.Spec.Console.UseClientAuth {
+		clientCert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate in secret %s, %v", secretName, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return tlsConfig, nil
 }
 
 func getEnvVarValue(envVar *corev1.EnvVar, namespace *types.NamespacedName, statefulset *appsv1.StatefulSet, client rtclient.Client, labels map[string]string) string {
@@ -109,7 +109,12 @@ var _ = Describe("JolokiaClient", func() {
 						},
 						Spec: corev1.PodSpec{
 							Containers: []corev1.Container{
-								{},
+								{
+									Env: []corev1.EnvVar{
+										{Name: "AMQ_USER", Value: "admin"},
+										{Name: "AMQ_PASSWORD", Value: "secret"},
+									},
+								},
 							},
 						},
 						Status: corev1.PodStatus{
@@ -0,0 +1,140 @@
+package jolokia_client
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+)
+
+var _ = Describe("Jolokia credentials", func() {
+
+	statefulset := &appsv1.StatefulSet{
+		ObjectMeta: v1.ObjectMeta{Name: "ex-aao-ss", Namespace: "some-ns"},
+	}
+	podNamespacedName := types.NamespacedName{Name: "ex-aao-ss-0", Namespace: "some-ns"}
+	adminContainers := []corev1.Container{
+		{
+			Env: []corev1.EnvVar{
+				{Name: "AMQ_USER", Value: "admin-from-env"},
+				{Name: "AMQ_PASSWORD", Value: "password-from-env"},
+			},
+		},
+	}
+	adminPod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "ex-aao-ss-0", Namespace: "some-ns"},
+		Spec:       corev1.PodSpec{Containers: adminContainers},
+	}
+
+	newClient := func(credentials *brokerv1beta1.ManagementCredentialsType, objs ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(brokerv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		cr := &brokerv1beta1.ActiveMQArtemis{ObjectMeta: v1.ObjectMeta{Name: "ex-aao", Namespace: "some-ns"}}
+		cr.Spec.ManagementCredentials = credentials
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, cr)...).Build()
+	}
+
+	newSecret := func(name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "some-ns"}, Data: data}
+	}
+
+	It("should use the management credentials secret of the CR", func() {
+		c := newClient(&brokerv1beta1.ManagementCredentialsType{SecretName: "mgmt"}, newSecret("mgmt", map[string][]byte{
+			ManagementCredentialsUserKey:     []byte("operator"),
+			ManagementCredentialsPasswordKey: []byte("s3cret"),
+		}))
+		user, password, protocol, err := resolveJolokiaRequestParams(c, adminPod, podNamespacedName, statefulset, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal("operator"))
+		Expect(password).To(Equal("s3cret"))
+		Expect(protocol).To(Equal("http"))
+	})
+
+	It("should default to the admin credentials secret of the CR", func() {
+		c := newClient(&brokerv1beta1.ManagementCredentialsType{}, newSecret("ex-aao-credentials-secret", map[string][]byte{
+			"AMQ_USER":     []byte("generated"),
+			"AMQ_PASSWORD": []byte("generated-password"),
+		}))
+		user, password, _, err := resolveJolokiaRequestParams(c, adminPod, podNamespacedName, statefulset, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal("generated"))
+		Expect(password).To(Equal("generated-password"))
+	})
+
+	It("should fail rather than fall back when the management credentials are incomplete", func() {
+		c := newClient(&brokerv1beta1.ManagementCredentialsType{SecretName: "mgmt", PasswordKey: "pass"}, newSecret("mgmt", map[string][]byte{
+			ManagementCredentialsUserKey: []byte("operator"),
+		}))
+		_, _, _, err := resolveJolokiaRequestParams(c, adminPod, podNamespacedName, statefulset, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should look up the jolokia secret by the CR of the statefulset", func() {
+		c := newClient(nil, newSecret("ex-aao-jolokia-secret", map[string][]byte{
+			"jolokiaUser":     []byte("jolokia"),
+			"jolokiaPassword": []byte("jolokia-password"),
+		}))
+		user, password, _, err := resolveJolokiaRequestParams(c, adminPod, podNamespacedName, statefulset, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal("jolokia"))
+		Expect(password).To(Equal("jolokia-password"))
+	})
+
+	It("should use the previous admin credentials until the pod is restarted", func() {
+		rotatedAt := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+		previous := newSecret("ex-aao-credentials-previous", map[string][]byte{
+			"AMQ_USER":     []byte("admin-before"),
+			"AMQ_PASSWORD": []byte("password-before"),
+		})
+		previous.CreationTimestamp = v1.NewTime(rotatedAt)
+		c := newClient(nil, previous)
+
+		pod := adminPod.DeepCopy()
+		pod.CreationTimestamp = v1.NewTime(rotatedAt.Add(-time.Hour))
+		user, password, _, err := resolveJolokiaRequestParams(c, pod, podNamespacedName, statefulset, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal("admin-before"))
+		Expect(password).To(Equal("password-before"))
+
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: v1.NewTime(rotatedAt.Add(time.Minute))}},
+		}}
+		user, password, _, err = resolveJolokiaRequestParams(c, pod, podNamespacedName, statefulset, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal("admin-from-env"))
+		Expect(password).To(Equal("password-from-env"))
+	})
+
+	It("should not use the previous admin credentials for the jolokia secret", func() {
+		previous := newSecret("ex-aao-credentials-previous", map[string][]byte{
+			"AMQ_USER":     []byte("admin-before"),
+			"AMQ_PASSWORD": []byte("password-before"),
+		})
+		previous.CreationTimestamp = v1.NewTime(time.Now())
+		c := newClient(nil, previous, newSecret("ex-aao-jolokia-secret", map[string][]byte{
+			"jolokiaUser":     []byte("jolokia"),
+			"jolokiaPassword": []byte("jolokia-password"),
+		}))
+		user, _, _, err := resolveJolokiaRequestParams(c, adminPod, podNamespacedName, statefulset, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal("jolokia"))
+	})
+
+	It("should fail without any credentials", func() {
+		c := newClient(nil)
+		_, _, _, err := resolveJolokiaRequestParams(c, &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{}}}}, podNamespacedName, statefulset, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,113 @@
+package jolokia_client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	brokerv1beta1 "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+)
+
+var _ = Describe("Jolokia TLS", func() {
+
+	statefulset := &appsv1.StatefulSet{
+		ObjectMeta: v1.ObjectMeta{Name: "ex-aao-ss", Namespace: "some-ns"},
+		Spec:       appsv1.StatefulSetSpec{ServiceName: "ex-aao-hdls-svc"},
+	}
+	certPEM, keyPEM := newSelfSignedPEM()
+
+	newClient := func(console brokerv1beta1.ConsoleType, secrets ...*corev1.Secret) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(brokerv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		cr := &brokerv1beta1.ActiveMQArtemis{ObjectMeta: v1.ObjectMeta{Name: "ex-aao", Namespace: "some-ns"}}
+		cr.Spec.Console = console
+		objs := []client.Object{cr}
+		for _, secret := range secrets {
+			objs = append(objs, secret)
+		}
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	newSecret := func(name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "some-ns"}, Data: data}
+	}
+
+	It("should not verify without a CA bundle", func() {
+		c := newClient(brokerv1beta1.ConsoleType{SSLEnabled: true}, newSecret("ex-aao-console-secret", map[string][]byte{"broker.ks": []byte("jks")}))
+		tlsConfig, err := resolveJolokiaTLSConfig(c, statefulset, "ex-aao-ss-0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tlsConfig).To(BeNil())
+	})
+
+	It("should verify the pod dns name against the CA bundle of the ssl secret", func() {
+		c := newClient(brokerv1beta1.ConsoleType{SSLEnabled: true, SSLSecret: "console-tls"}, newSecret("console-tls", map[string][]byte{TrustSecretCAKey: certPEM}))
+		tlsConfig, err := resolveJolokiaTLSConfig(c, statefulset, "ex-aao-ss-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tlsConfig).NotTo(BeNil())
+		Expect(tlsConfig.InsecureSkipVerify).To(BeFalse())
+		Expect(tlsConfig.RootCAs).NotTo(BeNil())
+		Expect(tlsConfig.ServerName).To(Equal("ex-aao-ss-1.ex-aao-hdls-svc.some-ns.svc.cluster.local"))
+		Expect(tlsConfig.Certificates).To(BeEmpty())
+	})
+
+	It("should present a client certificate from the trust secret", func() {
+		console := brokerv1beta1.ConsoleType{SSLEnabled: true, UseClientAuth: true, TrustSecret: "console-trust"}
+		c := newClient(console, newSecret("console-trust", map[string][]byte{
+			TrustSecretCAKey:        certPEM,
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		}))
+		tlsConfig, err := resolveJolokiaTLSConfig(c, statefulset, "ex-aao-ss-0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tlsConfig.Certificates).To(HaveLen(1))
+	})
+
+	It("should fail when the trust secret cannot be used", func() {
+		console := brokerv1beta1.ConsoleType{SSLEnabled: true, TrustSecret: "console-trust"}
+		_, err := resolveJolokiaTLSConfig(newClient(console), statefulset, "ex-aao-ss-0")
+		Expect(err).To(HaveOccurred())
+
+		_, err = resolveJolokiaTLSConfig(newClient(console, newSecret("console-trust", map[string][]byte{})), statefulset, "ex-aao-ss-0")
+		Expect(err).To(HaveOccurred())
+
+		console.UseClientAuth = true
+		_, err = resolveJolokiaTLSConfig(newClient(console, newSecret("console-trust", map[string][]byte{TrustSecretCAKey: certPEM})), statefulset, "ex-aao-ss-0")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func newSelfSignedPEM() ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ex-aao"},
+		DNSNames:              []string{"*.ex-aao-hdls-svc.some-ns.svc.cluster.local"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
@@ -66,6 +66,14 @@ func CrToSS(crName string) string {
 	return crName + "-ss"
 }
 
+func CrToCredentialsSecret(crName string) string {
+	return crName + "-credentials-secret"
+}
+
+func CrToPreviousCredentialsSecret(crName string) string {
+	return crName + "-credentials-previous"
+}
+
 func SSToCr(ssName string) string {
 	return strings.TrimSuffix(ssName, "-ss")
 }